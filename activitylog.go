@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// activityLogRetentionDays is how far back the Activity Log keeps events
+const activityLogRetentionDays = 90
+
+// resourceGroupWriteOperation is the Activity Log operation recorded when a resource group is
+// created (and, later, updated)
+const resourceGroupWriteOperation = "Microsoft.Resources/subscriptions/resourceGroups/write"
+
+// ActivityLogEvent is the subset of an Activity Log event used to find a group's creation
+type ActivityLogEvent struct {
+	EventTimestamp time.Time `json:"eventTimestamp"`
+	Caller         string    `json:"caller"`
+	OperationName  struct {
+		Value string `json:"value"`
+	} `json:"operationName"`
+	Status struct {
+		Value string `json:"value"`
+	} `json:"status"`
+}
+
+// ActivityLogResponse is one page of Activity Log events
+type ActivityLogResponse struct {
+	Value    []ActivityLogEvent `json:"value"`
+	NextLink string             `json:"nextLink"`
+}
+
+// validateActivityLogDays checks the --activity-log-days window against the log's retention
+func validateActivityLogDays(days int) error {
+	if days < 1 || days > activityLogRetentionDays {
+		return fmt.Errorf("--activity-log-days must be between 1 and %d", activityLogRetentionDays)
+	}
+	return nil
+}
+
+// fetchResourceGroupCreation returns the earliest successful write event for a resource group
+// within the lookback window, or nil when there is none
+func (ac *AzureClient) fetchResourceGroupCreation(resourceGroupName string) (*ActivityLogEvent, error) {
+	now := inventoryNow().UTC()
+	from := now.AddDate(0, 0, -ac.Config.ActivityLogDays)
+	groupID := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName)
+
+	query := apiVersion("2015-04-01")
+	query.Set("$filter", fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceUri eq '%s'",
+		from.Format(time.RFC3339), now.Format(time.RFC3339), strings.ReplaceAll(groupID.String(), "'", "''")))
+	query.Set("$select", "eventTimestamp,caller,operationName,status")
+	url := NewSubscriptionResourceID(ac.Config.SubscriptionID).URL("providers/Microsoft.Insights/eventtypes/management/values", query)
+
+	var earliest *ActivityLogEvent
+	for url != "" {
+		resp, err := ac.makeAzureRequest(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch activity log: %w", err)
+		}
+
+		var page ActivityLogResponse
+		if err := decodeAzureResponse(resp, &page); err != nil {
+			return nil, err
+		}
+
+		for i := range page.Value {
+			event := page.Value[i]
+			if !strings.EqualFold(event.OperationName.Value, resourceGroupWriteOperation) || !strings.EqualFold(event.Status.Value, "Succeeded") {
+				continue
+			}
+			if earliest == nil || event.EventTimestamp.Before(earliest.EventTimestamp) {
+				earliest = &event
+			}
+		}
+		url = page.NextLink
+	}
+
+	return earliest, nil
+}
+
+// activityLogCreatedTime looks up the group's creation in the Activity Log. A write event only
+// counts as the creation if no resource in the group predates it: later writes are tag or
+// property updates of a group created before the lookback window.
+func (ac *AzureClient) activityLogCreatedTime(result *ResourceGroupResult) *time.Time {
+	event, err := ac.fetchResourceGroupCreation(result.ResourceGroup.Name)
+	if err != nil {
+		result.ActivityLogError = err
+		return nil
+	}
+	if event == nil {
+		return nil
+	}
+	if earliest := earliestCreatedTime(result.Resources); earliest != nil && earliest.Before(event.EventTimestamp) {
+		return nil
+	}
+
+	result.Creator = event.Caller
+	created := event.EventTimestamp.UTC()
+	return &created
+}
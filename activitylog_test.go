@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActivityLogCreatedTime(t *testing.T) {
+	withInventoryNow(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	var filters []string
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if !strings.Contains(req.URL.Path, "/providers/Microsoft.Insights/eventtypes/management/values") {
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+		if req.URL.Query().Get("page") == "2" {
+			return jsonResponse(http.StatusOK, `{"value": [
+				{"eventTimestamp": "2024-01-10T09:00:00Z", "caller": "alice@example.com", "operationName": {"value": "Microsoft.Resources/subscriptions/resourcegroups/write"}, "status": {"value": "Succeeded"}},
+				{"eventTimestamp": "2024-01-10T08:59:00Z", "caller": "alice@example.com", "operationName": {"value": "Microsoft.Resources/subscriptions/resourcegroups/write"}, "status": {"value": "Started"}}
+			]}`), nil
+		}
+		filters = append(filters, req.URL.Query().Get("$filter"))
+		return jsonResponse(http.StatusOK, `{"value": [
+			{"eventTimestamp": "2024-02-01T10:00:00Z", "caller": "bob@example.com", "operationName": {"value": "Microsoft.Resources/subscriptions/resourceGroups/write"}, "status": {"value": "Succeeded"}},
+			{"eventTimestamp": "2024-01-01T10:00:00Z", "caller": "carol@example.com", "operationName": {"value": "Microsoft.Authorization/locks/write"}, "status": {"value": "Succeeded"}}
+		], "nextLink": "https://management.azure.com/subscriptions/test-subscription/providers/Microsoft.Insights/eventtypes/management/values?page=2"}`), nil
+	})
+	client.Config.ActivityLog = true
+	client.Config.ActivityLogDays = activityLogRetentionDays
+
+	// An empty group takes the earliest successful write as its creation
+	empty := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "empty-rg"}}
+	client.resolveCreatedTime(&empty)
+	if empty.CreatedTime == nil || !empty.CreatedTime.Equal(time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected creation time %v", empty.CreatedTime)
+	}
+	if empty.CreatedTimeSource != createdTimeSourceActivityLog || empty.Creator != "alice@example.com" {
+		t.Errorf("unexpected source %q and creator %q", empty.CreatedTimeSource, empty.Creator)
+	}
+
+	expectedFilter := "eventTimestamp ge '2023-12-02T00:00:00Z' and eventTimestamp le '2024-03-01T00:00:00Z' and " +
+		"resourceUri eq '/subscriptions/test-subscription/resourceGroups/empty-rg'"
+	if len(filters) == 0 || filters[0] != expectedFilter {
+		t.Errorf("unexpected filter %v", filters)
+	}
+
+	// Resources older than every write event mean the group predates the window
+	resourceTime := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	older := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "old-rg"}, Resources: []Resource{{Name: "vm", CreatedTime: &resourceTime}}}
+	client.resolveCreatedTime(&older)
+	if !older.CreatedTime.Equal(resourceTime) || older.CreatedTimeSource != createdTimeSourceResources || older.Creator != "" {
+		t.Errorf("Expected the resource time to be kept, got %+v", older)
+	}
+}
+
+func TestActivityLogErrorsAreReported(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusForbidden, `{"error": {"code": "AuthorizationFailed"}}`), nil
+	})
+	client.Config.ActivityLog = true
+	client.Config.ActivityLogDays = 30
+
+	result := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "rg"}}
+	client.resolveCreatedTime(&result)
+	if result.ActivityLogError == nil || result.CreatedTime != nil {
+		t.Fatalf("Expected a lookup error and no creation time, got %+v", result)
+	}
+
+	inventory := client.buildInventory([]ResourceGroupResult{result}, false)
+	if !strings.Contains(inventory[0].Error, "activity log lookup failed") {
+		t.Errorf("Expected the lookup failure in the inventory error, got %q", inventory[0].Error)
+	}
+}
+
+func TestValidateActivityLogDays(t *testing.T) {
+	for _, days := range []int{1, 30, activityLogRetentionDays} {
+		if err := validateActivityLogDays(days); err != nil {
+			t.Errorf("Expected %d days to be valid, got %v", days, err)
+		}
+	}
+	for _, days := range []int{0, -1, 91} {
+		if err := validateActivityLogDays(days); err == nil {
+			t.Errorf("Expected error for %d days", days)
+		}
+	}
+}
+
+func TestCreationColumns(t *testing.T) {
+	columns, err := ParseColumns("created_time_source,creator")
+	if err != nil {
+		t.Fatalf("failed to parse columns: %v", err)
+	}
+	result := ResourceGroupResult{CreatedTimeSource: createdTimeSourceActivityLog, Creator: "app-id", ActivityLogError: errors.New("ignored")}
+	if got := columnValues(columns, result, columnStyleCSV); got[0] != "activity-log" || got[1] != "app-id" {
+		t.Errorf("unexpected values %v", got)
+	}
+}
@@ -0,0 +1,806 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Cleanup outcome statuses recorded in the outcome log
+const (
+	cleanupStatusDryRun   = "dry-run"
+	cleanupStatusDeleted  = "deleted"
+	cleanupStatusNotFound = "not-found"
+	cleanupStatusFailed   = "failed"
+	cleanupStatusSkipped  = "skipped"
+)
+
+// Defaults used while waiting for asynchronous Azure operations
+const (
+	defaultPollInterval     = 10 * time.Second
+	defaultOperationTimeout = 60 * time.Minute
+)
+
+// cleanupConfirmationWord must be typed to confirm a non-dry-run apply
+const cleanupConfirmationWord = "delete"
+
+// confirmationInput is where interactive confirmations are read from (replaced in tests)
+var confirmationInput io.Reader = os.Stdin
+
+// CleanupPlan is a reviewable list of resource groups scheduled for deletion
+type CleanupPlan struct {
+	SubscriptionID string             `json:"subscriptionId"`
+	GeneratedAt    time.Time          `json:"generatedAt"`
+	Source         string             `json:"source"`
+	Groups         []CleanupPlanEntry `json:"groups"`
+	Skipped        []CleanupPlanEntry `json:"skipped,omitempty"`
+}
+
+// CleanupPlanEntry describes a single resource group in a cleanup plan
+type CleanupPlanEntry struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	Location string `json:"location"`
+	Reason   string `json:"reason"`
+}
+
+// CleanupPlanOptions controls how resource groups are selected for a cleanup plan
+type CleanupPlanOptions struct {
+	DecisionsCSV    string
+	NamePattern     string
+	IncludeDefaults bool
+}
+
+// CleanupApplyOptions controls how a cleanup plan is executed
+type CleanupApplyOptions struct {
+	Execute    bool
+	AssumeYes  bool
+	OutcomeLog string
+}
+
+// CleanupOutcome records what happened to a single resource group during apply
+type CleanupOutcome struct {
+	ResourceGroup   string    `json:"resourceGroup"`
+	Status          string    `json:"status"`
+	Reason          string    `json:"reason,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	StartedAt       time.Time `json:"startedAt"`
+	CompletedAt     time.Time `json:"completedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+// asyncOperationStatus is the body returned by an Azure-AsyncOperation status URL
+type asyncOperationStatus struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Plan cleanup command
+var planCleanupCmd = &cobra.Command{
+	Use:   "plan-cleanup",
+	Short: "Generate a reviewable plan of resource groups to delete",
+	Long: `Selects resource groups from a CSV of review decisions (rows whose Decision or
+Action column is "delete"), by matching names against a regular expression and/or with the
+inventory filters (--older-than, --location, --tag, --min-cost, ...), and writes them to a
+plan file that can be reviewed before running apply. The filters select the same groups an
+inventory run with the same filters lists.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		planFile, _ := cmd.Flags().GetString("plan-file")
+		decisionsCSV, _ := cmd.Flags().GetString("from-csv")
+		match, _ := cmd.Flags().GetString("match")
+		includeDefaults, _ := cmd.Flags().GetBool("include-defaults")
+
+		// Filters given to this command take precedence over the config file's filters section
+		if err := bindFilterFlags(viper.GetViper(), cmd.Flags()); err != nil {
+			log.Fatalf("Failed to bind filter flags: %v", err)
+		}
+		filter, err := NewResourceGroupFilter(filterOptionsFromConfig(viper.GetViper()), time.Now())
+		if err != nil {
+			log.Fatalf("Invalid filter: %v", err)
+		}
+		azureClient.Filter = filter
+		azureClient.Config.PolicyCompliance = filter.NeedsPolicyCompliance()
+		azureClient.Config.Cost = filter.NeedsCost()
+		azureClient.Config.CostPeriod, _ = cmd.Flags().GetString("cost-period")
+
+		plan, err := azureClient.BuildCleanupPlan(CleanupPlanOptions{
+			DecisionsCSV:    decisionsCSV,
+			NamePattern:     match,
+			IncludeDefaults: includeDefaults,
+		})
+		if err != nil {
+			log.Fatalf("Error building cleanup plan: %v", err)
+		}
+
+		if err := writeCleanupPlan(planFile, plan); err != nil {
+			log.Fatalf("Error writing cleanup plan: %v", err)
+		}
+
+		printCleanupPlan(plan)
+		fmt.Printf("Cleanup plan written to: %s\n", planFile)
+	},
+}
+
+// Apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Execute a cleanup plan (dry-run unless --execute is given)",
+	Long: `Reads a plan produced by plan-cleanup and deletes the listed resource groups.
+By default nothing is deleted; pass --execute and confirm interactively (or with --yes)
+to issue the DELETE calls. Each deletion is polled until Azure reports completion and
+the outcome for every group is appended to the outcome log as soon as it is known.
+Dry runs do not touch the outcome log.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		planFile, _ := cmd.Flags().GetString("plan-file")
+		execute, _ := cmd.Flags().GetBool("execute")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		outcomeLog, _ := cmd.Flags().GetString("outcome-log")
+		pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		plan, err := readCleanupPlan(planFile)
+		if err != nil {
+			log.Fatalf("Error reading cleanup plan: %v", err)
+		}
+
+		azureClient.Config.PollInterval = pollInterval
+		azureClient.Config.OperationTimeout = timeout
+
+		if _, err := azureClient.ApplyCleanupPlan(plan, CleanupApplyOptions{
+			Execute:    execute,
+			AssumeYes:  assumeYes,
+			OutcomeLog: outcomeLog,
+		}); err != nil {
+			log.Fatalf("Error applying cleanup plan: %v", err)
+		}
+	},
+}
+
+func init() {
+	planCleanupCmd.Flags().String("plan-file", "cleanup-plan.json", "Path to write the cleanup plan to")
+	planCleanupCmd.Flags().String("from-csv", "", "CSV of review decisions; rows with Decision/Action 'delete' are planned")
+	planCleanupCmd.Flags().String("match", "", "Regular expression selecting resource group names to plan for deletion")
+	planCleanupCmd.Flags().Bool("include-defaults", false, "Allow default resource groups (e.g. NetworkWatcherRG) in the plan")
+	planCleanupCmd.Flags().String("cost-period", defaultCostPeriod, "Cost period for --min-cost/--max-cost: month-to-date, last-month, week-to-date or a window ending now such as 30d")
+	addFilterFlags(planCleanupCmd.Flags())
+
+	applyCmd.Flags().String("plan-file", "cleanup-plan.json", "Path of the cleanup plan to execute")
+	applyCmd.Flags().Bool("execute", false, "Actually delete resource groups (default is a dry-run)")
+	applyCmd.Flags().Bool("yes", false, "Skip the interactive confirmation prompt when --execute is given")
+	applyCmd.Flags().String("outcome-log", "cleanup-outcomes.jsonl", "Path to append the per-group outcome log to (JSON lines; not written on dry runs)")
+	applyCmd.Flags().Duration("poll-interval", defaultPollInterval, "Interval between polls of asynchronous delete operations when Azure sends no Retry-After")
+	applyCmd.Flags().Duration("timeout", defaultOperationTimeout, "Maximum time to wait for each resource group deletion")
+
+	rootCmd.AddCommand(planCleanupCmd, applyCmd)
+}
+
+// BuildCleanupPlan selects resource groups for deletion from review decisions, a name pattern
+// and/or the client's inventory filter
+func (ac *AzureClient) BuildCleanupPlan(opts CleanupPlanOptions) (*CleanupPlan, error) {
+	if opts.DecisionsCSV == "" && opts.NamePattern == "" && ac.Filter == nil {
+		return nil, fmt.Errorf("either --from-csv, --match or an inventory filter must be provided to select resource groups")
+	}
+
+	var pattern *regexp.Regexp
+	if opts.NamePattern != "" {
+		compiled, err := regexp.Compile(opts.NamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match pattern: %w", err)
+		}
+		pattern = compiled
+	}
+
+	var decisions map[string]string
+	if opts.DecisionsCSV != "" {
+		var err error
+		decisions, err = readCleanupDecisions(opts.DecisionsCSV)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resourceGroups, err := ac.listResourceGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, 0, 3)
+	if ac.Filter != nil {
+		sources = append(sources, "filters")
+	}
+	if opts.DecisionsCSV != "" {
+		sources = append(sources, "csv:"+opts.DecisionsCSV)
+	}
+	if opts.NamePattern != "" {
+		sources = append(sources, "match:"+opts.NamePattern)
+	}
+
+	plan := &CleanupPlan{
+		SubscriptionID: ac.Config.SubscriptionID,
+		GeneratedAt:    time.Now().UTC(),
+		Source:         strings.Join(sources, " "),
+		Groups:         make([]CleanupPlanEntry, 0),
+	}
+
+	resourceGroups, err = ac.applyPlanFilters(resourceGroups, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(decisions))
+	candidates := make([]ResourceGroup, 0)
+	candidateEntries := make([]CleanupPlanEntry, 0)
+	for _, rg := range resourceGroups {
+		key := strings.ToLower(rg.Name)
+		reason := ""
+
+		if decisions != nil {
+			decisionReason, ok := decisions[key]
+			if !ok {
+				continue
+			}
+			found[key] = true
+			reason = decisionReason
+		}
+
+		if pattern != nil {
+			if !pattern.MatchString(rg.Name) {
+				continue
+			}
+			if reason == "" {
+				reason = fmt.Sprintf("name matches %q", opts.NamePattern)
+			}
+		}
+		if reason == "" && ac.Filter != nil {
+			reason = "matches the inventory filters"
+		}
+
+		entry := CleanupPlanEntry{
+			Name:     rg.Name,
+			ID:       rg.ID,
+			Location: rg.Location,
+			Reason:   reason,
+		}
+
+		if !opts.IncludeDefaults && checkIfDefaultResourceGroup(rg.Name).IsDefault {
+			entry.Reason = "default resource group (use --include-defaults to plan it)"
+			plan.Skipped = append(plan.Skipped, entry)
+			continue
+		}
+
+		candidates = append(candidates, rg)
+		candidateEntries = append(candidateEntries, entry)
+	}
+
+	// Protected and locked groups are never planned, whatever the selection said
+	guardReasons := ac.cleanupGuardReasons(candidates)
+	for i, entry := range candidateEntries {
+		if guardReasons[i] != "" {
+			entry.Reason = guardReasons[i]
+			plan.Skipped = append(plan.Skipped, entry)
+			continue
+		}
+		plan.Groups = append(plan.Groups, entry)
+	}
+
+	for name := range decisions {
+		if !found[name] {
+			log.Printf("Warning: resource group %q from %s was not found in the subscription", name, opts.DecisionsCSV)
+		}
+	}
+
+	return plan, nil
+}
+
+// applyPlanFilters narrows the groups with the inventory filter in the order an inventory run
+// applies it, so a plan holds the groups the same filters list. Unlike the inventory, a group
+// whose creation time or compliance could not be determined is skipped rather than kept: the
+// plan must not delete a group it cannot show to match.
+func (ac *AzureClient) applyPlanFilters(resourceGroups []ResourceGroup, plan *CleanupPlan) ([]ResourceGroup, error) {
+	if ac.Filter == nil {
+		return resourceGroups, nil
+	}
+
+	resourceGroups = ac.Filter.FilterGroups(resourceGroups)
+	if ac.Config.Cost {
+		if err := ac.loadCosts(); err != nil {
+			return nil, err
+		}
+		resourceGroups = ac.applyCosts(resourceGroups)
+	}
+	if !ac.Filter.NeedsCreatedTime() && !ac.Filter.NeedsPolicyCompliance() {
+		return resourceGroups, nil
+	}
+
+	results := ac.collectResourceGroupResults(resourceGroups, false, "Applying filters...")
+	matched := make([]ResourceGroup, 0, len(results))
+	for _, result := range results {
+		entry := CleanupPlanEntry{
+			Name:     result.ResourceGroup.Name,
+			ID:       result.ResourceGroup.ID,
+			Location: result.ResourceGroup.Location,
+		}
+		switch {
+		case ac.Filter.NeedsCreatedTime() && result.CreatedTime == nil:
+			entry.Reason = fmt.Sprintf("creation time unknown: %v", result.Error)
+		case ac.Filter.NeedsPolicyCompliance() && result.Policy == nil:
+			entry.Reason = fmt.Sprintf("policy compliance unknown: %v", result.PolicyError)
+		default:
+			matched = append(matched, result.ResourceGroup)
+			continue
+		}
+		plan.Skipped = append(plan.Skipped, entry)
+	}
+	return matched, nil
+}
+
+// cleanupGuardReasons evaluates cleanupGuardReason for each resource group with bounded concurrency
+func (ac *AzureClient) cleanupGuardReasons(resourceGroups []ResourceGroup) []string {
+	var wg sync.WaitGroup
+	reasons := make([]string, len(resourceGroups))
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent lock lookups
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, rg := range resourceGroups {
+		wg.Add(1)
+		go func(i int, rg ResourceGroup) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			reasons[i] = ac.cleanupGuardReason(rg)
+		}(i, rg)
+	}
+
+	wg.Wait()
+	return reasons
+}
+
+// readCleanupDecisions reads a review CSV and returns the lower-cased names of groups marked for deletion
+// mapped to the reviewer's reason (if a Reason or Notes column is present)
+func readCleanupDecisions(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decisions CSV: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("Warning: failed to close decisions CSV: %v", err)
+		}
+	}()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decisions CSV header: %w", err)
+	}
+
+	nameCol, decisionCol, reasonCol := -1, -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "resourcegroupname", "name":
+			nameCol = i
+		case "decision", "action":
+			decisionCol = i
+		case "reason", "notes":
+			reasonCol = i
+		}
+	}
+	if nameCol == -1 || decisionCol == -1 {
+		return nil, fmt.Errorf("decisions CSV must have ResourceGroupName and Decision (or Action) columns")
+	}
+
+	decisions := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decisions CSV row: %w", err)
+		}
+		if nameCol >= len(record) || decisionCol >= len(record) {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(record[decisionCol]), cleanupConfirmationWord) {
+			continue
+		}
+
+		reason := "marked for deletion in review CSV"
+		if reasonCol != -1 && reasonCol < len(record) && strings.TrimSpace(record[reasonCol]) != "" {
+			reason = strings.TrimSpace(record[reasonCol])
+		}
+		decisions[strings.ToLower(strings.TrimSpace(record[nameCol]))] = reason
+	}
+
+	return decisions, nil
+}
+
+// writeCleanupPlan writes the plan as indented JSON so it can be reviewed and committed
+func writeCleanupPlan(path string, plan *CleanupPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cleanup plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cleanup plan: %w", err)
+	}
+	return nil
+}
+
+// readCleanupPlan loads a plan written by writeCleanupPlan
+func readCleanupPlan(path string) (*CleanupPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cleanup plan: %w", err)
+	}
+
+	var plan CleanupPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse cleanup plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// printCleanupPlan prints a human-readable summary of a plan
+func printCleanupPlan(plan *CleanupPlan) {
+	fmt.Printf("Cleanup plan for subscription %s (%d resource groups):\n\n", plan.SubscriptionID, len(plan.Groups))
+	for _, entry := range plan.Groups {
+		fmt.Printf("  - %s (%s): %s\n", entry.Name, entry.Location, entry.Reason)
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Printf("\nSkipped (%d):\n", len(plan.Skipped))
+		for _, entry := range plan.Skipped {
+			fmt.Printf("  - %s: %s\n", entry.Name, entry.Reason)
+		}
+	}
+	fmt.Println()
+}
+
+// ApplyCleanupPlan deletes the resource groups in a plan, or reports what would be deleted in dry-run mode
+func (ac *AzureClient) ApplyCleanupPlan(plan *CleanupPlan, opts CleanupApplyOptions) ([]CleanupOutcome, error) {
+	if plan.SubscriptionID != ac.Config.SubscriptionID {
+		return nil, fmt.Errorf("plan targets subscription %s but the configured subscription is %s",
+			plan.SubscriptionID, ac.Config.SubscriptionID)
+	}
+
+	outcomes := make([]CleanupOutcome, len(plan.Groups))
+
+	if !opts.Execute {
+		fmt.Printf("Dry-run: %d resource groups would be deleted (re-run with --execute to delete):\n", len(plan.Groups))
+		now := time.Now().UTC()
+		for i, entry := range plan.Groups {
+			fmt.Printf("  [dry-run] would delete %s\n", entry.Name)
+			outcomes[i] = CleanupOutcome{
+				ResourceGroup: entry.Name,
+				Status:        cleanupStatusDryRun,
+				StartedAt:     now,
+				CompletedAt:   now,
+			}
+		}
+		// Dry runs leave the outcome log alone: it is the record of what earlier runs deleted
+		return outcomes, nil
+	}
+
+	if !opts.AssumeYes {
+		if err := confirmCleanup(len(plan.Groups), plan.SubscriptionID); err != nil {
+			return nil, err
+		}
+	}
+
+	outcomeLog, err := openCleanupOutcomeLog(opts.OutcomeLog)
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent deletions
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, entry := range plan.Groups {
+		wg.Add(1)
+		go func(i int, entry CleanupPlanEntry) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			outcomes[i] = ac.deleteResourceGroup(entry.Name)
+			outcomeLog.Write(outcomes[i])
+		}(i, entry)
+	}
+
+	wg.Wait()
+
+	failed := 0
+	for _, outcome := range outcomes {
+		switch outcome.Status {
+		case cleanupStatusFailed:
+			failed++
+			fmt.Printf("  %s: %s (%s)\n", outcome.ResourceGroup, outcome.Status, outcome.Error)
+		case cleanupStatusSkipped:
+			fmt.Printf("  %s: %s (%s)\n", outcome.ResourceGroup, outcome.Status, outcome.Reason)
+		default:
+			fmt.Printf("  %s: %s\n", outcome.ResourceGroup, outcome.Status)
+		}
+	}
+
+	if err := outcomeLog.Close(); err != nil {
+		return outcomes, err
+	}
+
+	if failed > 0 {
+		return outcomes, fmt.Errorf("%d of %d resource group deletions failed", failed, len(outcomes))
+	}
+	return outcomes, nil
+}
+
+// confirmCleanup asks the user to type the confirmation word before anything is deleted
+func confirmCleanup(count int, subscriptionID string) error {
+	fmt.Printf("This will permanently delete %d resource groups in subscription %s.\n", count, subscriptionID)
+	fmt.Printf("Type '%s' to continue: ", cleanupConfirmationWord)
+
+	answer, err := bufio.NewReader(confirmationInput).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(answer) != cleanupConfirmationWord {
+		return fmt.Errorf("cleanup aborted: confirmation not given")
+	}
+	return nil
+}
+
+// deleteResourceGroup deletes a single resource group, waits for completion and records the outcome.
+// The guard rails are checked again against the group's current state because protection tags
+// or locks may have been added since the plan was written.
+func (ac *AzureClient) deleteResourceGroup(name string) CleanupOutcome {
+	outcome := CleanupOutcome{
+		ResourceGroup: name,
+		StartedAt:     time.Now().UTC(),
+	}
+
+	rg, err := ac.fetchResourceGroup(name)
+	if err == nil {
+		outcome.Reason = ac.cleanupGuardReason(rg)
+		if outcome.Reason == "" {
+			err = ac.deleteResourceGroupAndWait(name)
+		}
+	}
+
+	var apiErr *AzureAPIError
+	switch {
+	case err == nil && outcome.Reason != "":
+		outcome.Status = cleanupStatusSkipped
+	case err == nil:
+		outcome.Status = cleanupStatusDeleted
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		outcome.Status = cleanupStatusNotFound
+	default:
+		outcome.Status = cleanupStatusFailed
+		outcome.Error = err.Error()
+	}
+
+	outcome.CompletedAt = time.Now().UTC()
+	outcome.DurationSeconds = outcome.CompletedAt.Sub(outcome.StartedAt).Seconds()
+	return outcome
+}
+
+// fetchResourceGroup reads the current state of a resource group, including its tags
+func (ac *AzureClient) fetchResourceGroup(name string) (ResourceGroup, error) {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, name).URL("", apiVersion("2021-04-01"))
+
+	var rg ResourceGroup
+	resp, err := ac.doAzureRequest("GET", url, nil)
+	if err != nil {
+		return rg, err
+	}
+	err = decodeAzureResponse(resp, &rg)
+	return rg, err
+}
+
+// deleteResourceGroupAndWait issues the DELETE call and polls the asynchronous operation until it finishes
+func (ac *AzureClient) deleteResourceGroupAndWait(name string) error {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, name).URL("", apiVersion("2021-04-01"))
+
+	resp, err := ac.doAzureRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		closeResponseBody(resp)
+		return nil
+	}
+
+	_, err = ac.waitForAsyncOperation(resp)
+	return err
+}
+
+// waitForAsyncOperation polls the Azure-AsyncOperation (preferred) or Location header of an
+// accepted response until the operation completes, returning the final response body
+func (ac *AzureClient) waitForAsyncOperation(resp *http.Response) ([]byte, error) {
+	asyncURL := resp.Header.Get("Azure-AsyncOperation")
+	locationURL := resp.Header.Get("Location")
+	delay := ac.retryAfter(resp)
+	closeResponseBody(resp)
+
+	if asyncURL == "" && locationURL == "" {
+		return nil, fmt.Errorf("accepted response did not include an Azure-AsyncOperation or Location header")
+	}
+
+	timeout := ac.Config.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultOperationTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %v waiting for operation to complete", timeout)
+		}
+		time.Sleep(delay)
+
+		var done bool
+		var body []byte
+		var err error
+		if asyncURL != "" {
+			done, body, delay, err = ac.pollAsyncOperationStatus(asyncURL)
+		} else {
+			done, body, delay, err = ac.pollLocation(locationURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if done && asyncURL != "" && locationURL != "" {
+			// Operations that produce a result (e.g. exportTemplate) expose it on the Location URL,
+			// which may still answer 202 for a while after the status reports success
+			asyncURL = ""
+			continue
+		}
+		if done {
+			return body, nil
+		}
+	}
+}
+
+// pollAsyncOperationStatus checks an Azure-AsyncOperation status URL once
+func (ac *AzureClient) pollAsyncOperationStatus(url string) (bool, []byte, time.Duration, error) {
+	resp, err := ac.makeAzureRequest(url)
+	if err != nil {
+		return false, nil, 0, fmt.Errorf("failed to poll operation status: %w", err)
+	}
+	delay := ac.retryAfter(resp)
+
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return false, nil, 0, err
+	}
+
+	var status asyncOperationStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return false, nil, 0, fmt.Errorf("failed to parse operation status: %w", err)
+	}
+
+	switch strings.ToLower(status.Status) {
+	case "succeeded":
+		return true, body, delay, nil
+	case "failed", "canceled", "cancelled":
+		if status.Error != nil {
+			return false, nil, 0, fmt.Errorf("operation %s: %s: %s", status.Status, status.Error.Code, status.Error.Message)
+		}
+		return false, nil, 0, fmt.Errorf("operation %s", status.Status)
+	default:
+		return false, nil, delay, nil
+	}
+}
+
+// pollLocation checks a Location URL once; 202 means the operation is still running
+func (ac *AzureClient) pollLocation(url string) (bool, []byte, time.Duration, error) {
+	resp, err := ac.doAzureRequest("GET", url, nil)
+	if err != nil {
+		return false, nil, 0, fmt.Errorf("failed to poll operation location: %w", err)
+	}
+	delay := ac.retryAfter(resp)
+
+	if resp.StatusCode == http.StatusAccepted {
+		closeResponseBody(resp)
+		return false, nil, delay, nil
+	}
+
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return false, nil, 0, err
+	}
+	return true, body, delay, nil
+}
+
+// retryAfter returns the server-requested polling delay, falling back to the configured interval
+// when the header is missing or 0 so polling never spins
+func (ac *AzureClient) retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if ac.Config.PollInterval > 0 {
+		return ac.Config.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// cleanupOutcomeLog appends one JSON object per line to the outcome log as deletions finish, so
+// an interrupted run still records what was deleted. Earlier runs' lines are kept.
+type cleanupOutcomeLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error
+}
+
+// openCleanupOutcomeLog opens the outcome log for appending; an empty path disables it
+func openCleanupOutcomeLog(path string) (*cleanupOutcomeLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outcome log: %w", err)
+	}
+	return &cleanupOutcomeLog{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Write appends an outcome; the first failure is kept and returned by Close
+func (l *cleanupOutcomeLog) Write(outcome CleanupOutcome) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.encoder.Encode(outcome); err != nil && l.err == nil {
+		l.err = fmt.Errorf("failed to write outcome log: %w", err)
+	}
+}
+
+// Close closes the log and returns the first write or close failure
+func (l *cleanupOutcomeLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.file.Close(); err != nil && l.err == nil {
+		l.err = fmt.Errorf("failed to close outcome log: %w", err)
+	}
+	return l.err
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const cleanupTestResourceGroups = `{
+	"value": [
+		{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-alice", "name": "sandbox-alice", "location": "eastus", "properties": {"provisioningState": "Succeeded"}},
+		{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-bob", "name": "sandbox-bob", "location": "westus", "properties": {"provisioningState": "Succeeded"}},
+		{"id": "/subscriptions/test-subscription/resourceGroups/prod-app", "name": "prod-app", "location": "eastus", "properties": {"provisioningState": "Succeeded"}},
+		{"id": "/subscriptions/test-subscription/resourceGroups/NetworkWatcherRG", "name": "NetworkWatcherRG", "location": "eastus", "properties": {"provisioningState": "Succeeded"}}
+	]
+}`
+
+func newCleanupTestClient(doFunc func(req *http.Request) (*http.Response, error)) *AzureClient {
+	return &AzureClient{
+		Config: Config{
+			SubscriptionID: "test-subscription",
+			AccessToken:    "test-token",
+			MaxConcurrency: 2,
+			Porcelain:      true,
+			PollInterval:   time.Millisecond,
+		},
+		HTTPClient: &MockHTTPClient{DoFunc: doFunc},
+	}
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestBuildCleanupPlanFromCSV(t *testing.T) {
+	dir := t.TempDir()
+	decisions := filepath.Join(dir, "decisions.csv")
+	content := "ResourceGroupName,Location,Decision,Reason\n" +
+		"sandbox-alice,eastus,delete,left the company\n" +
+		"sandbox-bob,westus,keep,\n" +
+		"networkwatcherrg,eastus,Delete,\n" +
+		"missing-rg,eastus,delete,\n"
+	if err := os.WriteFile(decisions, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write decisions CSV: %v", err)
+	}
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "Microsoft.Authorization/locks") {
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+
+	plan, err := client.BuildCleanupPlan(CleanupPlanOptions{DecisionsCSV: decisions})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(plan.Groups) != 1 || plan.Groups[0].Name != "sandbox-alice" {
+		t.Fatalf("Expected only sandbox-alice in plan, got %+v", plan.Groups)
+	}
+	if plan.Groups[0].Reason != "left the company" {
+		t.Errorf("Expected reviewer reason to be carried into plan, got %q", plan.Groups[0].Reason)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Name != "NetworkWatcherRG" {
+		t.Errorf("Expected default resource group to be skipped, got %+v", plan.Skipped)
+	}
+	if plan.SubscriptionID != "test-subscription" {
+		t.Errorf("Expected plan subscription to be recorded, got %q", plan.SubscriptionID)
+	}
+}
+
+func TestBuildCleanupPlanWithMatch(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "Microsoft.Authorization/locks") {
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+
+	plan, err := client.BuildCleanupPlan(CleanupPlanOptions{NamePattern: "^sandbox-"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(plan.Groups) != 2 {
+		t.Fatalf("Expected 2 groups in plan, got %d", len(plan.Groups))
+	}
+
+	if _, err := client.BuildCleanupPlan(CleanupPlanOptions{}); err == nil {
+		t.Error("Expected error when no selection criteria are given")
+	}
+	if _, err := client.BuildCleanupPlan(CleanupPlanOptions{NamePattern: "("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestBuildCleanupPlanSkipsProtectedAndLockedGroups(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "Microsoft.Authorization/locks") {
+			if req.URL.Query().Get("$filter") != "atScope()" {
+				t.Errorf("Expected atScope() lock filter, got %q", req.URL.RawQuery)
+			}
+			if strings.Contains(req.URL.Path, "/sandbox-bob/") {
+				return jsonResponse(http.StatusOK, `{"value": [{"id": "lock-id", "name": "keep-me", "properties": {"level": "CanNotDelete"}}]}`), nil
+			}
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+
+	rules, err := NewProtectionRules(ProtectionConfig{Names: []string{"Prod-App"}})
+	if err != nil {
+		t.Fatalf("failed to build protection rules: %v", err)
+	}
+	client.Protection = rules
+
+	plan, err := client.BuildCleanupPlan(CleanupPlanOptions{NamePattern: "^(sandbox|prod)-"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(plan.Groups) != 1 || plan.Groups[0].Name != "sandbox-alice" {
+		t.Fatalf("Expected only sandbox-alice to be planned, got %+v", plan.Groups)
+	}
+
+	skipped := make(map[string]string)
+	for _, entry := range plan.Skipped {
+		skipped[entry.Name] = entry.Reason
+	}
+	if !strings.HasPrefix(skipped["sandbox-bob"], "locked: CanNotDelete (keep-me)") {
+		t.Errorf("Expected sandbox-bob to be skipped as locked, got %q", skipped["sandbox-bob"])
+	}
+	if !strings.HasPrefix(skipped["prod-app"], "protected:") {
+		t.Errorf("Expected prod-app to be skipped as protected, got %q", skipped["prod-app"])
+	}
+}
+
+func TestCleanupPlanRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := &CleanupPlan{
+		SubscriptionID: "test-subscription",
+		GeneratedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Source:         "match:^sandbox-",
+		Groups:         []CleanupPlanEntry{{Name: "sandbox-alice", Location: "eastus", Reason: "stale"}},
+	}
+
+	if err := writeCleanupPlan(path, plan); err != nil {
+		t.Fatalf("failed to write plan: %v", err)
+	}
+	loaded, err := readCleanupPlan(path)
+	if err != nil {
+		t.Fatalf("failed to read plan: %v", err)
+	}
+	if loaded.SubscriptionID != plan.SubscriptionID || len(loaded.Groups) != 1 || loaded.Groups[0].Name != "sandbox-alice" {
+		t.Errorf("plan did not round trip: %+v", loaded)
+	}
+}
+
+func TestApplyCleanupPlanDryRun(t *testing.T) {
+	var methods []string
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		methods = append(methods, req.Method)
+		return jsonResponse(http.StatusOK, `{}`), nil
+	})
+
+	outcomeLog := filepath.Join(t.TempDir(), "outcomes.jsonl")
+	plan := &CleanupPlan{
+		SubscriptionID: "test-subscription",
+		Groups:         []CleanupPlanEntry{{Name: "sandbox-alice"}, {Name: "sandbox-bob"}},
+	}
+
+	outcomes, err := client.ApplyCleanupPlan(plan, CleanupApplyOptions{OutcomeLog: outcomeLog})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(methods) != 0 {
+		t.Errorf("Expected no API calls in dry-run, got %v", methods)
+	}
+	for _, outcome := range outcomes {
+		if outcome.Status != cleanupStatusDryRun {
+			t.Errorf("Expected dry-run status, got %q", outcome.Status)
+		}
+	}
+
+	// The outcome log records real deletions only
+	if _, err := os.Stat(outcomeLog); !os.IsNotExist(err) {
+		t.Errorf("Expected no outcome log from a dry run, got %v", err)
+	}
+}
+
+func TestApplyCleanupPlanRequiresConfirmation(t *testing.T) {
+	oldInput := confirmationInput
+	confirmationInput = strings.NewReader("yes\n")
+	defer func() { confirmationInput = oldInput }()
+
+	called := false
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return jsonResponse(http.StatusOK, `{}`), nil
+	})
+	plan := &CleanupPlan{SubscriptionID: "test-subscription", Groups: []CleanupPlanEntry{{Name: "sandbox-alice"}}}
+
+	if _, err := client.ApplyCleanupPlan(plan, CleanupApplyOptions{Execute: true}); err == nil {
+		t.Fatal("Expected apply to abort without the confirmation word")
+	}
+	if called {
+		t.Error("Expected no API calls when confirmation is refused")
+	}
+}
+
+func TestApplyCleanupPlanSubscriptionMismatch(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		t.Error("unexpected API call")
+		return jsonResponse(http.StatusOK, `{}`), nil
+	})
+	plan := &CleanupPlan{SubscriptionID: "other-subscription", Groups: []CleanupPlanEntry{{Name: "sandbox-alice"}}}
+
+	if _, err := client.ApplyCleanupPlan(plan, CleanupApplyOptions{Execute: true, AssumeYes: true}); err == nil {
+		t.Fatal("Expected error for plan targeting another subscription")
+	}
+}
+
+func TestApplyCleanupPlanPollsAsyncOperations(t *testing.T) {
+	var mu sync.Mutex
+	polls := make(map[string]int)
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := req.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/sandbox-dave/providers/Microsoft.Authorization/locks"):
+			return jsonResponse(http.StatusOK, `{"value": [{"name": "keep", "properties": {"level": "CanNotDelete"}}]}`), nil
+		case strings.HasSuffix(path, "/providers/Microsoft.Authorization/locks"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		case req.Method == "GET" && strings.HasSuffix(path, "/sandbox-gone"):
+			return jsonResponse(http.StatusNotFound, `{"error":{"code":"ResourceGroupNotFound"}}`), nil
+		case req.Method == "GET" && strings.HasSuffix(path, "/sandbox-erin"):
+			return jsonResponse(http.StatusOK, `{"name": "sandbox-erin", "tags": {"do-not-delete": "true"}}`), nil
+		case req.Method == "GET" && strings.Contains(path, "/resourceGroups/sandbox-"):
+			return jsonResponse(http.StatusOK, `{"name": "`+path[strings.LastIndex(path, "/")+1:]+`"}`), nil
+		case req.Method == "DELETE" && strings.HasSuffix(path, "/sandbox-alice"):
+			resp := jsonResponse(http.StatusAccepted, "")
+			resp.Header.Set("Azure-AsyncOperation", "https://management.azure.com/operations/alice")
+			return resp, nil
+		case req.Method == "DELETE" && strings.HasSuffix(path, "/sandbox-bob"):
+			resp := jsonResponse(http.StatusAccepted, "")
+			resp.Header.Set("Location", "https://management.azure.com/operationresults/bob")
+			return resp, nil
+		case req.Method == "DELETE" && strings.HasSuffix(path, "/sandbox-carol"):
+			resp := jsonResponse(http.StatusAccepted, "")
+			resp.Header.Set("Azure-AsyncOperation", "https://management.azure.com/operations/carol")
+			return resp, nil
+		case strings.HasSuffix(path, "/operations/alice"):
+			polls["alice"]++
+			if polls["alice"] < 3 {
+				return jsonResponse(http.StatusOK, `{"status":"InProgress"}`), nil
+			}
+			return jsonResponse(http.StatusOK, `{"status":"Succeeded"}`), nil
+		case strings.HasSuffix(path, "/operationresults/bob"):
+			polls["bob"]++
+			if polls["bob"] < 2 {
+				return jsonResponse(http.StatusAccepted, ""), nil
+			}
+			return jsonResponse(http.StatusOK, ""), nil
+		case strings.HasSuffix(path, "/operations/carol"):
+			return jsonResponse(http.StatusOK, `{"status":"Failed","error":{"code":"Conflict","message":"locked"}}`), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(http.StatusBadRequest, ""), nil
+	})
+
+	// Earlier runs' outcomes are kept
+	outcomeLog := filepath.Join(t.TempDir(), "outcomes.jsonl")
+	if err := os.WriteFile(outcomeLog, []byte(`{"resourceGroup":"earlier","status":"deleted"}`+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to seed outcome log: %v", err)
+	}
+	plan := &CleanupPlan{
+		SubscriptionID: "test-subscription",
+		Groups: []CleanupPlanEntry{
+			{Name: "sandbox-alice"}, {Name: "sandbox-bob"}, {Name: "sandbox-carol"}, {Name: "sandbox-gone"},
+			{Name: "sandbox-dave"}, {Name: "sandbox-erin"},
+		},
+	}
+
+	// Guard rails are checked again before each DELETE: dave was locked and erin tagged after planning
+	rules, err := NewProtectionRules(ProtectionConfig{Tags: []string{"do-not-delete"}})
+	if err != nil {
+		t.Fatalf("failed to build protection rules: %v", err)
+	}
+	client.Protection = rules
+
+	outcomes, err := client.ApplyCleanupPlan(plan, CleanupApplyOptions{Execute: true, AssumeYes: true, OutcomeLog: outcomeLog})
+	if err == nil {
+		t.Fatal("Expected an error reporting the failed deletion")
+	}
+
+	expected := map[string]string{
+		"sandbox-alice": cleanupStatusDeleted,
+		"sandbox-bob":   cleanupStatusDeleted,
+		"sandbox-carol": cleanupStatusFailed,
+		"sandbox-gone":  cleanupStatusNotFound,
+		"sandbox-dave":  cleanupStatusSkipped,
+		"sandbox-erin":  cleanupStatusSkipped,
+	}
+	for _, outcome := range outcomes {
+		if outcome.Status != expected[outcome.ResourceGroup] {
+			t.Errorf("Expected %s to be %s, got %s (%s)", outcome.ResourceGroup, expected[outcome.ResourceGroup], outcome.Status, outcome.Error)
+		}
+	}
+	if outcomes[4].Reason != "locked: CanNotDelete (keep)" || !strings.HasPrefix(outcomes[5].Reason, "protected: ") {
+		t.Errorf("unexpected skip reasons %q, %q", outcomes[4].Reason, outcomes[5].Reason)
+	}
+	if polls["alice"] != 3 || polls["bob"] != 2 {
+		t.Errorf("Expected polling until completion, got %v", polls)
+	}
+
+	data, err := os.ReadFile(outcomeLog)
+	if err != nil {
+		t.Fatalf("failed to read outcome log: %v", err)
+	}
+	// Outcomes are appended in completion order
+	logged := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var outcome CleanupOutcome
+		if err := json.Unmarshal([]byte(line), &outcome); err != nil {
+			t.Fatalf("failed to parse outcome log line %q: %v", line, err)
+		}
+		logged[outcome.ResourceGroup] = outcome.Status
+	}
+	if len(logged) != len(expected)+1 || logged["earlier"] != cleanupStatusDeleted || logged["sandbox-alice"] != cleanupStatusDeleted {
+		t.Errorf("unexpected outcome log %v", logged)
+	}
+}
+
+func TestRetryAfterEnforcesPollInterval(t *testing.T) {
+	client := newCleanupTestClient(nil)
+	client.Config.PollInterval = 2 * time.Second
+
+	for header, want := range map[string]time.Duration{
+		"":     2 * time.Second,
+		"0":    2 * time.Second,
+		"-1":   2 * time.Second,
+		"soon": 2 * time.Second,
+		"5":    5 * time.Second,
+	} {
+		resp := jsonResponse(http.StatusAccepted, "")
+		if header != "" {
+			resp.Header.Set("Retry-After", header)
+		}
+		if got := client.retryAfter(resp); got != want {
+			t.Errorf("Retry-After %q: expected %v, got %v", header, want, got)
+		}
+	}
+
+	client.Config.PollInterval = 0
+	resp := jsonResponse(http.StatusAccepted, "")
+	resp.Header.Set("Retry-After", "0")
+	if got := client.retryAfter(resp); got != defaultPollInterval {
+		t.Errorf("expected the default interval, got %v", got)
+	}
+}
+
+func TestBuildCleanupPlanWithInventoryFilters(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.Contains(req.URL.Path, "Microsoft.Authorization/locks"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		case strings.HasSuffix(req.URL.Path, "/sandbox-alice/resources"):
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "vm", "name": "vm", "type": "Microsoft.Compute/virtualMachines", "createdTime": "2024-01-01T00:00:00Z"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/NetworkWatcherRG/resources"):
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "nw", "name": "nw", "type": "Microsoft.Network/networkWatchers", "createdTime": "2024-05-20T00:00:00Z"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/prod-app/resources"):
+			return jsonResponse(http.StatusForbidden, `{"error": {"code": "AuthorizationFailed", "message": "denied"}}`), nil
+		case strings.HasSuffix(req.URL.Path, "/resources"):
+			t.Errorf("Unexpected resource listing for a group the location filter rejects: %s", req.URL.Path)
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+
+	filter, err := NewResourceGroupFilter(FilterOptions{OlderThan: "30d", Locations: []string{"eastus"}}, now)
+	if err != nil {
+		t.Fatalf("failed to build filter: %v", err)
+	}
+	client.Filter = filter
+
+	plan, err := client.BuildCleanupPlan(CleanupPlanOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(plan.Groups) != 1 || plan.Groups[0].Name != "sandbox-alice" {
+		t.Fatalf("Expected only sandbox-alice to be planned, got %+v", plan.Groups)
+	}
+	if plan.Groups[0].Reason != "matches the inventory filters" {
+		t.Errorf("Expected the filter reason, got %q", plan.Groups[0].Reason)
+	}
+	if plan.Source != "filters" {
+		t.Errorf("Expected filters as the plan source, got %q", plan.Source)
+	}
+	// A group whose age is unknown must not be planned for deletion
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Name != "prod-app" || !strings.HasPrefix(plan.Skipped[0].Reason, "creation time unknown:") {
+		t.Errorf("Expected prod-app to be skipped with an unknown creation time, got %+v", plan.Skipped)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// columnStyle selects how missing and failed values are shown, matching the fixed outputs:
+// porcelain and table use "N/A"/"ERROR", CSV uses "Not available"/"Error: <message>"
+type columnStyle int
+
+const (
+	columnStyleCSV columnStyle = iota
+	columnStylePorcelain
+	columnStyleTable
+)
+
+// Column is an output field that can be picked with --columns
+type Column struct {
+	Key             string
+	CSVHeader       string
+	PorcelainHeader string
+	Value           func(result ResourceGroupResult, style columnStyle) string
+}
+
+// columnRegistry lists the fields available to --columns, in the order shown in help output
+var columnRegistry = []Column{
+	{"name", "ResourceGroupName", "NAME", func(r ResourceGroupResult, _ columnStyle) string { return r.ResourceGroup.Name }},
+	{"id", "ID", "ID", func(r ResourceGroupResult, _ columnStyle) string { return r.ResourceGroup.ID }},
+	{"subscription", "SubscriptionID", "SUBSCRIPTION", columnSubscription},
+	{"location", "Location", "LOCATION", func(r ResourceGroupResult, _ columnStyle) string { return r.ResourceGroup.Location }},
+	{"state", "ProvisioningState", "PROVISIONING_STATE", func(r ResourceGroupResult, _ columnStyle) string {
+		return r.ResourceGroup.Properties.ProvisioningState
+	}},
+	{"created_time", "CreatedTime", "CREATED_TIME", columnCreatedTime},
+	{"created_time_source", "CreatedTimeSource", "CREATED_TIME_SOURCE", func(r ResourceGroupResult, _ columnStyle) string { return r.CreatedTimeSource }},
+	{"creator", "Creator", "CREATOR", func(r ResourceGroupResult, _ columnStyle) string { return r.Creator }},
+	{"age_days", "AgeDays", "AGE_DAYS", columnAgeDays},
+	{"last_modified", "LastModified", "LAST_MODIFIED", columnLastModified},
+	{"days_since_change", "DaysSinceChange", "DAYS_SINCE_CHANGE", columnDaysSinceChange},
+	{"is_default", "IsDefault", "IS_DEFAULT", func(r ResourceGroupResult, _ columnStyle) string {
+		return strconv.FormatBool(checkIfDefaultResourceGroup(r.ResourceGroup.Name).IsDefault)
+	}},
+	{"created_by", "CreatedBy", "CREATED_BY", func(r ResourceGroupResult, _ columnStyle) string {
+		return checkIfDefaultResourceGroup(r.ResourceGroup.Name).CreatedBy
+	}},
+	{"description", "Description", "DESCRIPTION", func(r ResourceGroupResult, _ columnStyle) string {
+		return checkIfDefaultResourceGroup(r.ResourceGroup.Name).Description
+	}},
+	{"resource_count", "ResourceCount", "RESOURCE_COUNT", columnResourceCount},
+	{"resources", "Resources", "RESOURCES", func(r ResourceGroupResult, _ columnStyle) string { return formatResourcesCell(r.Resources) }},
+	{"tags", "Tags", "TAGS", func(r ResourceGroupResult, _ columnStyle) string { return formatTagsCell(r.ResourceGroup.Tags) }},
+	{"protected", "Protected", "PROTECTED", func(r ResourceGroupResult, _ columnStyle) string { return strconv.FormatBool(r.Protected) }},
+	{"protection_reason", "ProtectionReason", "PROTECTION_REASON", func(r ResourceGroupResult, _ columnStyle) string { return r.ProtectionReason }},
+	{"locks", "Locks", "LOCKS", columnLocks},
+	{"inferred_owners", "InferredOwners", "INFERRED_OWNERS", columnInferredOwners},
+	{"role_assignments", "RoleAssignments", "ROLE_ASSIGNMENTS", columnRoleAssignments},
+	{"non_compliant_resources", "NonCompliantResources", "NON_COMPLIANT_RESOURCES", columnNonCompliantResources},
+	{"non_compliant_policies", "NonCompliantPolicies", "NON_COMPLIANT_POLICIES", columnNonCompliantPolicies},
+	{"cost", "Cost", "COST", columnCost},
+	{"cost_currency", "CostCurrency", "COST_CURRENCY", func(r ResourceGroupResult, _ columnStyle) string { return r.CostCurrency }},
+	{"tag_gap_resources", "TagGapResources", "TAG_GAP_RESOURCES", columnTagGapResources},
+	{"tag_gaps", "TagGaps", "TAG_GAPS", columnTagGaps},
+	{"error", "Error", "ERROR", func(r ResourceGroupResult, _ columnStyle) string {
+		if r.Error != nil {
+			return r.Error.Error()
+		}
+		return ""
+	}},
+}
+
+// columnAliases maps alternative names to registry keys
+var columnAliases = map[string]string{
+	"resource_group":     "name",
+	"provisioning_state": "state",
+	"created":            "created_time",
+	"age":                "age_days",
+	"default":            "is_default",
+	"owners":             "inferred_owners",
+}
+
+// tagColumnPrefix selects a single tag value, e.g. tags.owner
+const tagColumnPrefix = "tags."
+
+// ParseColumns resolves a comma-separated --columns value against the registry. Keys are
+// case-insensitive and may use '-' or '_'; tags.<key> selects the value of one tag.
+func ParseColumns(spec string) ([]Column, error) {
+	var columns []Column
+	for _, raw := range strings.Split(spec, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if len(raw) > len(tagColumnPrefix) && strings.EqualFold(raw[:len(tagColumnPrefix)], tagColumnPrefix) {
+			columns = append(columns, tagColumn(raw[len(tagColumnPrefix):]))
+			continue
+		}
+
+		key := strings.ReplaceAll(strings.ToLower(raw), "-", "_")
+		if alias, ok := columnAliases[key]; ok {
+			key = alias
+		}
+		column, ok := lookupColumn(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s, tags.<key>)", raw, strings.Join(columnKeys(), ", "))
+		}
+		columns = append(columns, column)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return columns, nil
+}
+
+func lookupColumn(key string) (Column, bool) {
+	for _, column := range columnRegistry {
+		if column.Key == key {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// columnKeys returns the registry keys
+func columnKeys() []string {
+	keys := make([]string, len(columnRegistry))
+	for i, column := range columnRegistry {
+		keys[i] = column.Key
+	}
+	return keys
+}
+
+// tagColumn builds the column for one tag; the key is matched case-insensitively
+func tagColumn(tagKey string) Column {
+	header := tagColumnPrefix + tagKey
+	return Column{
+		Key:             header,
+		CSVHeader:       header,
+		PorcelainHeader: header,
+		Value: func(r ResourceGroupResult, _ columnStyle) string {
+			_, value, _ := lookupTag(r.ResourceGroup.Tags, tagKey)
+			return value
+		},
+	}
+}
+
+func columnSubscription(r ResourceGroupResult, _ columnStyle) string {
+	id, err := ParseResourceID(r.ResourceGroup.ID)
+	if err != nil {
+		return ""
+	}
+	return id.SubscriptionID
+}
+
+func columnCreatedTime(r ResourceGroupResult, style columnStyle) string {
+	switch {
+	case r.CreatedTime != nil:
+		return r.CreatedTime.Format(time.RFC3339)
+	case r.Error != nil && style == columnStyleCSV:
+		return "Error: " + r.Error.Error()
+	case r.Error != nil:
+		return "ERROR"
+	case style == columnStyleCSV:
+		return "Not available"
+	default:
+		return "N/A"
+	}
+}
+
+func columnAgeDays(r ResourceGroupResult, style columnStyle) string {
+	if r.CreatedTime != nil {
+		return strconv.Itoa(ageInDays(*r.CreatedTime))
+	}
+	if style == columnStyleCSV {
+		return ""
+	}
+	return "N/A"
+}
+
+func columnResourceCount(r ResourceGroupResult, style columnStyle) string {
+	if r.Error != nil {
+		if style == columnStyleCSV {
+			return ""
+		}
+		return "ERROR"
+	}
+	return strconv.Itoa(len(r.Resources))
+}
+
+func columnLocks(r ResourceGroupResult, style columnStyle) string {
+	switch {
+	case r.LocksError != nil && style == columnStyleCSV:
+		return "Error: " + r.LocksError.Error()
+	case r.LocksError != nil:
+		return "ERROR"
+	case style == columnStyleCSV:
+		return formatLocks(r.Locks)
+	default:
+		return lockLevels(r.Locks)
+	}
+}
+
+// formatTagsCell renders tags as sorted key=value pairs joined by "; "
+func formatTagsCell(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "; ")
+}
+
+// columnValues renders one result for the selected columns
+func columnValues(columns []Column, result ResourceGroupResult, style columnStyle) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = column.Value(result, style)
+		if style != columnStyleCSV {
+			values[i] = columnSeparatorReplacer.Replace(values[i])
+		}
+	}
+	return values
+}
+
+// columnSeparatorReplacer keeps tag values and error messages containing tabs or line breaks
+// from splitting porcelain and table rows; CSV quotes such values instead
+var columnSeparatorReplacer = strings.NewReplacer("\r\n", " ", "\t", " ", "\r", " ", "\n", " ")
+
+// writePorcelainRow writes one tab-separated porcelain row, passing every field through
+// columnSeparatorReplacer so a tag value or message cannot shift the columns or split the row
+func writePorcelainRow(w io.Writer, fields ...string) {
+	sanitized := make([]string, len(fields))
+	for i, field := range fields {
+		sanitized[i] = columnSeparatorReplacer.Replace(field)
+	}
+	fmt.Fprintln(w, strings.Join(sanitized, "\t"))
+}
+
+// columnHeaders returns the CSV or porcelain headers of the selected columns
+func columnHeaders(columns []Column, style columnStyle) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		if style == columnStylePorcelain {
+			headers[i] = column.PorcelainHeader
+		} else {
+			headers[i] = column.CSVHeader
+		}
+	}
+	return headers
+}
+
+// writeColumnsCSV writes the selected columns of each result to the CSV output file
+func (ac *AzureClient) writeColumnsCSV(results []ResourceGroupResult) error {
+	records := make([][]string, 0, len(results)+1)
+	records = append(records, columnHeaders(ac.Columns, columnStyleCSV))
+	for _, result := range results {
+		records = append(records, columnValues(ac.Columns, result, columnStyleCSV))
+	}
+	return writeCSVRecords(ac.Config.OutputCSV, records)
+}
+
+// renderColumnsTable prints the selected columns as an aligned table
+func (ac *AzureClient) renderColumnsTable(w io.Writer, results []ResourceGroupResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := columnHeaders(ac.Columns, columnStyleTable)
+	separators := make([]string, len(headers))
+	for i, header := range headers {
+		separators[i] = strings.Repeat("-", len(header))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	fmt.Fprintln(tw, strings.Join(separators, "\t"))
+	for _, result := range results {
+		fmt.Fprintln(tw, strings.Join(columnValues(ac.Columns, result, columnStyleTable), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
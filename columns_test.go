@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("Name, provisioning-state,AGE,tags.Owner,created_by")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"name", "state", "age_days", "tags.Owner", "created_by"}
+	if len(columns) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(columns))
+	}
+	for i, key := range expected {
+		if columns[i].Key != key {
+			t.Errorf("column %d: expected %q, got %q", i, key, columns[i].Key)
+		}
+	}
+
+	for _, spec := range []string{"", " , ", "name,owner"} {
+		if _, err := ParseColumns(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestColumnValues(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 1, 31, 12, 0, 0, 0, time.UTC))
+
+	columns, err := ParseColumns("name,subscription,created_time,age_days,is_default,resource_count,tags.OWNER,tags")
+	if err != nil {
+		t.Fatalf("failed to parse columns: %v", err)
+	}
+
+	results := outputTestResults()
+	got := columnValues(columns, results[0], columnStylePorcelain)
+	expected := []string{"sandbox-alice", "test", "2023-01-01T12:00:00Z", "30", "false", "1", "alice", "owner=alice"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected values:\n got %v\nwant %v", got, expected)
+	}
+
+	failed := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "broken"}, Error: errors.New("boom")}
+	if got := columnValues(columns, failed, columnStyleCSV); got[2] != "Error: boom" || got[3] != "" || got[5] != "" {
+		t.Errorf("unexpected CSV values for failed lookup: %v", got)
+	}
+	if got := columnValues(columns, failed, columnStylePorcelain); got[2] != "ERROR" || got[3] != "N/A" || got[5] != "ERROR" {
+		t.Errorf("unexpected porcelain values for failed lookup: %v", got)
+	}
+
+	// Tabs and line breaks in values would split porcelain and table rows
+	tagged := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "tagged", Tags: map[string]string{"owner": "ops\tteam\r\nb"}}}
+	for _, style := range []columnStyle{columnStylePorcelain, columnStyleTable} {
+		if got := columnValues(columns, tagged, style); got[6] != "ops team b" || got[7] != "owner=ops team b" {
+			t.Errorf("expected separators replaced in style %d, got %q", style, got)
+		}
+	}
+	if got := columnValues(columns, tagged, columnStyleCSV); got[6] != "ops\tteam\r\nb" {
+		t.Errorf("expected the raw value in CSV, got %q", got[6])
+	}
+}
+
+func TestColumnsAcrossOutputs(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/resources") {
+				return jsonResponse(http.StatusOK, `{"value": [{"name": "a", "type": "Microsoft.Web/sites", "createdTime": "2023-01-01T12:00:00Z"}, {"name": "b", "type": "Microsoft.Web/sites"}]}`), nil
+			}
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "/subscriptions/test-subscription/resourceGroups/rg-one", "name": "rg-one", "location": "eastus", "tags": {"owner": "ops"}, "properties": {"provisioningState": "Succeeded"}}]}`), nil
+		},
+	}
+
+	columns, err := ParseColumns("location,name,resource_count,tags.owner")
+	if err != nil {
+		t.Fatalf("failed to parse columns: %v", err)
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "out.csv")
+	client := &AzureClient{
+		Config: Config{
+			SubscriptionID: "test-subscription",
+			AccessToken:    "test-token",
+			MaxConcurrency: 2,
+			Porcelain:      true,
+			OutputCSV:      csvPath,
+		},
+		HTTPClient: mockClient,
+		Columns:    columns,
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	err = client.FetchResourceGroups()
+
+	if closeErr := w.Close(); closeErr != nil {
+		t.Errorf("Failed to close pipe writer: %v", closeErr)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Errorf("Failed to copy output: %v", copyErr)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if buf.String() != "LOCATION\tNAME\tRESOURCE_COUNT\ttags.owner\neastus\trg-one\t2\tops\n" {
+		t.Errorf("unexpected porcelain output:\n%q", buf.String())
+	}
+
+	csvContent, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	if string(csvContent) != "Location,ResourceGroupName,ResourceCount,tags.owner\neastus,rg-one,2,ops\n" {
+		t.Errorf("unexpected CSV output:\n%q", csvContent)
+	}
+
+	// The aligned table uses the same columns
+	client.Config.Porcelain = false
+	client.Config.OutputFormat = outputTable
+	var table bytes.Buffer
+	if err := client.renderInventory(&table, []ResourceGroupResult{{ResourceGroup: ResourceGroup{Name: "rg-one", Location: "eastus"}}}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if header := strings.Fields(strings.Split(table.String(), "\n")[0]); strings.Join(header, ",") != "Location,ResourceGroupName,ResourceCount,tags.owner" {
+		t.Errorf("unexpected table header %v", header)
+	}
+}
+
+func TestWritePorcelainRow(t *testing.T) {
+	var buf bytes.Buffer
+	writePorcelainRow(&buf, "sandbox-bob", "owner", "invalid", "bob\tsmith\r\nops")
+	if buf.String() != "sandbox-bob\towner\tinvalid\tbob smith ops\n" {
+		t.Errorf("unexpected porcelain row %q", buf.String())
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCostPeriod is the default --cost-period: the last 30 days
+const defaultCostPeriod = "30d"
+
+// costTimeframes maps the named --cost-period values to Cost Management timeframes
+var costTimeframes = map[string]string{
+	"month-to-date": "MonthToDate",
+	"last-month":    "TheLastMonth",
+	"week-to-date":  "WeekToDate",
+}
+
+// CostPeriod is a parsed --cost-period: a named timeframe, or a custom window ending now
+type CostPeriod struct {
+	Label     string
+	Timeframe string
+	From, To  time.Time
+}
+
+// parseCostPeriod accepts month-to-date, last-month, week-to-date or an age such as 30d or 2w
+func parseCostPeriod(value string, now time.Time) (CostPeriod, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if timeframe, ok := costTimeframes[value]; ok {
+		return CostPeriod{Label: value, Timeframe: timeframe}, nil
+	}
+	age, err := parseAge(value)
+	if err != nil || age <= 0 {
+		return CostPeriod{}, fmt.Errorf("unsupported cost period %q (use month-to-date, last-month, week-to-date or an age such as 30d)", value)
+	}
+	now = now.UTC()
+	return CostPeriod{Label: "last " + value, Timeframe: "Custom", From: now.Add(-age), To: now}, nil
+}
+
+// CostQuery is the body of a Cost Management query grouped by resource group
+type CostQuery struct {
+	Type       string          `json:"type"`
+	Timeframe  string          `json:"timeframe"`
+	TimePeriod *CostTimePeriod `json:"timePeriod,omitempty"`
+	Dataset    CostDataset     `json:"dataset"`
+}
+
+type CostTimePeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type CostDataset struct {
+	Granularity string                     `json:"granularity"`
+	Aggregation map[string]CostAggregation `json:"aggregation"`
+	Grouping    []CostGrouping             `json:"grouping"`
+}
+
+type CostAggregation struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+}
+
+type CostGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// CostQueryResponse is one page of query results; rows are positional, described by columns
+type CostQueryResponse struct {
+	Properties struct {
+		NextLink string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"properties"`
+}
+
+// CostTable holds the cost of every resource group in the subscription for one period
+type CostTable struct {
+	Period   CostPeriod
+	Currency string
+	groups   map[string]GroupCost
+}
+
+// GroupCost is a resource group's total for the period
+type GroupCost struct {
+	Amount   float64
+	Currency string
+}
+
+// Lookup returns a group's cost; groups without usage in the period cost zero
+func (t *CostTable) Lookup(resourceGroupName string) GroupCost {
+	if cost, ok := t.groups[strings.ToLower(resourceGroupName)]; ok {
+		return cost
+	}
+	return GroupCost{Currency: t.Currency}
+}
+
+func newCostQuery(period CostPeriod) CostQuery {
+	query := CostQuery{
+		Type:      "ActualCost",
+		Timeframe: period.Timeframe,
+		Dataset: CostDataset{
+			Granularity: "None",
+			Aggregation: map[string]CostAggregation{"totalCost": {Name: "Cost", Function: "Sum"}},
+			Grouping:    []CostGrouping{{Type: "Dimension", Name: "ResourceGroupName"}},
+		},
+	}
+	if period.Timeframe == "Custom" {
+		query.TimePeriod = &CostTimePeriod{From: period.From, To: period.To}
+	}
+	return query
+}
+
+// fetchCostTable runs one Cost Management query for the whole subscription, grouped by
+// resource group name. Names come back lower-cased, so lookups are case-insensitive.
+func (ac *AzureClient) fetchCostTable(period CostPeriod) (*CostTable, error) {
+	payload, err := json.Marshal(newCostQuery(period))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cost query: %w", err)
+	}
+
+	table := &CostTable{Period: period, groups: make(map[string]GroupCost)}
+	url := NewSubscriptionResourceID(ac.Config.SubscriptionID).
+		URL("providers/Microsoft.CostManagement/query", apiVersion("2023-03-01"))
+	for url != "" {
+		resp, err := ac.doAzureRequest("POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query costs: %w", err)
+		}
+
+		var page CostQueryResponse
+		if err := decodeAzureResponse(resp, &page); err != nil {
+			return nil, err
+		}
+		if err := table.addRows(page); err != nil {
+			return nil, err
+		}
+		url = page.Properties.NextLink
+	}
+	return table, nil
+}
+
+// addRows adds a page of rows, locating the cost, group and currency columns by name and type
+func (t *CostTable) addRows(page CostQueryResponse) error {
+	costColumn, groupColumn, currencyColumn := -1, -1, -1
+	for i, column := range page.Properties.Columns {
+		switch {
+		case strings.EqualFold(column.Name, "ResourceGroupName"):
+			groupColumn = i
+		case strings.EqualFold(column.Name, "Currency"):
+			currencyColumn = i
+		case costColumn < 0 && strings.EqualFold(column.Type, "Number"):
+			costColumn = i
+		}
+	}
+	if len(page.Properties.Rows) == 0 {
+		return nil
+	}
+	if costColumn < 0 || groupColumn < 0 {
+		return fmt.Errorf("unexpected cost query result: missing cost or ResourceGroupName column")
+	}
+
+	for _, row := range page.Properties.Rows {
+		if len(row) <= costColumn || len(row) <= groupColumn {
+			continue
+		}
+		amount, ok := row[costColumn].(float64)
+		if !ok {
+			continue
+		}
+		name, _ := row[groupColumn].(string)
+		currency := ""
+		if currencyColumn >= 0 && len(row) > currencyColumn {
+			currency, _ = row[currencyColumn].(string)
+		}
+		if t.Currency == "" {
+			t.Currency = currency
+		}
+
+		// Costs outside any resource group (e.g. reservations) have an empty name
+		key := strings.ToLower(name)
+		cost := t.groups[key]
+		cost.Amount += amount
+		cost.Currency = currency
+		t.groups[key] = cost
+	}
+	return nil
+}
+
+// loadCosts runs the cost query once before the per-group lookups
+func (ac *AzureClient) loadCosts() error {
+	period, err := parseCostPeriod(ac.Config.CostPeriod, inventoryNow())
+	if err != nil {
+		return err
+	}
+	ac.Costs, err = ac.fetchCostTable(period)
+	return err
+}
+
+// applyCosts drops groups rejected by the cost filters and, with --sort-by-cost, orders the
+// rest by cost, most expensive first. Results keep this order.
+func (ac *AzureClient) applyCosts(resourceGroups []ResourceGroup) []ResourceGroup {
+	matched := make([]ResourceGroup, 0, len(resourceGroups))
+	for _, rg := range resourceGroups {
+		if ac.Filter.MatchCost(ac.Costs.Lookup(rg.Name).Amount) {
+			matched = append(matched, rg)
+		}
+	}
+	if ac.Config.SortByCost {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return ac.Costs.Lookup(matched[i].Name).Amount > ac.Costs.Lookup(matched[j].Name).Amount
+		})
+	}
+	return matched
+}
+
+// CostSummary totals the cost of the scanned groups, split into Azure-created defaults and the rest
+type CostSummary struct {
+	Period   string  `json:"period"`
+	Currency string  `json:"currency"`
+	Total    float64 `json:"total"`
+	Default  float64 `json:"default"`
+	User     float64 `json:"user"`
+}
+
+func buildCostSummary(results []ResourceGroupResult, table *CostTable) *CostSummary {
+	if table == nil {
+		return nil
+	}
+	summary := &CostSummary{Period: table.Period.Label, Currency: table.Currency}
+	for _, result := range results {
+		if result.Cost == nil {
+			continue
+		}
+		if checkIfDefaultResourceGroup(result.ResourceGroup.Name).IsDefault {
+			summary.Default += *result.Cost
+		} else {
+			summary.User += *result.Cost
+		}
+	}
+	summary.Total = roundCost(summary.Default + summary.User)
+	summary.Default = roundCost(summary.Default)
+	summary.User = roundCost(summary.User)
+	return summary
+}
+
+// roundCost rounds to cents so summed floats print cleanly
+func roundCost(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func formatCost(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func columnCost(r ResourceGroupResult, style columnStyle) string {
+	if r.Cost != nil {
+		return formatCost(*r.Cost)
+	}
+	if style == columnStyleCSV {
+		return ""
+	}
+	return "N/A"
+}
+
+// costColumns returns the extra porcelain header columns when --cost is set
+func (ac *AzureClient) costColumns() []string {
+	if !ac.Config.Cost {
+		return nil
+	}
+	return []string{"COST", "COST_CURRENCY"}
+}
+
+// costValues returns the porcelain values matching costColumns
+func (ac *AzureClient) costValues(result ResourceGroupResult) []string {
+	if !ac.Config.Cost {
+		return nil
+	}
+	return []string{columnCost(result, columnStylePorcelain), result.CostCurrency}
+}
+
+// printCost prints a group's cost in the human-readable output
+func (ac *AzureClient) printCost(result ResourceGroupResult) {
+	if result.Cost == nil || ac.Costs == nil {
+		return
+	}
+	fmt.Printf("  💰 Cost (%s): %s %s\n", ac.Costs.Period.Label, formatCost(*result.Cost), result.CostCurrency)
+}
+
+// printCostTotals prints the cost totals after the human-readable listing
+func printCostTotals(summary *CostSummary) {
+	if summary == nil {
+		return
+	}
+	fmt.Printf("Cost (%s): %s %s total, %s created by Azure, %s user-created\n", summary.Period,
+		formatCost(summary.Total), summary.Currency, formatCost(summary.Default), formatCost(summary.User))
+}
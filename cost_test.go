@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCostPeriod(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	period, err := parseCostPeriod("Month-To-Date", now)
+	if err != nil || period.Timeframe != "MonthToDate" || period.Label != "month-to-date" {
+		t.Errorf("unexpected period %+v (%v)", period, err)
+	}
+
+	period, err = parseCostPeriod("30d", now)
+	if err != nil || period.Timeframe != "Custom" || period.Label != "last 30d" {
+		t.Fatalf("unexpected period %+v (%v)", period, err)
+	}
+	if !period.From.Equal(now.AddDate(0, 0, -30)) || !period.To.Equal(now) {
+		t.Errorf("unexpected window %v - %v", period.From, period.To)
+	}
+
+	for _, value := range []string{"", "0d", "yesterday", "-5d"} {
+		if _, err := parseCostPeriod(value, now); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestFetchCostTable(t *testing.T) {
+	requests := 0
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/subscriptions/test-subscription/providers/Microsoft.CostManagement/query") {
+			t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		}
+		body, _ := io.ReadAll(req.Body)
+		var query CostQuery
+		if err := json.Unmarshal(body, &query); err != nil {
+			t.Fatalf("invalid query %s", body)
+		}
+		if query.Timeframe != "Custom" || query.TimePeriod == nil || query.Dataset.Grouping[0].Name != "ResourceGroupName" {
+			t.Errorf("unexpected query %s", body)
+		}
+
+		if req.URL.Query().Get("page") == "2" {
+			return jsonResponse(http.StatusOK, `{"properties": {"columns": [{"name": "Cost", "type": "Number"}, {"name": "ResourceGroupName", "type": "String"}, {"name": "Currency", "type": "String"}],
+				"rows": [[1.5, "prod-app", "EUR"]]}}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"properties": {
+			"nextLink": "https://management.azure.com/subscriptions/test-subscription/providers/Microsoft.CostManagement/query?page=2",
+			"columns": [{"name": "Cost", "type": "Number"}, {"name": "ResourceGroupName", "type": "String"}, {"name": "Currency", "type": "String"}],
+			"rows": [[120.25, "prod-app", "EUR"], [3.1, "networkwatcherrg", "EUR"], [9, "", "EUR"]]}}`), nil
+	})
+
+	period, _ := parseCostPeriod("30d", time.Now())
+	table, err := client.fetchCostTable(period)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if cost := table.Lookup("Prod-App"); cost.Amount != 121.75 || cost.Currency != "EUR" {
+		t.Errorf("unexpected cost %+v", cost)
+	}
+	if cost := table.Lookup("sandbox-alice"); cost.Amount != 0 || cost.Currency != "EUR" {
+		t.Errorf("expected zero cost for a group without usage, got %+v", cost)
+	}
+}
+
+func testCostTable() *CostTable {
+	return &CostTable{
+		Period:   CostPeriod{Label: "month-to-date"},
+		Currency: "USD",
+		groups: map[string]GroupCost{
+			"prod-app":         {Amount: 250.5, Currency: "USD"},
+			"sandbox-bob":      {Amount: 12.125, Currency: "USD"},
+			"networkwatcherrg": {Amount: 0.8, Currency: "USD"},
+		},
+	}
+}
+
+func costTestGroups(t *testing.T) []ResourceGroup {
+	var response ResourceGroupsResponse
+	if err := json.Unmarshal([]byte(cleanupTestResourceGroups), &response); err != nil {
+		t.Fatalf("invalid test groups: %v", err)
+	}
+	return response.Value
+}
+
+func TestApplyCostsFiltersAndSorts(t *testing.T) {
+	filter, err := NewResourceGroupFilter(FilterOptions{MinCost: "0.5"}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := &AzureClient{Config: Config{Cost: true, SortByCost: true}, Filter: filter, Costs: testCostTable()}
+
+	var names []string
+	for _, rg := range client.applyCosts(costTestGroups(t)) {
+		names = append(names, rg.Name)
+	}
+	if strings.Join(names, ",") != "prod-app,sandbox-bob,NetworkWatcherRG" {
+		t.Errorf("unexpected groups %v", names)
+	}
+
+	if _, err := NewResourceGroupFilter(FilterOptions{MaxCost: "cheap"}, time.Now()); err == nil {
+		t.Error("Expected error for an invalid --max-cost")
+	}
+	filter, _ = NewResourceGroupFilter(FilterOptions{MinCost: "1", MaxCost: "100"}, time.Now())
+	if filter.MatchCost(0.99) || !filter.MatchCost(100) || filter.MatchCost(100.01) {
+		t.Error("unexpected cost bounds")
+	}
+}
+
+func TestCostOutputs(t *testing.T) {
+	client := &AzureClient{Config: Config{SubscriptionID: "test-subscription", Cost: true}, Costs: testCostTable()}
+
+	var results []ResourceGroupResult
+	for _, rg := range costTestGroups(t) {
+		result := ResourceGroupResult{ResourceGroup: rg}
+		client.enrichResourceGroupResult(&result)
+		results = append(results, result)
+	}
+
+	summary := client.inventorySummary(results)
+	if summary.Cost == nil || summary.Cost.Total != 263.43 || summary.Cost.Default != 0.8 || summary.Cost.User != 262.63 {
+		t.Errorf("unexpected cost summary %+v", summary.Cost)
+	}
+	if got := strings.Join(client.costValues(results[2]), ","); got != "250.50,USD" {
+		t.Errorf("unexpected porcelain values %s", got)
+	}
+	if got := columnCost(ResourceGroupResult{}, columnStylePorcelain); got != "N/A" {
+		t.Errorf("expected N/A, got %q", got)
+	}
+
+	inventory := client.buildInventory(results, false)
+	if inventory[1].Cost == nil || *inventory[1].Cost != 12.13 || inventory[1].CostCurrency != "USD" {
+		t.Errorf("unexpected inventory %+v", inventory[1])
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := client.writeHTMLReport(path, results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), `<td data-sort="250.5">250.50 USD</td>`) {
+		t.Error("expected the cost column in the HTML report")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+// enrichmentStep is one optional per-group lookup. Steps run in order after the creation time
+// is resolved and only for groups that pass the creation time filters.
+type enrichmentStep struct {
+	enabled func(ac *AzureClient, result *ResourceGroupResult) bool
+	apply   func(ac *AzureClient, result *ResourceGroupResult)
+}
+
+// enrichmentSteps are the optional lookups: guard rails (protection rules and locks), the last
+// modified time, ownership role assignments, policy compliance, the cost and the resources
+// missing the group's tags. Steps that read the resource listing are skipped when it failed.
+var enrichmentSteps = []enrichmentStep{
+	{
+		enabled: func(ac *AzureClient, _ *ResourceGroupResult) bool { return ac.Protection != nil },
+		apply: func(ac *AzureClient, result *ResourceGroupResult) {
+			result.Protected, result.ProtectionReason = ac.Protection.Check(result.ResourceGroup)
+		},
+	},
+	{
+		enabled: func(ac *AzureClient, _ *ResourceGroupResult) bool { return ac.Config.CheckLocks },
+		apply: func(ac *AzureClient, result *ResourceGroupResult) {
+			result.Locks, result.LocksError = ac.fetchResourceGroupLocks(result.ResourceGroup.Name)
+		},
+	},
+	{
+		enabled: func(ac *AzureClient, result *ResourceGroupResult) bool {
+			return ac.Config.LastModified && result.Error == nil
+		},
+		apply: (*AzureClient).resolveLastModified,
+	},
+	{
+		enabled: func(ac *AzureClient, _ *ResourceGroupResult) bool { return ac.Config.RoleAssignments },
+		apply:   (*AzureClient).resolveOwnership,
+	},
+	{
+		enabled: func(ac *AzureClient, _ *ResourceGroupResult) bool { return ac.Config.PolicyCompliance },
+		apply: func(ac *AzureClient, result *ResourceGroupResult) {
+			result.Policy, result.PolicyError = ac.fetchPolicySummary(result.ResourceGroup.Name)
+		},
+	},
+	{
+		enabled: func(ac *AzureClient, _ *ResourceGroupResult) bool { return ac.Costs != nil },
+		apply: func(ac *AzureClient, result *ResourceGroupResult) {
+			cost := ac.Costs.Lookup(result.ResourceGroup.Name)
+			result.Cost, result.CostCurrency = &cost.Amount, cost.Currency
+		},
+	},
+	{
+		enabled: func(ac *AzureClient, result *ResourceGroupResult) bool {
+			return ac.Config.TagInheritance && result.Error == nil
+		},
+		apply: func(ac *AzureClient, result *ResourceGroupResult) {
+			result.TagInheritance = checkTagInheritance(result.ResourceGroup.Tags, result.Resources, ac.Config.TagInheritanceKeys)
+		},
+	},
+}
+
+// enrichResourceGroupResult runs the enabled enrichment steps on a result
+func (ac *AzureClient) enrichResourceGroupResult(result *ResourceGroupResult) {
+	for _, step := range enrichmentSteps {
+		if step.enabled(ac, result) {
+			step.apply(ac, result)
+		}
+	}
+}
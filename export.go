@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Export outcome statuses
+const (
+	exportStatusExported = "exported"
+	exportStatusFailed   = "failed"
+)
+
+// exportTemplateRequest is the body sent to the resource group exportTemplate operation
+type exportTemplateRequest struct {
+	Resources []string `json:"resources"`
+	Options   string   `json:"options,omitempty"`
+}
+
+// exportTemplateResult is returned by exportTemplate; Error is set when some resources could not be exported
+type exportTemplateResult struct {
+	Template json.RawMessage `json:"template"`
+	Error    *struct {
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Details []json.RawMessage `json:"details,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+// ExportOptions controls which resource groups are exported and where
+type ExportOptions struct {
+	GroupSelection
+	ArchiveDir string
+}
+
+// ExportOutcome records the result of exporting a single resource group
+type ExportOutcome struct {
+	ResourceGroup string `json:"resourceGroup"`
+	Status        string `json:"status"`
+	Directory     string `json:"directory,omitempty"`
+	Warning       string `json:"warning,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// exportMetadata is written next to each exported template
+type exportMetadata struct {
+	SubscriptionID string        `json:"subscriptionId"`
+	ExportedAt     time.Time     `json:"exportedAt"`
+	ResourceGroup  ResourceGroup `json:"resourceGroup"`
+	ResourceCount  int           `json:"resourceCount"`
+	ExportWarning  string        `json:"exportWarning,omitempty"`
+}
+
+// Export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Back up resource groups as ARM templates before deleting them",
+	Long: `Exports the ARM template of the selected resource groups (via the exportTemplate
+operation) together with their resource listing and metadata into an archive directory,
+so groups can be recreated if a deletion is regretted. Groups can be selected by name,
+by regular expression, or by passing a cleanup plan produced by plan-cleanup.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		planFile, _ := cmd.Flags().GetString("from-plan")
+		archiveDir, _ := cmd.Flags().GetString("archive-dir")
+		pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
+
+		azureClient.Config.PollInterval = pollInterval
+
+		if _, err := azureClient.ExportResourceGroups(ExportOptions{
+			GroupSelection: GroupSelection{Groups: groups, NamePattern: match, PlanFile: planFile},
+			ArchiveDir:     archiveDir,
+		}); err != nil {
+			log.Fatalf("Error exporting resource groups: %v", err)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringSlice("group", nil, "Resource group name to export (repeatable or comma-separated)")
+	exportCmd.Flags().String("match", "", "Regular expression selecting resource group names to export")
+	exportCmd.Flags().String("from-plan", "", "Export every resource group listed in a cleanup plan")
+	exportCmd.Flags().String("archive-dir", "rg-backups", "Directory to write exported templates to")
+	exportCmd.Flags().Duration("poll-interval", defaultPollInterval, "Interval between polls of asynchronous export operations when Azure sends no Retry-After")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+// ExportResourceGroups exports the selected resource groups into a timestamped directory under the archive
+func (ac *AzureClient) ExportResourceGroups(opts ExportOptions) ([]ExportOutcome, error) {
+	if opts.GroupSelection.IsEmpty() {
+		return nil, fmt.Errorf("one of --group, --match or --from-plan must be provided to select resource groups")
+	}
+
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no resource groups matched the export selection")
+	}
+
+	runDir := filepath.Join(opts.ArchiveDir, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	fmt.Printf("Exporting %d resource groups to %s...\n", len(selected), runDir)
+
+	var wg sync.WaitGroup
+	outcomes := make([]ExportOutcome, len(selected))
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent exports
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, rg := range selected {
+		wg.Add(1)
+		go func(i int, rg ResourceGroup) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			outcomes[i] = ac.exportResourceGroup(rg, runDir)
+		}(i, rg)
+	}
+
+	wg.Wait()
+
+	failed := 0
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Status == exportStatusFailed:
+			failed++
+			fmt.Printf("  %s: %s (%s)\n", outcome.ResourceGroup, outcome.Status, outcome.Error)
+		case outcome.Warning != "":
+			fmt.Printf("  %s: %s with warnings (%s)\n", outcome.ResourceGroup, outcome.Status, outcome.Warning)
+		default:
+			fmt.Printf("  %s: %s\n", outcome.ResourceGroup, outcome.Status)
+		}
+	}
+
+	if failed > 0 {
+		return outcomes, fmt.Errorf("%d of %d resource group exports failed", failed, len(outcomes))
+	}
+	return outcomes, nil
+}
+
+// exportResourceGroup writes template.json, resources.json and metadata.json for one resource group
+func (ac *AzureClient) exportResourceGroup(rg ResourceGroup, runDir string) ExportOutcome {
+	outcome := ExportOutcome{ResourceGroup: rg.Name, Status: exportStatusFailed}
+
+	result, err := ac.exportResourceGroupTemplate(rg.Name)
+	if err != nil {
+		outcome.Error = err.Error()
+		return outcome
+	}
+	if result.Error != nil {
+		outcome.Warning = fmt.Sprintf("%s: %s", result.Error.Code, result.Error.Message)
+	}
+
+	resources, err := ac.fetchResourcesInGroup(rg.Name)
+	if err != nil {
+		outcome.Error = err.Error()
+		return outcome
+	}
+
+	dir := filepath.Join(runDir, exportDirName(rg.Name))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		outcome.Error = fmt.Sprintf("failed to create export directory: %v", err)
+		return outcome
+	}
+
+	metadata := exportMetadata{
+		SubscriptionID: ac.Config.SubscriptionID,
+		ExportedAt:     time.Now().UTC(),
+		ResourceGroup:  rg,
+		ResourceCount:  len(resources),
+		ExportWarning:  outcome.Warning,
+	}
+
+	files := map[string]interface{}{
+		"template.json":  result.Template,
+		"resources.json": resources,
+		"metadata.json":  metadata,
+	}
+	for name, content := range files {
+		if err := writeJSONFile(filepath.Join(dir, name), content); err != nil {
+			outcome.Error = err.Error()
+			return outcome
+		}
+	}
+
+	outcome.Status = exportStatusExported
+	outcome.Directory = dir
+	return outcome
+}
+
+// exportResourceGroupTemplate calls exportTemplate, following the asynchronous 202 flow when Azure uses it
+func (ac *AzureClient) exportResourceGroupTemplate(resourceGroupName string) (*exportTemplateResult, error) {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).
+		URL("exportTemplate", apiVersion("2021-04-01"))
+
+	payload, err := json.Marshal(exportTemplateRequest{
+		Resources: []string{"*"},
+		Options:   "IncludeParameterDefaultValue,IncludeComments",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export request: %w", err)
+	}
+
+	resp, err := ac.doAzureRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to export template: %w", err)
+	}
+
+	var body []byte
+	if resp.StatusCode == http.StatusAccepted {
+		body, err = ac.waitForAsyncOperation(resp)
+	} else {
+		body, err = readResponseBody(resp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to export template: %w", err)
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, fmt.Errorf("export returned no template")
+	}
+
+	var result exportTemplateResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse exported template: %w", err)
+	}
+	if len(result.Template) == 0 || string(result.Template) == "null" {
+		if result.Error != nil {
+			return nil, fmt.Errorf("export failed: %s: %s", result.Error.Code, result.Error.Message)
+		}
+		return nil, fmt.Errorf("export returned no template")
+	}
+
+	return &result, nil
+}
+
+// exportDirName makes a resource group name safe to use as a directory name
+func exportDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// writeJSONFile writes v as indented JSON
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
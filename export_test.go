@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExportResourceGroups(t *testing.T) {
+	var mu sync.Mutex
+	locationPolls := 0
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := req.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/sandbox-alice/exportTemplate"):
+			if req.Method != "POST" {
+				t.Errorf("Expected POST for exportTemplate, got %s", req.Method)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if !strings.Contains(string(body), `"resources":["*"]`) {
+				t.Errorf("Expected export of all resources, got %s", body)
+			}
+			return jsonResponse(http.StatusOK, `{"template": {"$schema": "alice"}}`), nil
+		case strings.HasSuffix(path, "/sandbox-bob/exportTemplate"):
+			resp := jsonResponse(http.StatusAccepted, "")
+			resp.Header.Set("Location", "https://management.azure.com/operationresults/export-bob")
+			return resp, nil
+		case strings.HasSuffix(path, "/operationresults/export-bob"):
+			locationPolls++
+			if locationPolls < 2 {
+				return jsonResponse(http.StatusAccepted, ""), nil
+			}
+			return jsonResponse(http.StatusOK, `{"template": {"$schema": "bob"}, "error": {"code": "ExportTemplateCompletedWithErrors", "message": "1 resource skipped"}}`), nil
+		case strings.HasSuffix(path, "/resources"):
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-alice/providers/Microsoft.Web/sites/app", "name": "app", "type": "Microsoft.Web/sites"}]}`), nil
+		case strings.Contains(path, "resourcegroups"):
+			return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(http.StatusBadRequest, ""), nil
+	})
+
+	archive := t.TempDir()
+	outcomes, err := client.ExportResourceGroups(ExportOptions{
+		GroupSelection: GroupSelection{Groups: []string{"SANDBOX-ALICE", "sandbox-bob"}},
+		ArchiveDir:     archive,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("Expected 2 outcomes, got %d", len(outcomes))
+	}
+
+	for _, outcome := range outcomes {
+		if outcome.Status != exportStatusExported {
+			t.Fatalf("Expected %s to be exported, got %s (%s)", outcome.ResourceGroup, outcome.Status, outcome.Error)
+		}
+		for _, name := range []string{"template.json", "resources.json", "metadata.json"} {
+			if _, err := os.Stat(filepath.Join(outcome.Directory, name)); err != nil {
+				t.Errorf("Expected %s for %s: %v", name, outcome.ResourceGroup, err)
+			}
+		}
+	}
+
+	if outcomes[1].Warning == "" {
+		t.Error("Expected partial export warning for sandbox-bob")
+	}
+
+	template, err := os.ReadFile(filepath.Join(outcomes[1].Directory, "template.json"))
+	if err != nil {
+		t.Fatalf("failed to read template: %v", err)
+	}
+	if !strings.Contains(string(template), `"bob"`) {
+		t.Errorf("Expected template from polled Location, got %s", template)
+	}
+
+	var metadata exportMetadata
+	data, err := os.ReadFile(filepath.Join(outcomes[0].Directory, "metadata.json"))
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("failed to parse metadata: %v", err)
+	}
+	if metadata.ResourceGroup.Name != "sandbox-alice" || metadata.ResourceCount != 1 {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+}
+
+func TestExportResourceGroupsFromPlan(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/exportTemplate"):
+			return jsonResponse(http.StatusInternalServerError, `{"error": {"code": "InternalServerError"}}`), nil
+		case strings.Contains(req.URL.Path, "resourcegroups"):
+			return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	plan := &CleanupPlan{SubscriptionID: "test-subscription", Groups: []CleanupPlanEntry{{Name: "prod-app"}}}
+	if err := writeCleanupPlan(planPath, plan); err != nil {
+		t.Fatalf("failed to write plan: %v", err)
+	}
+
+	outcomes, err := client.ExportResourceGroups(ExportOptions{GroupSelection: GroupSelection{PlanFile: planPath}, ArchiveDir: t.TempDir()})
+	if err == nil {
+		t.Fatal("Expected error when an export fails")
+	}
+	if len(outcomes) != 1 || outcomes[0].ResourceGroup != "prod-app" || outcomes[0].Status != exportStatusFailed {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+
+	if _, err := client.ExportResourceGroups(ExportOptions{ArchiveDir: t.TempDir()}); err == nil {
+		t.Error("Expected error when no selection is given")
+	}
+}
+
+func TestExportPollsLocationAfterStatusSucceeds(t *testing.T) {
+	var mu sync.Mutex
+	locationPolls := 0
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := req.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/exportTemplate"):
+			name := strings.Split(path, "/")[4]
+			resp := jsonResponse(http.StatusAccepted, "")
+			resp.Header.Set("Azure-AsyncOperation", "https://management.azure.com/operations/"+name)
+			resp.Header.Set("Location", "https://management.azure.com/operationresults/"+name)
+			return resp, nil
+		case strings.Contains(path, "/operations/"):
+			return jsonResponse(http.StatusOK, `{"status":"Succeeded"}`), nil
+		case strings.HasSuffix(path, "/operationresults/sandbox-alice"):
+			locationPolls++
+			if locationPolls < 3 {
+				return jsonResponse(http.StatusAccepted, ""), nil
+			}
+			return jsonResponse(http.StatusOK, `{"template": {"$schema": "alice"}}`), nil
+		case strings.HasSuffix(path, "/operationresults/sandbox-bob"):
+			return jsonResponse(http.StatusOK, ""), nil
+		case strings.HasSuffix(path, "/resources"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		case strings.Contains(path, "resourcegroups"):
+			return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(http.StatusBadRequest, ""), nil
+	})
+
+	outcomes, err := client.ExportResourceGroups(ExportOptions{
+		GroupSelection: GroupSelection{Groups: []string{"sandbox-alice", "sandbox-bob"}},
+		ArchiveDir:     t.TempDir(),
+	})
+	if err == nil {
+		t.Fatal("Expected an error reporting the empty export")
+	}
+	if len(outcomes) != 2 || outcomes[0].Status != exportStatusExported || locationPolls != 3 {
+		t.Fatalf("Expected alice exported after polling the Location, got %+v (%d polls)", outcomes, locationPolls)
+	}
+	if outcomes[1].Status != exportStatusFailed || !strings.Contains(outcomes[1].Error, "no template") {
+		t.Errorf("Expected bob to fail with an empty template, got %+v", outcomes[1])
+	}
+}
+
+func TestExportDirName(t *testing.T) {
+	if got := exportDirName(`odd:name*with?chars`); got != "odd_name_with_chars" {
+		t.Errorf("unexpected directory name %q", got)
+	}
+	if got := exportDirName("my-rg (legacy)"); got != "my-rg (legacy)" {
+		t.Errorf("Expected valid characters to be kept, got %q", got)
+	}
+}
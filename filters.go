@@ -0,0 +1,428 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// FilterOptions holds the raw inventory filter flags
+type FilterOptions struct {
+	Include            []string // glob patterns, case-insensitive
+	Exclude            []string
+	IncludeRegex       []string
+	ExcludeRegex       []string
+	Locations          []string
+	ProvisioningStates []string
+	CreatedBefore      string
+	CreatedAfter       string
+	OlderThan          string
+	DefaultsOnly       bool
+	ExcludeDefaults    bool
+	TagSelectors       []string
+	TagIgnoreCase      bool
+	MinCost            string // cost bounds for the --cost period, inclusive
+	MaxCost            string
+	NonCompliantOnly   bool
+}
+
+// addFilterFlags registers the inventory filter flags; plan-cleanup shares them with the root command
+func addFilterFlags(flags *pflag.FlagSet) {
+	flags.StringSlice("include", nil, "Only include resource groups whose name matches a glob pattern (case-insensitive, repeatable)")
+	flags.StringSlice("exclude", nil, "Exclude resource groups whose name matches a glob pattern (case-insensitive, repeatable)")
+	flags.StringSlice("include-regex", nil, "Only include resource groups whose name matches a regular expression (repeatable)")
+	flags.StringSlice("exclude-regex", nil, "Exclude resource groups whose name matches a regular expression (repeatable)")
+	flags.StringSlice("location", nil, "Only include resource groups in these locations (repeatable or comma-separated)")
+	flags.StringSlice("provisioning-state", nil, "Only include resource groups in these provisioning states (e.g. Succeeded, Failed)")
+	flags.String("created-before", "", "Only include resource groups created before this time (RFC3339 or YYYY-MM-DD)")
+	flags.String("created-after", "", "Only include resource groups created after this time (RFC3339 or YYYY-MM-DD)")
+	flags.String("older-than", "", "Only include resource groups older than this age (e.g. 90d, 2w, 36h)")
+	flags.Bool("defaults-only", false, "Only include Azure-created default resource groups")
+	flags.Bool("exclude-defaults", false, "Exclude Azure-created default resource groups")
+	flags.StringArray("tag", nil, "Tag selector, e.g. 'owner', '!owner', 'env=dev', 'env in (dev,test)' (repeatable; all must match)")
+	flags.Bool("tag-ignore-case", false, "Match tag keys case-insensitively in tag selectors and the missing tags report")
+	flags.Bool("non-compliant-only", false, "Only include resource groups with non-compliant resources (implies --policy-compliance)")
+	flags.String("min-cost", "", "Only include resource groups costing at least this amount in the cost period (implies --cost)")
+	flags.String("max-cost", "", "Only include resource groups costing at most this amount in the cost period (implies --cost)")
+}
+
+// filterFlagNames are the inventory filter flags registered by addFilterFlags
+var filterFlagNames = []string{
+	"include", "exclude", "include-regex", "exclude-regex", "location", "provisioning-state",
+	"created-before", "created-after", "older-than", "defaults-only", "exclude-defaults",
+	"tag", "tag-ignore-case", "min-cost", "max-cost", "non-compliant-only",
+}
+
+// bindFilterFlags binds the filter flags under "filters." so a config file can set them in a
+// filters section. The prefix keeps generic names such as "location" from being picked up from
+// the environment.
+func bindFilterFlags(v *viper.Viper, flags *pflag.FlagSet) error {
+	for _, name := range filterFlagNames {
+		if err := v.BindPFlag("filters."+name, flags.Lookup(name)); err != nil {
+			return fmt.Errorf("failed to bind %s flag: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// filterOptionsFromConfig reads the inventory filters; flags take precedence over the config file
+func filterOptionsFromConfig(v *viper.Viper) FilterOptions {
+	return FilterOptions{
+		Include:            v.GetStringSlice("filters.include"),
+		Exclude:            v.GetStringSlice("filters.exclude"),
+		IncludeRegex:       v.GetStringSlice("filters.include-regex"),
+		ExcludeRegex:       v.GetStringSlice("filters.exclude-regex"),
+		Locations:          v.GetStringSlice("filters.location"),
+		ProvisioningStates: v.GetStringSlice("filters.provisioning-state"),
+		CreatedBefore:      v.GetString("filters.created-before"),
+		CreatedAfter:       v.GetString("filters.created-after"),
+		OlderThan:          v.GetString("filters.older-than"),
+		DefaultsOnly:       v.GetBool("filters.defaults-only"),
+		ExcludeDefaults:    v.GetBool("filters.exclude-defaults"),
+		TagSelectors:       v.GetStringSlice("filters.tag"),
+		TagIgnoreCase:      v.GetBool("filters.tag-ignore-case"),
+		MinCost:            v.GetString("filters.min-cost"),
+		MaxCost:            v.GetString("filters.max-cost"),
+		NonCompliantOnly:   v.GetBool("filters.non-compliant-only"),
+	}
+}
+
+// ResourceGroupFilter is the compiled form of FilterOptions. Name, location, default and
+// provisioning state checks only need the resource group listing and are applied before
+// any per-group API calls; creation time checks are applied once the creation time is known.
+// Cost checks are applied after the subscription's cost query, also before per-group calls;
+// the policy compliance check is applied once the group's policy states are summarized.
+type ResourceGroupFilter struct {
+	include            []string
+	exclude            []string
+	includeRegex       []*regexp.Regexp
+	excludeRegex       []*regexp.Regexp
+	locations          map[string]bool
+	provisioningStates map[string]bool
+	createdBefore      *time.Time
+	createdAfter       *time.Time
+	defaultsOnly       bool
+	excludeDefaults    bool
+	tagSelectors       []*TagSelector
+	minCost            *float64
+	maxCost            *float64
+	nonCompliantOnly   bool
+}
+
+// NewResourceGroupFilter compiles the filter options; now is the reference time for --older-than.
+// It returns nil when no filter is set.
+func NewResourceGroupFilter(opts FilterOptions, now time.Time) (*ResourceGroupFilter, error) {
+	if opts.DefaultsOnly && opts.ExcludeDefaults {
+		return nil, fmt.Errorf("--defaults-only and --exclude-defaults cannot be used together")
+	}
+
+	f := &ResourceGroupFilter{
+		defaultsOnly:     opts.DefaultsOnly,
+		excludeDefaults:  opts.ExcludeDefaults,
+		nonCompliantOnly: opts.NonCompliantOnly,
+	}
+
+	var err error
+	if f.include, err = compileGlobs(opts.Include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compileGlobs(opts.Exclude); err != nil {
+		return nil, err
+	}
+	if f.includeRegex, err = compileRegexes(opts.IncludeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRegex, err = compileRegexes(opts.ExcludeRegex); err != nil {
+		return nil, err
+	}
+	f.locations = normalizedSet(opts.Locations, normalizeLocation)
+	f.provisioningStates = normalizedSet(opts.ProvisioningStates, strings.ToLower)
+
+	for _, expr := range opts.TagSelectors {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		selector, err := ParseTagSelector(expr, opts.TagIgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		f.tagSelectors = append(f.tagSelectors, selector)
+	}
+
+	if opts.CreatedBefore != "" {
+		t, err := parseFilterTime(opts.CreatedBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --created-before: %w", err)
+		}
+		f.createdBefore = &t
+	}
+	if opts.CreatedAfter != "" {
+		t, err := parseFilterTime(opts.CreatedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --created-after: %w", err)
+		}
+		f.createdAfter = &t
+	}
+	if opts.OlderThan != "" {
+		age, err := parseAge(opts.OlderThan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --older-than: %w", err)
+		}
+		cutoff := now.Add(-age)
+		// --older-than and --created-before both bound the creation time from above; keep the stricter one
+		if f.createdBefore == nil || cutoff.Before(*f.createdBefore) {
+			f.createdBefore = &cutoff
+		}
+	}
+
+	if f.minCost, err = parseCostBound(opts.MinCost); err != nil {
+		return nil, fmt.Errorf("invalid --min-cost: %w", err)
+	}
+	if f.maxCost, err = parseCostBound(opts.MaxCost); err != nil {
+		return nil, fmt.Errorf("invalid --max-cost: %w", err)
+	}
+
+	if !f.active() {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func (f *ResourceGroupFilter) active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0 || len(f.includeRegex) > 0 || len(f.excludeRegex) > 0 ||
+		len(f.locations) > 0 || len(f.provisioningStates) > 0 || f.defaultsOnly || f.excludeDefaults ||
+		len(f.tagSelectors) > 0 || f.NeedsCreatedTime() || f.NeedsCost() || f.NeedsPolicyCompliance()
+}
+
+// NeedsCreatedTime reports whether the filter has creation time conditions
+func (f *ResourceGroupFilter) NeedsCreatedTime() bool {
+	return f != nil && (f.createdBefore != nil || f.createdAfter != nil)
+}
+
+// MatchGroup applies the checks that only need the resource group listing
+func (f *ResourceGroupFilter) MatchGroup(rg ResourceGroup) bool {
+	if f == nil {
+		return true
+	}
+
+	name := strings.ToLower(rg.Name)
+	if len(f.include) > 0 || len(f.includeRegex) > 0 {
+		if !matchesAnyGlob(f.include, name) && !matchesAnyRegex(f.includeRegex, rg.Name) {
+			return false
+		}
+	}
+	if matchesAnyGlob(f.exclude, name) || matchesAnyRegex(f.excludeRegex, rg.Name) {
+		return false
+	}
+
+	if len(f.locations) > 0 && !f.locations[normalizeLocation(rg.Location)] {
+		return false
+	}
+	if len(f.provisioningStates) > 0 && !f.provisioningStates[strings.ToLower(rg.Properties.ProvisioningState)] {
+		return false
+	}
+
+	if f.defaultsOnly || f.excludeDefaults {
+		isDefault := checkIfDefaultResourceGroup(rg.Name).IsDefault
+		if f.defaultsOnly && !isDefault || f.excludeDefaults && isDefault {
+			return false
+		}
+	}
+
+	for _, selector := range f.tagSelectors {
+		if !selector.Matches(rg.Tags) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TagKeys returns the tag keys referenced by the tag selectors
+func (f *ResourceGroupFilter) TagKeys() []string {
+	if f == nil {
+		return nil
+	}
+	var keys []string
+	for _, selector := range f.tagSelectors {
+		keys = append(keys, selector.Keys()...)
+	}
+	return keys
+}
+
+// MatchCreatedTime applies the creation time checks. Groups whose creation time is
+// unknown cannot satisfy a creation time condition; callers keep the groups whose lookup
+// failed so the error is reported rather than filtered away.
+func (f *ResourceGroupFilter) MatchCreatedTime(createdTime *time.Time) bool {
+	if !f.NeedsCreatedTime() {
+		return true
+	}
+	if createdTime == nil {
+		return false
+	}
+	if f.createdBefore != nil && !createdTime.Before(*f.createdBefore) {
+		return false
+	}
+	if f.createdAfter != nil && !createdTime.After(*f.createdAfter) {
+		return false
+	}
+	return true
+}
+
+// NeedsCost reports whether the filter has cost conditions
+func (f *ResourceGroupFilter) NeedsCost() bool {
+	return f != nil && (f.minCost != nil || f.maxCost != nil)
+}
+
+// MatchCost applies the cost bounds to a group's cost for the period
+func (f *ResourceGroupFilter) MatchCost(amount float64) bool {
+	if !f.NeedsCost() {
+		return true
+	}
+	if f.minCost != nil && amount < *f.minCost {
+		return false
+	}
+	if f.maxCost != nil && amount > *f.maxCost {
+		return false
+	}
+	return true
+}
+
+// NeedsPolicyCompliance reports whether only non-compliant groups are wanted
+func (f *ResourceGroupFilter) NeedsPolicyCompliance() bool {
+	return f != nil && f.nonCompliantOnly
+}
+
+// MatchPolicyCompliance applies --non-compliant-only. Groups without a compliance summary
+// cannot satisfy it; callers keep the groups whose lookup failed so the error is reported
+// rather than filtered away.
+func (f *ResourceGroupFilter) MatchPolicyCompliance(summary *PolicySummary) bool {
+	return !f.NeedsPolicyCompliance() || summary.NonCompliant()
+}
+
+// FilterGroups returns the resource groups that pass MatchGroup
+func (f *ResourceGroupFilter) FilterGroups(resourceGroups []ResourceGroup) []ResourceGroup {
+	if f == nil {
+		return resourceGroups
+	}
+	filtered := make([]ResourceGroup, 0, len(resourceGroups))
+	for _, rg := range resourceGroups {
+		if f.MatchGroup(rg) {
+			filtered = append(filtered, rg)
+		}
+	}
+	return filtered
+}
+
+func compileGlobs(patterns []string) ([]string, error) {
+	globs := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, pattern)
+	}
+	return globs, nil
+}
+
+func compileRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchesAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyRegex(patterns []*regexp.Regexp, name string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizedSet(values []string, normalize func(string) string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value = normalize(strings.TrimSpace(value)); value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// normalizeLocation lets display names match location names, e.g. "East US" matches "eastus"
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// parseFilterTime accepts RFC3339 timestamps or plain dates (YYYY-MM-DD, taken as UTC midnight)
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 time or YYYY-MM-DD date, got %q", value)
+	}
+	return t, nil
+}
+
+// parseCostBound parses an optional non-negative amount; empty means no bound
+func parseCostBound(value string) (*float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || amount < 0 {
+		return nil, fmt.Errorf("expected a non-negative amount, got %q", value)
+	}
+	return &amount, nil
+}
+
+// parseAge parses an age such as "90d", "2w" or any Go duration ("36h")
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 90d, 2w or 36h)", value)
+	}
+	return age, nil
+}
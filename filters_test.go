@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestResourceGroupFilterMatchGroup(t *testing.T) {
+	newGroup := func(name, location, state string) ResourceGroup {
+		rg := ResourceGroup{Name: name, Location: location}
+		rg.Properties.ProvisioningState = state
+		return rg
+	}
+
+	testCases := []struct {
+		name     string
+		opts     FilterOptions
+		rg       ResourceGroup
+		expected bool
+	}{
+		{"include glob is case-insensitive", FilterOptions{Include: []string{"sandbox-*"}}, newGroup("Sandbox-Alice", "eastus", "Succeeded"), true},
+		{"include glob miss", FilterOptions{Include: []string{"sandbox-*"}}, newGroup("prod-app", "eastus", "Succeeded"), false},
+		{"include regex", FilterOptions{IncludeRegex: []string{"^prod-"}}, newGroup("prod-app", "eastus", "Succeeded"), true},
+		{"include glob or regex", FilterOptions{Include: []string{"sandbox-*"}, IncludeRegex: []string{"^prod-"}}, newGroup("prod-app", "eastus", "Succeeded"), true},
+		{"exclude wins over include", FilterOptions{Include: []string{"*"}, Exclude: []string{"*-bob"}}, newGroup("sandbox-bob", "eastus", "Succeeded"), false},
+		{"exclude regex", FilterOptions{ExcludeRegex: []string{"(?i)^networkwatcher"}}, newGroup("NetworkWatcherRG", "eastus", "Succeeded"), false},
+		{"location display name", FilterOptions{Locations: []string{"East US"}}, newGroup("rg", "eastus", "Succeeded"), true},
+		{"location miss", FilterOptions{Locations: []string{"westus"}}, newGroup("rg", "eastus", "Succeeded"), false},
+		{"provisioning state", FilterOptions{ProvisioningStates: []string{"failed"}}, newGroup("rg", "eastus", "Failed"), true},
+		{"provisioning state miss", FilterOptions{ProvisioningStates: []string{"failed"}}, newGroup("rg", "eastus", "Succeeded"), false},
+		{"defaults only", FilterOptions{DefaultsOnly: true}, newGroup("NetworkWatcherRG", "eastus", "Succeeded"), true},
+		{"defaults only miss", FilterOptions{DefaultsOnly: true}, newGroup("prod-app", "eastus", "Succeeded"), false},
+		{"exclude defaults", FilterOptions{ExcludeDefaults: true}, newGroup("NetworkWatcherRG", "eastus", "Succeeded"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := NewResourceGroupFilter(tc.opts, time.Now())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := filter.MatchGroup(tc.rg); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResourceGroupFilterCreatedTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	filter, err := NewResourceGroupFilter(FilterOptions{OlderThan: "90d"}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !filter.MatchCreatedTime(&old) || filter.MatchCreatedTime(&recent) {
+		t.Error("Expected --older-than 90d to keep only the old group")
+	}
+	if filter.MatchCreatedTime(nil) {
+		t.Error("Expected unknown creation time not to match a creation time filter")
+	}
+
+	filter, err = NewResourceGroupFilter(FilterOptions{CreatedAfter: "2024-01-01", CreatedBefore: "2024-06-01T00:00:00Z"}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filter.MatchCreatedTime(&old) || !filter.MatchCreatedTime(&recent) {
+		t.Error("Expected the created-after/created-before window to keep only the recent group")
+	}
+
+	var none *ResourceGroupFilter
+	if !none.MatchCreatedTime(nil) || !none.MatchGroup(ResourceGroup{Name: "any"}) {
+		t.Error("Expected a nil filter to match everything")
+	}
+}
+
+func TestNewResourceGroupFilterErrors(t *testing.T) {
+	invalid := []FilterOptions{
+		{DefaultsOnly: true, ExcludeDefaults: true},
+		{Include: []string{"[abc"}},
+		{ExcludeRegex: []string{"("}},
+		{CreatedBefore: "last tuesday"},
+		{OlderThan: "ninety days"},
+		{OlderThan: "-5d"},
+	}
+	for _, opts := range invalid {
+		if _, err := NewResourceGroupFilter(opts, time.Now()); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+
+	filter, err := NewResourceGroupFilter(FilterOptions{}, time.Now())
+	if err != nil || filter != nil {
+		t.Errorf("Expected nil filter without error for empty options, got %v, %v", filter, err)
+	}
+}
+
+func TestFilterOptionsFromConfig(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringSlice("include", nil, "")
+	flags.StringSlice("exclude", nil, "")
+	flags.StringSlice("include-regex", nil, "")
+	flags.StringSlice("exclude-regex", nil, "")
+	flags.StringSlice("location", nil, "")
+	flags.StringSlice("provisioning-state", nil, "")
+	flags.String("created-before", "", "")
+	flags.String("created-after", "", "")
+	flags.String("older-than", "", "")
+	flags.Bool("defaults-only", false, "")
+	flags.Bool("exclude-defaults", false, "")
+	flags.StringArray("tag", nil, "")
+	flags.Bool("tag-ignore-case", false, "")
+	flags.String("min-cost", "", "")
+	flags.String("max-cost", "", "")
+	flags.Bool("non-compliant-only", false, "")
+
+	v := viper.New()
+	if err := bindFilterFlags(v, flags); err != nil {
+		t.Fatalf("failed to bind filter flags: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `filters:
+  location: [eastus, westus]
+  older-than: 90d
+  exclude-defaults: true
+  tag: ["env in (dev,test)", "owner"]
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	// Flags given on the command line take precedence over the config file
+	if err := flags.Parse([]string{"--older-than", "30d", "--tag", "team=a,b"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	opts := filterOptionsFromConfig(v)
+	if strings.Join(opts.Locations, ",") != "eastus,westus" || !opts.ExcludeDefaults {
+		t.Errorf("expected filters from the config file, got %+v", opts)
+	}
+	if opts.OlderThan != "30d" || len(opts.TagSelectors) != 1 || opts.TagSelectors[0] != "team=a,b" {
+		t.Errorf("expected command-line filters to win, got %+v", opts)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	}
+	for input, expected := range testCases {
+		got, err := parseAge(input)
+		if err != nil || got != expected {
+			t.Errorf("parseAge(%q) = %v, %v; expected %v", input, got, err, expected)
+		}
+	}
+}
+
+func TestFetchResourceGroupsWithFilters(t *testing.T) {
+	var mu sync.Mutex
+	resourceRequests := make(map[string]bool)
+
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			path := req.URL.Path
+			if !strings.HasSuffix(path, "/resources") {
+				return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+			}
+
+			group := strings.Split(path, "/")[4]
+			mu.Lock()
+			resourceRequests[group] = true
+			mu.Unlock()
+
+			createdTime := "2024-05-30T00:00:00Z"
+			if group == "sandbox-alice" {
+				createdTime = "2020-01-01T00:00:00Z"
+			}
+			return jsonResponse(http.StatusOK, `{"value": [{"name": "res", "type": "Microsoft.Web/sites", "createdTime": "`+createdTime+`"}]}`), nil
+		},
+	}
+
+	filter, err := NewResourceGroupFilter(FilterOptions{
+		Locations:       []string{"eastus"},
+		ExcludeDefaults: true,
+		OlderThan:       "90d",
+	}, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to build filter: %v", err)
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "out.csv")
+	client := &AzureClient{
+		Config: Config{
+			SubscriptionID: "test-subscription",
+			AccessToken:    "test-token",
+			MaxConcurrency: 2,
+			Porcelain:      true,
+			OutputCSV:      csvPath,
+		},
+		HTTPClient: mockClient,
+		Filter:     filter,
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	err = client.FetchResourceGroups()
+
+	if closeErr := w.Close(); closeErr != nil {
+		t.Errorf("Failed to close pipe writer: %v", closeErr)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Errorf("Failed to copy output: %v", copyErr)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Location and default filters are applied before any per-group request
+	if resourceRequests["sandbox-bob"] || resourceRequests["NetworkWatcherRG"] {
+		t.Errorf("Expected no resource requests for pre-filtered groups, got %v", resourceRequests)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "sandbox-alice\t") {
+		t.Errorf("Expected sandbox-alice in porcelain output, got:\n%s", output)
+	}
+	if strings.Contains(output, "prod-app") {
+		t.Errorf("Expected recently created prod-app to be filtered out, got:\n%s", output)
+	}
+
+	csvContent, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(csvContent)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "sandbox-alice,") {
+		t.Errorf("Expected only sandbox-alice in CSV, got:\n%s", csvContent)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"time"
+)
+
+// HTMLReport is the data rendered into the --output-html report
+type HTMLReport struct {
+	SubscriptionID string
+	GeneratedAt    time.Time
+	GroupCount     int
+	DefaultCount   int
+	ResourceCount  int
+	Errors         []LabelCount // the failed lookups of the text summary
+	LastModified   bool
+	InferredOwners bool
+	Cost           bool
+	Policy         bool
+	TagGaps        bool
+	Locations      []LabelCount
+	Ages           []HTMLBar
+	Groups         []InventoryGroup
+	Resources      []HTMLResourceRow
+}
+
+// HTMLBar is one bar of the age histogram; Percent is relative to the largest bucket
+type HTMLBar struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+// HTMLResourceRow is a resource with the group it belongs to
+type HTMLResourceRow struct {
+	Group string
+	InventoryResource
+}
+
+// writeHTMLReport writes a single self-contained HTML file: all styles, scripts and data are
+// inlined so the report works offline and can be attached to an email as is
+func (ac *AzureClient) writeHTMLReport(path string, results []ResourceGroupResult) error {
+	report := ac.buildHTMLReport(results)
+
+	tmpl, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap(templateFuncs)).
+		Funcs(htmltemplate.FuncMap{"cost": formatCost}).Parse(htmlReportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("Warning: failed to close HTML file: %v", err)
+		}
+	}()
+
+	if err := tmpl.Execute(file, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+func (ac *AzureClient) buildHTMLReport(results []ResourceGroupResult) HTMLReport {
+	report := HTMLReport{
+		SubscriptionID: ac.Config.SubscriptionID,
+		GeneratedAt:    inventoryNow().UTC(),
+		GroupCount:     len(results),
+		LastModified:   ac.Config.LastModified,
+		InferredOwners: ac.Config.RoleAssignments,
+		Cost:           ac.Config.Cost,
+		Policy:         ac.Config.PolicyCompliance,
+		TagGaps:        ac.Config.TagInheritance,
+		Locations:      countBy(results, func(r ResourceGroupResult) string { return r.ResourceGroup.Location }),
+		Groups:         ac.buildInventory(results, true),
+	}
+
+	for _, group := range report.Groups {
+		if group.Detection.IsDefault {
+			report.DefaultCount++
+		}
+		for _, resource := range group.Resources {
+			report.Resources = append(report.Resources, HTMLResourceRow{Group: group.Name, InventoryResource: resource})
+		}
+	}
+	report.ResourceCount = len(report.Resources)
+	report.Errors = buildSummary(results, 0).Errors.rows(ac.Config.CheckLocks)
+
+	ages := countByAge(results)
+	largest := 0
+	for _, age := range ages {
+		if age.Count > largest {
+			largest = age.Count
+		}
+	}
+	for _, age := range ages {
+		bar := HTMLBar{Label: age.Label, Count: age.Count}
+		if largest > 0 {
+			bar.Percent = age.Count * 100 / largest
+		}
+		report.Ages = append(report.Ages, bar)
+	}
+	return report
+}
+
+// htmlReportTemplate is the report page. Tables are sorted by clicking a header (cells with a
+// data-sort attribute sort numerically) and filtered by the text box above them. The inventory
+// is embedded as JSON in the inventory-data script element for anyone who wants the raw data.
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Resource group inventory - {{.SubscriptionID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+.meta { color: #59636e; margin-top: 0; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; margin: 1.5em 0; }
+.card { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.8em 1.2em; min-width: 9em; }
+.card .value { font-size: 1.8em; font-weight: 600; }
+.card .label { color: #59636e; }
+.panels { display: flex; flex-wrap: wrap; gap: 3em; }
+.histogram td { padding: 0.2em 0.5em; border: none; }
+.bar { background: #0969da; height: 1em; border-radius: 3px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #d1d9e0; padding: 0.35em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; white-space: nowrap; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #fbfcfd; }
+.badge { display: inline-block; background: #ddf4ff; color: #0550ae; border-radius: 1em; padding: 0 0.6em; font-size: 0.85em; }
+.error { color: #cf222e; }
+.tags { color: #59636e; font-size: 0.9em; }
+input.filter { padding: 0.4em; width: 20em; margin-bottom: 0.6em; }
+</style>
+</head>
+<body>
+<h1>Resource group inventory</h1>
+<p class="meta">Subscription {{.SubscriptionID}} &middot; generated {{date "2006-01-02 15:04 UTC" .GeneratedAt}}</p>
+
+<div class="cards">
+<div class="card"><div class="value">{{.GroupCount}}</div><div class="label">Resource groups</div></div>
+<div class="card"><div class="value">{{.DefaultCount}}</div><div class="label">Created by Azure</div></div>
+<div class="card"><div class="value">{{.ResourceCount}}</div><div class="label">Resources</div></div>
+{{range .Errors}}{{if .Count}}<div class="card"><div class="value error">{{.Count}}</div><div class="label">{{.Label}}</div></div>{{end}}{{end}}
+</div>
+
+<div class="panels">
+<div>
+<h2>Age</h2>
+<table class="histogram">
+{{range .Ages}}<tr><td>{{.Label}}</td><td style="width: 20em"><div class="bar" style="width: {{.Percent}}%"></div></td><td>{{.Count}}</td></tr>
+{{end}}</table>
+</div>
+<div>
+<h2>Locations</h2>
+<table class="histogram">
+{{range .Locations}}<tr><td>{{.Label}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+</div>
+</div>
+
+<h2>Resource groups</h2>
+<input class="filter" type="search" placeholder="Filter resource groups" data-table="groups">
+<table id="groups" class="sortable">
+<thead><tr><th>Name</th><th>Location</th><th>State</th><th>Created</th><th>Age (days)</th>{{if .LastModified}}<th>Last modified</th><th>Days since change</th>{{end}}{{if .InferredOwners}}<th>Inferred owners</th>{{end}}{{if .Policy}}<th>Non-compliant resources</th>{{end}}{{if .Cost}}<th>Cost</th>{{end}}{{if .TagGaps}}<th>Resources missing group tags</th>{{end}}<th>Resources</th><th>Tags</th></tr></thead>
+<tbody>
+{{range .Groups}}<tr>
+<td>{{.Name}}{{if .Detection.IsDefault}} <span class="badge" title="{{.Detection.Description}}">{{.Detection.CreatedBy}}</span>{{end}}{{if .Error}}<div class="error">{{.Error}}</div>{{end}}</td>
+<td>{{.Location}}</td>
+<td>{{.ProvisioningState}}</td>
+<td>{{date "2006-01-02" .CreatedTime}}</td>
+<td data-sort="{{ageDays .CreatedTime}}">{{if .CreatedTime}}{{ageDays .CreatedTime}}{{end}}</td>
+{{if $.LastModified}}<td>{{date "2006-01-02" .LastModified}}</td>
+<td data-sort="{{with .DaysSinceChange}}{{.}}{{else}}-1{{end}}">{{with .DaysSinceChange}}{{.}}{{end}}</td>
+{{end}}{{if $.InferredOwners}}<td>{{join "; " .InferredOwners}}</td>
+{{end}}{{if $.Policy}}<td data-sort="{{with .PolicyCompliance}}{{.NonCompliantResources}}{{else}}-1{{end}}">{{with .PolicyCompliance}}{{.NonCompliantResources}}{{end}}</td>
+{{end}}{{if $.Cost}}<td data-sort="{{with .Cost}}{{.}}{{else}}-1{{end}}">{{with .Cost}}{{cost .}}{{end}} {{.CostCurrency}}</td>
+{{end}}{{if $.TagGaps}}<td data-sort="{{with .TagGapResources}}{{.}}{{else}}-1{{end}}">{{with .TagGapResources}}{{.}}{{end}}</td>
+{{end}}<td data-sort="{{with .ResourceCount}}{{.}}{{else}}-1{{end}}">{{with .ResourceCount}}{{.}}{{end}}</td>
+<td class="tags">{{$tags := .Tags}}{{range $key := keys $tags}}{{$key}}={{index $tags $key}} {{end}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+
+<h2>Resources</h2>
+<input class="filter" type="search" placeholder="Filter resources" data-table="resources">
+<table id="resources" class="sortable">
+<thead><tr><th>Resource group</th><th>Name</th><th>Type</th><th>Created</th></tr></thead>
+<tbody>
+{{range .Resources}}<tr><td>{{.Group}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{date "2006-01-02 15:04" .CreatedTime}}</td></tr>
+{{end}}</tbody>
+</table>
+
+<script type="application/json" id="inventory-data">{{.Groups}}</script>
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var ascending = !th.classList.contains("asc");
+      table.querySelectorAll("th").forEach(function (other) { other.classList.remove("asc", "desc"); });
+      th.classList.add(ascending ? "asc" : "desc");
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column], y = b.cells[column];
+        var result = x.hasAttribute("data-sort")
+          ? Number(x.getAttribute("data-sort")) - Number(y.getAttribute("data-sort"))
+          : x.textContent.localeCompare(y.textContent);
+        return ascending ? result : -result;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+document.querySelectorAll("input.filter").forEach(function (input) {
+  input.addEventListener("input", function () {
+    var text = input.value.toLowerCase();
+    var rows = document.getElementById(input.getAttribute("data-table")).tBodies[0].rows;
+    Array.prototype.forEach.call(rows, function (row) {
+      row.style.display = row.textContent.toLowerCase().indexOf(text) === -1 ? "none" : "";
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	results := outputTestResults()
+	results[0].ResourceGroup.Tags["note"] = `<script>alert("x")</script>`
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	client := &AzureClient{Config: Config{SubscriptionID: "test-subscription"}}
+	if err := client.writeHTMLReport(path, results); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	report := string(content)
+
+	for _, want := range []string{
+		"Subscription test-subscription",
+		`<div class="value">2</div><div class="label">Resource groups</div>`,
+		`<span class="badge" title="Created by Azure Network Watcher service for network monitoring">Azure Network Watcher</span>`,
+		`<td data-sort="59">59</td>`,
+		"<td>sandbox-alice</td><td>db/main</td>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in report", want)
+		}
+	}
+
+	// Tag values are escaped and the report references no external assets
+	if strings.Contains(report, `<script>alert("x")</script>`) {
+		t.Error("Expected tag values to be escaped")
+	}
+	if regexp.MustCompile(`(src|href)=`).MatchString(report) {
+		t.Error("Expected no external assets in the report")
+	}
+
+	// The embedded data is the structured inventory
+	match := regexp.MustCompile(`(?s)<script type="application/json" id="inventory-data">(.*?)</script>`).FindStringSubmatch(report)
+	if match == nil {
+		t.Fatal("Expected embedded inventory data")
+	}
+	var groups []InventoryGroup
+	if err := json.Unmarshal([]byte(match[1]), &groups); err != nil {
+		t.Fatalf("Expected embedded JSON, got %v:\n%s", err, match[1])
+	}
+	if len(groups) != 2 || groups[0].Name != "sandbox-alice" || len(groups[0].Resources) != 1 {
+		t.Errorf("unexpected embedded inventory %+v", groups)
+	}
+}
+
+func TestBuildHTMLReportHistogram(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	report := (&AzureClient{}).buildHTMLReport(outputTestResults())
+	if report.DefaultCount != 1 || report.ResourceCount != 1 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	for _, bar := range report.Ages {
+		expected := 0
+		if bar.Label == ageBucket30To90Days || bar.Label == ageBucketUnknown {
+			expected = 100
+		}
+		if bar.Percent != expected {
+			t.Errorf("bar %s: expected %d%%, got %d%%", bar.Label, expected, bar.Percent)
+		}
+	}
+}
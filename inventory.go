@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// inventoryNow is the reference time for ages; tests replace it
+var inventoryNow = time.Now
+
+// InventoryGroup is the structured form of a resource group result. It is what the JSON, YAML
+// and table renderers print and what --query expressions are evaluated against.
+type InventoryGroup struct {
+	Name              string              `json:"name"`
+	ID                string              `json:"id"`
+	Location          string              `json:"location"`
+	ProvisioningState string              `json:"provisioningState"`
+	CreatedTime       *time.Time          `json:"createdTime"`
+	CreatedTimeSource string              `json:"createdTimeSource,omitempty"`
+	Creator           string              `json:"creator,omitempty"`
+	LastModified      *time.Time          `json:"lastModified,omitempty"`
+	DaysSinceChange   *int                `json:"daysSinceChange,omitempty"`
+	Tags              map[string]string   `json:"tags"`
+	Detection         InventoryDetection  `json:"detection"`
+	Protected         *bool               `json:"protected,omitempty"`
+	ProtectionReason  string              `json:"protectionReason,omitempty"`
+	Locks             []InventoryLock     `json:"locks,omitempty"`
+	RoleAssignments   []InventoryRole     `json:"roleAssignments,omitempty"`
+	InferredOwners    []string            `json:"inferredOwners,omitempty"`
+	PolicyCompliance  *PolicySummary      `json:"policyCompliance,omitempty"`
+	Cost              *float64            `json:"cost,omitempty"`
+	CostCurrency      string              `json:"costCurrency,omitempty"`
+	TagGapResources   *int                `json:"tagGapResources,omitempty"`
+	ResourceCount     *int                `json:"resourceCount,omitempty"`
+	Resources         []InventoryResource `json:"resources,omitempty"`
+	Error             string              `json:"error,omitempty"`
+}
+
+// InventoryDetection is the default resource group detection result
+type InventoryDetection struct {
+	IsDefault   bool   `json:"isDefault"`
+	CreatedBy   string `json:"createdBy,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// InventoryLock is a management lock in the structured inventory
+type InventoryLock struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// InventoryRole is an ownership role assignment in the structured inventory
+type InventoryRole struct {
+	Role              string `json:"role"`
+	PrincipalID       string `json:"principalId"`
+	PrincipalType     string `json:"principalType,omitempty"`
+	DisplayName       string `json:"displayName,omitempty"`
+	UserPrincipalName string `json:"userPrincipalName,omitempty"`
+}
+
+// InventoryResource is a resource nested under its group in the structured inventory
+type InventoryResource struct {
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider,omitempty"`
+	Parent      string     `json:"parent,omitempty"`
+	CreatedTime *time.Time `json:"createdTime"`
+	ChangedTime *time.Time `json:"changedTime,omitempty"`
+	TagGaps     []TagGap   `json:"tagGaps,omitempty"`
+}
+
+// buildInventory converts results to the structured inventory. Optional sections (protection,
+// locks, resources) are only present when the corresponding feature is enabled.
+func (ac *AzureClient) buildInventory(results []ResourceGroupResult, listResources bool) []InventoryGroup {
+	inventory := make([]InventoryGroup, 0, len(results))
+	for _, result := range results {
+		rg := result.ResourceGroup
+		defaultInfo := checkIfDefaultResourceGroup(rg.Name)
+
+		group := InventoryGroup{
+			Name:              rg.Name,
+			ID:                rg.ID,
+			Location:          rg.Location,
+			ProvisioningState: rg.Properties.ProvisioningState,
+			CreatedTime:       result.CreatedTime,
+			CreatedTimeSource: result.CreatedTimeSource,
+			Creator:           result.Creator,
+			LastModified:      result.LastModified,
+			DaysSinceChange:   daysSinceChange(result),
+			CostCurrency:      result.CostCurrency,
+			Tags:              rg.Tags,
+			Detection: InventoryDetection{
+				IsDefault:   defaultInfo.IsDefault,
+				CreatedBy:   defaultInfo.CreatedBy,
+				Description: defaultInfo.Description,
+			},
+		}
+		if group.Tags == nil {
+			group.Tags = map[string]string{}
+		}
+		if result.Error != nil {
+			group.Error = result.Error.Error()
+		}
+		if result.DeploymentsError != nil {
+			group.Error = joinErrors(group.Error, fmt.Sprintf("deployment lookup failed: %v", result.DeploymentsError))
+		}
+		if result.ActivityLogError != nil {
+			group.Error = joinErrors(group.Error, fmt.Sprintf("activity log lookup failed: %v", result.ActivityLogError))
+		}
+
+		if ac.Protection != nil {
+			protected := result.Protected
+			group.Protected = &protected
+			group.ProtectionReason = result.ProtectionReason
+		}
+		if ac.Config.CheckLocks {
+			if result.LocksError != nil {
+				group.Error = joinErrors(group.Error, fmt.Sprintf("lock lookup failed: %v", result.LocksError))
+			}
+			group.Locks = make([]InventoryLock, 0, len(result.Locks))
+			for _, lock := range result.Locks {
+				group.Locks = append(group.Locks, InventoryLock{Name: lock.Name, Level: lock.Properties.Level, Notes: lock.Properties.Notes})
+			}
+		}
+
+		if ac.Config.RoleAssignments {
+			if result.RoleAssignmentsError != nil {
+				group.Error = joinErrors(group.Error, fmt.Sprintf("role assignment lookup failed: %v", result.RoleAssignmentsError))
+			}
+			if result.PrincipalsError != nil {
+				group.Error = joinErrors(group.Error, fmt.Sprintf("principal lookup failed: %v", result.PrincipalsError))
+			}
+			group.RoleAssignments = make([]InventoryRole, 0, len(result.RoleAssignments))
+			for _, assignment := range result.RoleAssignments {
+				group.RoleAssignments = append(group.RoleAssignments, InventoryRole{
+					Role:              assignment.Role,
+					PrincipalID:       assignment.PrincipalID,
+					PrincipalType:     assignment.PrincipalType,
+					DisplayName:       assignment.DisplayName,
+					UserPrincipalName: assignment.UserPrincipalName,
+				})
+			}
+			group.InferredOwners = inferredOwners(result.RoleAssignments)
+		}
+
+		if ac.Config.PolicyCompliance {
+			if result.PolicyError != nil {
+				group.Error = joinErrors(group.Error, fmt.Sprintf("policy lookup failed: %v", result.PolicyError))
+			}
+			group.PolicyCompliance = result.Policy
+		}
+		if result.Cost != nil {
+			cost := roundCost(*result.Cost)
+			group.Cost = &cost
+		}
+
+		gaps := make(map[string][]TagGap)
+		if result.TagInheritance != nil {
+			count := len(result.TagInheritance.Gaps)
+			group.TagGapResources = &count
+			for _, resource := range result.TagInheritance.Gaps {
+				gaps[resource.Resource.ID] = resource.Gaps
+			}
+		}
+
+		if result.Error == nil {
+			count := len(result.Resources)
+			group.ResourceCount = &count
+		}
+		if listResources && result.Error == nil {
+			group.Resources = make([]InventoryResource, 0, len(result.Resources))
+			for _, resource := range result.Resources {
+				group.Resources = append(group.Resources, InventoryResource{
+					Name:        resource.Name,
+					Type:        resource.Type,
+					ID:          resource.ID,
+					Provider:    resource.ProviderNamespace(),
+					Parent:      resource.ParentName(),
+					CreatedTime: resource.CreatedTime,
+					ChangedTime: resource.ChangedTime,
+					TagGaps:     gaps[resource.ID],
+				})
+			}
+		}
+
+		inventory = append(inventory, group)
+	}
+	return inventory
+}
+
+// toGenericJSON converts v to the generic map/slice form produced by encoding/json,
+// which is what JMESPath evaluation and the generic renderers work on
+func toGenericJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode inventory: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory: %w", err)
+	}
+	return generic, nil
+}
+
+// ageInDays returns the whole days elapsed since t
+func ageInDays(t time.Time) int {
+	return int(inventoryNow().Sub(t).Hours() / 24)
+}
+
+func joinErrors(existing, message string) string {
+	if existing == "" {
+		return message
+	}
+	return existing + "; " + message
+}
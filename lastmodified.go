@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// resolveLastModified sets the group's last modified time: the latest changedTime of its
+// resources or the latest deployment, whichever is more recent. A failed deployment lookup is
+// kept on the result and the resource times are still used.
+func (ac *AzureClient) resolveLastModified(result *ResourceGroupResult) {
+	result.LastModified = latestChangedTime(result.Resources)
+
+	history, err := ac.deploymentHistory(result)
+	if err != nil || history.Latest == nil {
+		return
+	}
+	if result.LastModified == nil || history.Latest.After(*result.LastModified) {
+		latest := *history.Latest
+		result.LastModified = &latest
+	}
+}
+
+// latestChangedTime returns the most recent changedTime among resources, or nil if none is known
+func latestChangedTime(resources []Resource) *time.Time {
+	var latest *time.Time
+	for _, resource := range resources {
+		if resource.ChangedTime != nil && (latest == nil || resource.ChangedTime.After(*latest)) {
+			latest = resource.ChangedTime
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	t := latest.UTC()
+	return &t
+}
+
+// daysSinceChange returns whole days since the last modification, or nil when it is unknown
+func daysSinceChange(result ResourceGroupResult) *int {
+	if result.Error != nil || result.LastModified == nil {
+		return nil
+	}
+	days := ageInDays(*result.LastModified)
+	return &days
+}
+
+// lastModifiedColumns returns the extra porcelain header columns when --last-modified is set
+func (ac *AzureClient) lastModifiedColumns() []string {
+	if !ac.Config.LastModified {
+		return nil
+	}
+	return []string{"LAST_MODIFIED", "DAYS_SINCE_CHANGE"}
+}
+
+// lastModifiedValues returns the porcelain values matching lastModifiedColumns
+func (ac *AzureClient) lastModifiedValues(result ResourceGroupResult) []string {
+	if !ac.Config.LastModified {
+		return nil
+	}
+	return []string{columnLastModified(result, columnStylePorcelain), columnDaysSinceChange(result, columnStylePorcelain)}
+}
+
+func columnLastModified(r ResourceGroupResult, style columnStyle) string {
+	switch {
+	case r.Error == nil && r.LastModified != nil:
+		return r.LastModified.Format(time.RFC3339)
+	case style == columnStyleCSV:
+		return ""
+	default:
+		return "N/A"
+	}
+}
+
+func columnDaysSinceChange(r ResourceGroupResult, style columnStyle) string {
+	if days := daysSinceChange(r); days != nil {
+		return strconv.Itoa(*days)
+	}
+	if style == columnStyleCSV {
+		return ""
+	}
+	return "N/A"
+}
+
+// printLastModified prints the last modified time in the human-readable output
+func (ac *AzureClient) printLastModified(result ResourceGroupResult) {
+	if !ac.Config.LastModified || result.Error != nil {
+		return
+	}
+	if days := daysSinceChange(result); days != nil {
+		fmt.Printf("  Last Modified: %s (%d days ago)\n", result.LastModified.Format(time.RFC3339), *days)
+	} else {
+		fmt.Printf("  Last Modified: Not available\n")
+	}
+	if result.DeploymentsError != nil && !ac.customCreatedTimeSources() {
+		fmt.Printf("  Deployments: lookup failed (%v)\n", result.DeploymentsError)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveLastModified(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/providers/Microsoft.Resources/deployments") {
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+		switch {
+		case strings.Contains(req.URL.Path, "/resourceGroups/deployed/"):
+			return jsonResponse(http.StatusOK, `{"value": [{"properties": {"timestamp": "2023-01-01T00:00:00Z"}}, {"properties": {"timestamp": "2023-06-20T00:00:00Z"}}]}`), nil
+		case strings.Contains(req.URL.Path, "/resourceGroups/broken/"):
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+	client.Config.LastModified = true
+
+	older := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	resources := []Resource{{Name: "a", ChangedTime: &older}, {Name: "b", ChangedTime: &newer}, {Name: "c"}}
+
+	tests := []struct {
+		name     string
+		expected string
+		days     int
+	}{
+		{"quiet", "2023-06-01T00:00:00Z", 29},
+		{"deployed", "2023-06-20T00:00:00Z", 10},
+		{"broken", "2023-06-01T00:00:00Z", 29},
+	}
+	for _, tt := range tests {
+		result := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: tt.name}, Resources: resources}
+		client.enrichResourceGroupResult(&result)
+		if result.LastModified == nil || result.LastModified.Format(time.RFC3339) != tt.expected {
+			t.Errorf("%s: expected %s, got %v", tt.name, tt.expected, result.LastModified)
+			continue
+		}
+		if days := daysSinceChange(result); days == nil || *days != tt.days {
+			t.Errorf("%s: expected %d days, got %v", tt.name, tt.days, days)
+		}
+		if (tt.name == "broken") != (result.DeploymentsError != nil) {
+			t.Errorf("%s: unexpected deployments error %v", tt.name, result.DeploymentsError)
+		}
+	}
+
+	empty := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "empty"}}
+	client.enrichResourceGroupResult(&empty)
+	if empty.LastModified != nil || daysSinceChange(empty) != nil {
+		t.Errorf("expected no last modified time, got %v", empty.LastModified)
+	}
+}
+
+func TestLastModifiedOutputs(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	changed := time.Date(2023, 6, 20, 0, 0, 0, 0, time.UTC)
+	result := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "rg"}, LastModified: &changed}
+	unknown := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "unknown"}}
+
+	client := &AzureClient{Config: Config{LastModified: true}}
+	if got := strings.Join(client.lastModifiedColumns(), ","); got != "LAST_MODIFIED,DAYS_SINCE_CHANGE" {
+		t.Errorf("unexpected columns %s", got)
+	}
+	if got := strings.Join(client.lastModifiedValues(result), ","); got != "2023-06-20T00:00:00Z,10" {
+		t.Errorf("unexpected values %s", got)
+	}
+	if got := strings.Join(client.lastModifiedValues(unknown), ","); got != "N/A,N/A" {
+		t.Errorf("unexpected values for unknown time %s", got)
+	}
+
+	inventory := client.buildInventory([]ResourceGroupResult{result}, false)
+	if inventory[0].DaysSinceChange == nil || *inventory[0].DaysSinceChange != 10 || !inventory[0].LastModified.Equal(changed) {
+		t.Errorf("unexpected inventory %+v", inventory[0])
+	}
+
+	var keys []string
+	for _, column := range client.csvGroupColumns() {
+		keys = append(keys, column.Key)
+	}
+	if !containsString(keys, "last_modified") || !containsString(keys, "days_since_change") {
+		t.Errorf("expected last modified CSV columns, got %v", keys)
+	}
+
+	client.Config.LastModified = false
+	if client.lastModifiedColumns() != nil || client.lastModifiedValues(result) != nil {
+		t.Error("expected no columns without --last-modified")
+	}
+}
@@ -12,8 +12,10 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
+	"github.com/jmespath/go-jmespath"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -45,6 +47,7 @@ type ResourceGroup struct {
 	Properties struct {
 		ProvisioningState string `json:"provisioningState"`
 	} `json:"properties"`
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 type ResourceGroupsResponse struct {
@@ -52,10 +55,13 @@ type ResourceGroupsResponse struct {
 }
 
 type Resource struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Type        string     `json:"type"`
-	CreatedTime *time.Time `json:"createdTime,omitempty"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Location    string            `json:"location,omitempty"`
+	CreatedTime *time.Time        `json:"createdTime,omitempty"`
+	ChangedTime *time.Time        `json:"changedTime,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
 }
 
 type ResourcesResponse struct {
@@ -69,6 +75,73 @@ type Config struct {
 	MaxConcurrency int
 	OutputCSV      string
 	Porcelain      bool
+
+	// CSV layout (one row per group or per resource) and an optional linked resources CSV
+	CSVLayout          string
+	OutputResourcesCSV string
+
+	// Excel workbook output with Groups, Resources and Summary sheets
+	OutputXLSX string
+
+	// Self-contained HTML report
+	OutputHTML string
+
+	// Guard rails: protected-groups config file and management lock lookups
+	ProtectedConfig string
+	CheckLocks      bool
+
+	// Creation time sources tried in order, the tag keys read by the tag source, and the
+	// Activity Log lookup of the resource group write event for creation time and creator
+	CreatedTimeSources []string
+	CreatedTimeTags    []string
+	ActivityLog        bool
+	ActivityLogDays    int
+
+	// Asynchronous operation polling (used by write operations such as apply)
+	PollInterval     time.Duration
+	OperationTimeout time.Duration
+
+	// Inventory filters (name, location, age, default status, provisioning state, tags)
+	Filters           FilterOptions
+	MissingTagsReport bool
+
+	// Last modified time from resource changedTime and the deployment history
+	LastModified bool
+
+	// Owner/Contributor role assignments on each group, optionally resolved to names through a
+	// Graph-compatible endpoint
+	RoleAssignments   bool
+	ResolvePrincipals bool
+	GraphEndpoint     string
+	GraphToken        string
+
+	// Policy Insights compliance summary per group
+	PolicyCompliance bool
+
+	// Cost Management totals per group for a period, optionally ordering the output by cost
+	Cost       bool
+	CostPeriod string
+	SortByCost bool
+
+	// Resources missing their group's tags (or carrying different values), optionally
+	// restricted to some tag keys
+	TagInheritance     bool
+	TagInheritanceKeys []string
+
+	// Aggregated statistics printed after the scan, or instead of the per-group output
+	Summary     bool
+	SummaryOnly bool
+	SummaryTop  int
+
+	// Structured output (json, yaml, table) and an optional JMESPath query applied before rendering
+	OutputFormat string
+	Query        string
+
+	// Section grouping of the Markdown report (none, location, category)
+	MarkdownGroupBy string
+
+	// Go text/template output, executed per group or once for the whole inventory
+	TemplateScope string
 }
 
 // Spinner represents a simple text spinner for CLI feedback
@@ -119,6 +192,13 @@ func (s *Spinner) Stop() {
 type AzureClient struct {
 	Config     Config
 	HTTPClient HTTPClient
+	Protection *ProtectionRules
+	Filter     *ResourceGroupFilter
+	Query      *jmespath.JMESPath
+	Template   *template.Template
+	Columns    []Column
+	Principals *PrincipalResolver
+	Costs      *CostTable
 }
 
 // ResourceGroupResult holds the result of processing a resource group
@@ -126,6 +206,43 @@ type ResourceGroupResult struct {
 	ResourceGroup ResourceGroup
 	CreatedTime   *time.Time
 	Error         error
+
+	// Where CreatedTime came from and, when the Activity Log is queried, who created the group
+	CreatedTimeSource string
+	Creator           string
+	ActivityLogError  error
+
+	// Deployment history, fetched when a creation time source or the last modified time needs it
+	Deployments      *DeploymentHistory
+	DeploymentsError error
+
+	// Most recent change to the group's resources or deployments, with --last-modified
+	LastModified *time.Time
+
+	// Owner/Contributor assignments made directly on the group, with --role-assignments
+	RoleAssignments      []RoleAssignment
+	RoleAssignmentsError error
+	PrincipalsError      error
+
+	// Policy compliance summary, with --policy-compliance
+	Policy      *PolicySummary
+	PolicyError error
+
+	// Cost for the --cost period; zero for groups without usage
+	Cost         *float64
+	CostCurrency string
+
+	// Resources missing the group's tags, with --tag-inheritance
+	TagInheritance *TagInheritance
+
+	// Guard-rail information, populated when protection rules or lock checks are enabled
+	Protected        bool
+	ProtectionReason string
+	Locks            []ManagementLock
+	LocksError       error
+
+	// Resources in the group, populated when resources are listed
+	Resources []Resource
 }
 
 var config Config
@@ -154,6 +271,44 @@ func init() {
 	rootCmd.PersistentFlags().Int("max-concurrency", 10, "Maximum number of concurrent API calls (minimum: 1)")
 	rootCmd.PersistentFlags().String("output-csv", "", "Output results to CSV file (specify file path)")
 	rootCmd.PersistentFlags().Bool("porcelain", false, "Output results in a machine-readable format optimized for scripts (tab-separated values, no spinner)")
+	rootCmd.PersistentFlags().String("protected-config", "", "Config file (YAML/JSON/TOML) listing protected resource group names, patterns and tag selectors")
+	rootCmd.PersistentFlags().Bool("check-locks", false, "Query management locks (CanNotDelete/ReadOnly) for each resource group")
+	rootCmd.PersistentFlags().String("config", "", "Config file (YAML/JSON/TOML) with default inventory filters in a filters section")
+
+	// Inventory filters
+	addFilterFlags(rootCmd.Flags())
+	rootCmd.Flags().Bool("missing-tags-report", false, "Report which tag keys are missing from the most resource groups")
+	rootCmd.Flags().StringSlice("created-time-sources", defaultCreatedTimeSources, "Creation time sources to try in order: resources, tag, deployment, activity-log")
+	rootCmd.Flags().StringSlice("created-time-tags", defaultCreatedTimeTags, "Tag keys holding a creation time, read by the tag source (case-insensitive)")
+	rootCmd.Flags().Bool("activity-log", false, "Look up each resource group's creation time and creator in the Activity Log")
+	rootCmd.Flags().Int("activity-log-days", activityLogRetentionDays, "How many days of Activity Log to search (1-90)")
+	rootCmd.Flags().Bool("last-modified", false, "Report each resource group's last modified time and days since the last change (resource changedTime and deployments)")
+	rootCmd.Flags().Bool("role-assignments", false, "List Owner/Contributor role assignments made directly on each resource group and report them as inferred owners")
+	rootCmd.Flags().Bool("resolve-principals", false, "Resolve role assignment principals to display names and UPNs through Microsoft Graph (implies --role-assignments)")
+	rootCmd.Flags().String("graph-endpoint", defaultGraphEndpoint, "Graph-compatible endpoint used by --resolve-principals")
+	rootCmd.Flags().String("graph-token", "", "Access token for the Graph endpoint (or AZURE_GRAPH_TOKEN)")
+	rootCmd.Flags().Bool("policy-compliance", false, "Report non-compliant resource and policy counts per resource group from Policy Insights")
+	rootCmd.Flags().Bool("cost", false, "Report each resource group's cost from Cost Management")
+	rootCmd.Flags().String("cost-period", defaultCostPeriod, "Cost period: month-to-date, last-month, week-to-date or a window ending now such as 30d or 2w")
+	rootCmd.Flags().Bool("sort-by-cost", false, "Order resource groups by cost, most expensive first (implies --cost)")
+	rootCmd.Flags().Bool("tag-inheritance", false, "Report resources missing their resource group's tags or carrying different values (implies --list-resources)")
+	rootCmd.Flags().StringSlice("tag-inheritance-keys", nil, "Only check these group tag keys for --tag-inheritance (implies --tag-inheritance)")
+	rootCmd.Flags().Bool("summary", false, "Print summary statistics (counts by location, state, category and age, resource types, errors) after the scan")
+	rootCmd.Flags().Bool("summary-only", false, "Print only the summary statistics; with --output json or yaml, print them in that format")
+	rootCmd.Flags().Int("summary-top", defaultSummaryTop, "Number of resource types listed in the summary (0 lists all)")
+
+	// Structured output
+	rootCmd.Flags().StringP("output", "o", "", "Structured output format: json, yaml, table or markdown (default: human-readable)")
+	rootCmd.Flags().String("query", "", "JMESPath query applied to the structured inventory before rendering, e.g. \"[?detection.isDefault].name\" (implies --output json)")
+	rootCmd.Flags().String("template", "", "Go text/template used to render each resource group (see README for fields and helpers)")
+	rootCmd.Flags().String("template-file", "", "File containing a Go text/template used to render the output")
+	rootCmd.Flags().String("markdown-group-by", markdownGroupByNone, "Split the Markdown report into sections by location or category (none, location, category)")
+	rootCmd.Flags().String("columns", "", "Comma-separated columns for CSV, porcelain and table output, e.g. name,location,age_days,tags.owner (see README)")
+	rootCmd.Flags().String("template-scope", templateScopeGroup, "Run the template once per resource group (group) or once for the whole inventory (inventory)")
+	rootCmd.Flags().String("csv-layout", csvLayoutGroup, "CSV layout for --output-csv: one row per resource group (group) or one row per resource (resource)")
+	rootCmd.Flags().String("output-resources-csv", "", "Also write resources to this CSV file, one row per resource linked to the groups by ResourceGroupID")
+	rootCmd.Flags().String("output-xlsx", "", "Output results to an Excel workbook with Groups, Resources and Summary sheets (specify file path)")
+	rootCmd.Flags().String("output-html", "", "Output results to a self-contained HTML report (specify file path)")
 
 	// Bind flags to viper
 	if err := viper.BindPFlag("subscription-id", rootCmd.PersistentFlags().Lookup("subscription-id")); err != nil {
@@ -174,6 +329,105 @@ func init() {
 	if err := viper.BindPFlag("porcelain", rootCmd.PersistentFlags().Lookup("porcelain")); err != nil {
 		log.Fatalf("Failed to bind porcelain flag: %v", err)
 	}
+	if err := viper.BindPFlag("protected-config", rootCmd.PersistentFlags().Lookup("protected-config")); err != nil {
+		log.Fatalf("Failed to bind protected-config flag: %v", err)
+	}
+	if err := viper.BindPFlag("check-locks", rootCmd.PersistentFlags().Lookup("check-locks")); err != nil {
+		log.Fatalf("Failed to bind check-locks flag: %v", err)
+	}
+	if err := viper.BindPFlag("missing-tags-report", rootCmd.Flags().Lookup("missing-tags-report")); err != nil {
+		log.Fatalf("Failed to bind missing-tags-report flag: %v", err)
+	}
+	if err := viper.BindPFlag("output", rootCmd.Flags().Lookup("output")); err != nil {
+		log.Fatalf("Failed to bind output flag: %v", err)
+	}
+	if err := viper.BindPFlag("query", rootCmd.Flags().Lookup("query")); err != nil {
+		log.Fatalf("Failed to bind query flag: %v", err)
+	}
+	if err := viper.BindPFlag("template", rootCmd.Flags().Lookup("template")); err != nil {
+		log.Fatalf("Failed to bind template flag: %v", err)
+	}
+	if err := viper.BindPFlag("template-file", rootCmd.Flags().Lookup("template-file")); err != nil {
+		log.Fatalf("Failed to bind template-file flag: %v", err)
+	}
+	if err := viper.BindPFlag("template-scope", rootCmd.Flags().Lookup("template-scope")); err != nil {
+		log.Fatalf("Failed to bind template-scope flag: %v", err)
+	}
+	if err := viper.BindPFlag("columns", rootCmd.Flags().Lookup("columns")); err != nil {
+		log.Fatalf("Failed to bind columns flag: %v", err)
+	}
+	if err := viper.BindPFlag("csv-layout", rootCmd.Flags().Lookup("csv-layout")); err != nil {
+		log.Fatalf("Failed to bind csv-layout flag: %v", err)
+	}
+	if err := viper.BindPFlag("output-resources-csv", rootCmd.Flags().Lookup("output-resources-csv")); err != nil {
+		log.Fatalf("Failed to bind output-resources-csv flag: %v", err)
+	}
+	if err := viper.BindPFlag("output-xlsx", rootCmd.Flags().Lookup("output-xlsx")); err != nil {
+		log.Fatalf("Failed to bind output-xlsx flag: %v", err)
+	}
+	if err := viper.BindPFlag("output-html", rootCmd.Flags().Lookup("output-html")); err != nil {
+		log.Fatalf("Failed to bind output-html flag: %v", err)
+	}
+	if err := viper.BindPFlag("markdown-group-by", rootCmd.Flags().Lookup("markdown-group-by")); err != nil {
+		log.Fatalf("Failed to bind markdown-group-by flag: %v", err)
+	}
+	if err := viper.BindPFlag("summary", rootCmd.Flags().Lookup("summary")); err != nil {
+		log.Fatalf("Failed to bind summary flag: %v", err)
+	}
+	if err := viper.BindPFlag("summary-only", rootCmd.Flags().Lookup("summary-only")); err != nil {
+		log.Fatalf("Failed to bind summary-only flag: %v", err)
+	}
+	if err := viper.BindPFlag("summary-top", rootCmd.Flags().Lookup("summary-top")); err != nil {
+		log.Fatalf("Failed to bind summary-top flag: %v", err)
+	}
+	if err := viper.BindPFlag("activity-log", rootCmd.Flags().Lookup("activity-log")); err != nil {
+		log.Fatalf("Failed to bind activity-log flag: %v", err)
+	}
+	if err := viper.BindPFlag("activity-log-days", rootCmd.Flags().Lookup("activity-log-days")); err != nil {
+		log.Fatalf("Failed to bind activity-log-days flag: %v", err)
+	}
+	if err := viper.BindPFlag("created-time-sources", rootCmd.Flags().Lookup("created-time-sources")); err != nil {
+		log.Fatalf("Failed to bind created-time-sources flag: %v", err)
+	}
+	if err := viper.BindPFlag("created-time-tags", rootCmd.Flags().Lookup("created-time-tags")); err != nil {
+		log.Fatalf("Failed to bind created-time-tags flag: %v", err)
+	}
+	if err := viper.BindPFlag("last-modified", rootCmd.Flags().Lookup("last-modified")); err != nil {
+		log.Fatalf("Failed to bind last-modified flag: %v", err)
+	}
+	if err := viper.BindPFlag("role-assignments", rootCmd.Flags().Lookup("role-assignments")); err != nil {
+		log.Fatalf("Failed to bind role-assignments flag: %v", err)
+	}
+	if err := viper.BindPFlag("resolve-principals", rootCmd.Flags().Lookup("resolve-principals")); err != nil {
+		log.Fatalf("Failed to bind resolve-principals flag: %v", err)
+	}
+	if err := viper.BindPFlag("graph-endpoint", rootCmd.Flags().Lookup("graph-endpoint")); err != nil {
+		log.Fatalf("Failed to bind graph-endpoint flag: %v", err)
+	}
+	if err := viper.BindPFlag("graph-token", rootCmd.Flags().Lookup("graph-token")); err != nil {
+		log.Fatalf("Failed to bind graph-token flag: %v", err)
+	}
+	if err := viper.BindPFlag("cost", rootCmd.Flags().Lookup("cost")); err != nil {
+		log.Fatalf("Failed to bind cost flag: %v", err)
+	}
+	if err := viper.BindPFlag("cost-period", rootCmd.Flags().Lookup("cost-period")); err != nil {
+		log.Fatalf("Failed to bind cost-period flag: %v", err)
+	}
+	if err := viper.BindPFlag("sort-by-cost", rootCmd.Flags().Lookup("sort-by-cost")); err != nil {
+		log.Fatalf("Failed to bind sort-by-cost flag: %v", err)
+	}
+	if err := viper.BindPFlag("policy-compliance", rootCmd.Flags().Lookup("policy-compliance")); err != nil {
+		log.Fatalf("Failed to bind policy-compliance flag: %v", err)
+	}
+	if err := viper.BindPFlag("tag-inheritance", rootCmd.Flags().Lookup("tag-inheritance")); err != nil {
+		log.Fatalf("Failed to bind tag-inheritance flag: %v", err)
+	}
+	if err := viper.BindPFlag("tag-inheritance-keys", rootCmd.Flags().Lookup("tag-inheritance-keys")); err != nil {
+		log.Fatalf("Failed to bind tag-inheritance-keys flag: %v", err)
+	}
+	if err := bindFilterFlags(viper.GetViper(), rootCmd.Flags()); err != nil {
+		log.Fatalf("Failed to bind filter flags: %v", err)
+	}
 }
 
 func initConfig() {
@@ -181,12 +435,48 @@ func initConfig() {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
+	// Read the config file; flags and environment variables take precedence over it
+	if configFile, _ := rootCmd.PersistentFlags().GetString("config"); configFile != "" {
+		viper.SetConfigFile(configFile)
+		if err := viper.ReadInConfig(); err != nil {
+			log.Fatalf("Failed to read config file: %v", err)
+		}
+	}
+
 	// Set defaults
 	config.SubscriptionID = viper.GetString("subscription-id")
 	config.AccessToken = viper.GetString("access-token")
 	config.MaxConcurrency = viper.GetInt("max-concurrency")
 	config.OutputCSV = viper.GetString("output-csv")
 	config.Porcelain = viper.GetBool("porcelain")
+	config.ProtectedConfig = viper.GetString("protected-config")
+	config.CheckLocks = viper.GetBool("check-locks")
+	config.Filters = filterOptionsFromConfig(viper.GetViper())
+	config.MissingTagsReport = viper.GetBool("missing-tags-report")
+	createdTimeSources := viper.GetStringSlice("created-time-sources")
+	config.CreatedTimeTags = viper.GetStringSlice("created-time-tags")
+	config.ActivityLog = viper.GetBool("activity-log")
+	config.ActivityLogDays = viper.GetInt("activity-log-days")
+	config.LastModified = viper.GetBool("last-modified")
+	config.RoleAssignments = viper.GetBool("role-assignments")
+	config.ResolvePrincipals = viper.GetBool("resolve-principals")
+	config.GraphEndpoint = viper.GetString("graph-endpoint")
+	config.GraphToken = viper.GetString("graph-token")
+	config.PolicyCompliance = viper.GetBool("policy-compliance")
+	config.Cost = viper.GetBool("cost")
+	config.CostPeriod = viper.GetString("cost-period")
+	config.SortByCost = viper.GetBool("sort-by-cost")
+	config.TagInheritance = viper.GetBool("tag-inheritance")
+	config.TagInheritanceKeys = viper.GetStringSlice("tag-inheritance-keys")
+	config.Summary = viper.GetBool("summary")
+	config.SummaryOnly = viper.GetBool("summary-only")
+	config.SummaryTop = viper.GetInt("summary-top")
+	config.OutputFormat = viper.GetString("output")
+	config.Query = viper.GetString("query")
+	config.OutputFormat = strings.ToLower(config.OutputFormat)
+	if config.Query != "" && config.OutputFormat == "" {
+		config.OutputFormat = outputJSON
+	}
 
 	// If not provided via flags, try environment variables
 	if config.SubscriptionID == "" {
@@ -195,6 +485,9 @@ func initConfig() {
 	if config.AccessToken == "" {
 		config.AccessToken = os.Getenv("AZURE_ACCESS_TOKEN")
 	}
+	if config.GraphToken == "" {
+		config.GraphToken = os.Getenv("AZURE_GRAPH_TOKEN")
+	}
 	if config.MaxConcurrency == 0 {
 		config.MaxConcurrency = 10
 	}
@@ -210,45 +503,232 @@ func initConfig() {
 	// Validate concurrency configuration to prevent hanging
 	config.MaxConcurrency = validateConcurrency(config.MaxConcurrency)
 
+	// Load protected-group rules if configured
+	var protection *ProtectionRules
+	if config.ProtectedConfig != "" {
+		rules, err := loadProtectionRules(config.ProtectedConfig)
+		if err != nil {
+			log.Fatalf("Failed to load protected groups config: %v", err)
+		}
+		protection = rules
+	}
+
+	filter, err := NewResourceGroupFilter(config.Filters, time.Now())
+	if err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+	if filter.NeedsPolicyCompliance() {
+		config.PolicyCompliance = true
+	}
+	if config.SortByCost || filter.NeedsCost() {
+		config.Cost = true
+	}
+	if config.Cost {
+		if _, err := parseCostPeriod(config.CostPeriod, time.Now()); err != nil {
+			log.Fatalf("Invalid cost settings: %v", err)
+		}
+	}
+	if len(config.TagInheritanceKeys) > 0 {
+		config.TagInheritance = true
+	}
+	config.CreatedTimeSources, err = parseCreatedTimeSources(createdTimeSources)
+	if err != nil {
+		log.Fatalf("Invalid creation time settings: %v", err)
+	}
+	if config.ActivityLog || containsString(config.CreatedTimeSources, createdTimeSourceActivityLog) {
+		if err := validateActivityLogDays(config.ActivityLogDays); err != nil {
+			log.Fatalf("Invalid activity log settings: %v", err)
+		}
+	}
+
+	// Validate structured output settings
+	if err := validateOutputFormat(config.OutputFormat); err != nil {
+		log.Fatalf("Invalid output: %v", err)
+	}
+	if config.OutputFormat != "" && config.Porcelain {
+		log.Fatal("--porcelain cannot be combined with --output or --query")
+	}
+	query, err := compileQuery(config.Query)
+	if err != nil {
+		log.Fatalf("Invalid output: %v", err)
+	}
+	if config.OutputFormat == outputMarkdown && query != nil {
+		log.Fatal("--query cannot be combined with --output markdown")
+	}
+	config.MarkdownGroupBy = viper.GetString("markdown-group-by")
+	if err := validateMarkdownGroupBy(config.MarkdownGroupBy); err != nil {
+		log.Fatalf("Invalid output: %v", err)
+	}
+
+	templateText := viper.GetString("template")
+	templateFile := viper.GetString("template-file")
+	config.TemplateScope = viper.GetString("template-scope")
+	outputTemplate, err := loadOutputTemplate(templateText, templateFile, config.TemplateScope)
+	if err != nil {
+		log.Fatalf("Invalid template: %v", err)
+	}
+	if outputTemplate != nil && (config.OutputFormat != "" || config.Porcelain) {
+		log.Fatal("--template cannot be combined with --output, --query or --porcelain")
+	}
+	if config.SummaryOnly {
+		if config.Porcelain || outputTemplate != nil {
+			log.Fatal("--summary-only cannot be combined with --porcelain or --template")
+		}
+		if config.OutputFormat != "" && config.OutputFormat != outputJSON && config.OutputFormat != outputYAML {
+			log.Fatal("--summary-only supports --output json or yaml")
+		}
+	}
+
+	config.CSVLayout = viper.GetString("csv-layout")
+	config.OutputResourcesCSV = viper.GetString("output-resources-csv")
+	config.OutputXLSX = viper.GetString("output-xlsx")
+	config.OutputHTML = viper.GetString("output-html")
+	if err := validateCSVLayout(config.CSVLayout); err != nil {
+		log.Fatalf("Invalid CSV output: %v", err)
+	}
+	if config.CSVLayout == csvLayoutResource && config.OutputCSV == "" {
+		log.Fatal("--csv-layout resource requires --output-csv")
+	}
+
+	var columns []Column
+	if columnSpec := viper.GetString("columns"); columnSpec != "" {
+		columns, err = ParseColumns(columnSpec)
+		if err != nil {
+			log.Fatalf("Invalid columns: %v", err)
+		}
+	}
+
 	// Initialize Azure client with optimized HTTP client
-	azureClient = &AzureClient{
-		Config: config,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
 		},
 	}
+
+	var principals *PrincipalResolver
+	if config.ResolvePrincipals {
+		config.RoleAssignments = true
+		if config.GraphToken == "" {
+			log.Fatal("--resolve-principals requires a Graph token. Set via --graph-token flag or AZURE_GRAPH_TOKEN environment variable")
+		}
+		principals, err = NewPrincipalResolver(config.GraphEndpoint, config.GraphToken, httpClient)
+		if err != nil {
+			log.Fatalf("Invalid principal resolution settings: %v", err)
+		}
+	}
+
+	azureClient = &AzureClient{
+		Config:     config,
+		HTTPClient: httpClient,
+		Protection: protection,
+		Filter:     filter,
+		Query:      query,
+		Template:   outputTemplate,
+		Columns:    columns,
+		Principals: principals,
+	}
 }
 
 func (ac *AzureClient) makeAzureRequest(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	resp, err := ac.sendAzureRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+ac.Config.AccessToken)
-	req.Header.Set("Content-Type", "application/json")
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Warning: failed to close response body: %v", err)
+		}
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
 
-	resp, err := ac.HTTPClient.Do(req)
+	return resp, nil
+}
+
+// doAzureRequest performs an authenticated request with an arbitrary method and
+// accepts any 2xx status, which write operations such as DELETE and PATCH use
+// to signal accepted or asynchronous work
+func (ac *AzureClient) doAzureRequest(method, url string, body io.Reader) (*http.Response, error) {
+	resp, err := ac.sendAzureRequest(method, url, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		body, _ := io.ReadAll(resp.Body)
 		if err := resp.Body.Close(); err != nil {
 			log.Printf("Warning: failed to close response body: %v", err)
 		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &AzureAPIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return resp, nil
 }
 
+// sendAzureRequest builds and sends an authenticated request without checking the status code
+func (ac *AzureClient) sendAzureRequest(method, url string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+ac.Config.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ac.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// AzureAPIError is returned by doAzureRequest when the API responds with a non-2xx status
+type AzureAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *AzureAPIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// decodeAzureResponse reads and closes the response body, decoding it into v
+func decodeAzureResponse(resp *http.Response, v interface{}) error {
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// readResponseBody reads and closes a response body
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	defer closeResponseBody(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
+
+// closeResponseBody closes a response body, logging any failure
+func closeResponseBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("Warning: failed to close response body: %v", err)
+	}
+}
+
 // DefaultResourceGroupInfo represents information about a default resource group
 type DefaultResourceGroupInfo struct {
 	IsDefault   bool
@@ -377,83 +857,197 @@ func (ac *AzureClient) FetchResourceGroups() error {
 		log.Printf("Operation completed in %v, Memory usage: %d KB", time.Since(start), m.Alloc/1024)
 	}()
 
-	if !ac.Config.Porcelain {
+	if ac.humanOutput() {
 		fmt.Println("Fetching resource groups...")
 	}
 
 	// Fetch all resource groups
-	url := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourcegroups?api-version=2021-04-01", ac.Config.SubscriptionID)
-
-	resp, err := ac.makeAzureRequest(url)
+	resourceGroups, err := ac.listResourceGroups()
 	if err != nil {
-		return fmt.Errorf("failed to fetch resource groups: %w", err)
+		return err
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Warning: failed to close response body: %v", err)
-		}
-	}()
+	rgResponse := ResourceGroupsResponse{Value: resourceGroups}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
+	// Apply the filters that only need the listing before any per-group API calls
+	filteredGroups := ac.Filter.FilterGroups(rgResponse.Value)
 
-	var rgResponse ResourceGroupsResponse
-	if err := json.Unmarshal(body, &rgResponse); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	// Costs come from one subscription-wide query, so cost filters and ordering also apply
+	// before the per-group calls
+	if ac.Config.Cost {
+		if err := ac.loadCosts(); err != nil {
+			return err
+		}
+		filteredGroups = ac.applyCosts(filteredGroups)
 	}
 
 	if ac.Config.Porcelain {
 		// Print header for porcelain mode
-		fmt.Printf("NAME\tLOCATION\tPROVISIONING_STATE\tCREATED_TIME\tIS_DEFAULT\n")
-	} else {
+		header := append([]string{"NAME", "LOCATION", "PROVISIONING_STATE", "CREATED_TIME", "IS_DEFAULT"}, ac.protectionColumns()...)
+		header = append(header, ac.lastModifiedColumns()...)
+		header = append(header, ac.ownershipColumns()...)
+		header = append(header, ac.policyColumns()...)
+		header = append(header, ac.costColumns()...)
+		header = append(header, ac.tagInheritanceColumns()...)
+		if ac.Columns != nil {
+			header = columnHeaders(ac.Columns, columnStylePorcelain)
+		}
+		fmt.Println(strings.Join(header, "\t"))
+	} else if ac.humanOutput() {
 		fmt.Printf("Found %d resource groups:\n\n", len(rgResponse.Value))
 	}
 
-	// Check if we should list resources
-	listResources := viper.GetBool("list-resources")
+	// Check if we should list resources. Tag inheritance gaps are reported per resource, so
+	// --tag-inheritance lists them too.
+	listResources := viper.GetBool("list-resources") || ac.Config.TagInheritance
 
 	// Check if CSV output is enabled
 	outputCSV := ac.Config.OutputCSV != ""
 
-	var csvData []CSVRow
-	if outputCSV {
-		csvData = make([]CSVRow, 0, len(rgResponse.Value))
+	// Process resource groups concurrently
+	var results []ResourceGroupResult
+	switch {
+	case ac.Config.SummaryOnly:
+		results = ac.collectResourceGroupResults(filteredGroups, listResources, "Processing resource groups...")
+	case ac.Template != nil:
+		results = ac.collectResourceGroupResults(filteredGroups, listResources, "")
+		if err := ac.renderTemplate(os.Stdout, results, listResources); err != nil {
+			return err
+		}
+	case ac.Config.OutputFormat != "":
+		// Structured output is rendered once all results are in
+		results = ac.collectResourceGroupResults(filteredGroups, listResources, "")
+		if err := ac.renderInventory(os.Stdout, results, listResources); err != nil {
+			return err
+		}
+	case listResources:
+		results = ac.processResourceGroupsConcurrentlyWithResources(filteredGroups)
+	default:
+		results = ac.processResourceGroupsConcurrently(filteredGroups)
 	}
 
-	// Process resource groups concurrently
-	if listResources {
-		if outputCSV {
-			csvData = ac.processResourceGroupsConcurrentlyWithResourcesCSV(rgResponse.Value)
-		} else {
-			ac.processResourceGroupsConcurrentlyWithResources(rgResponse.Value)
+	if ac.Filter != nil && ac.humanOutput() {
+		fmt.Printf("Filters matched %d of %d resource groups\n", len(results), len(rgResponse.Value))
+	}
+	if ac.Costs != nil && ac.humanOutput() && !ac.Config.Summary && !ac.Config.SummaryOnly {
+		printCostTotals(buildCostSummary(results, ac.Costs))
+	}
+
+	if ac.Config.MissingTagsReport {
+		ac.printMissingTagsReport(missingTagKeys(results, ac.Filter.TagKeys(), ac.Config.Filters.TagIgnoreCase), len(results))
+	}
+
+	if ac.Config.SummaryOnly && ac.Config.OutputFormat != "" {
+		if err := ac.renderSummary(os.Stdout, ac.inventorySummary(results)); err != nil {
+			return err
 		}
-	} else {
-		if outputCSV {
-			csvData = ac.processResourceGroupsConcurrentlyCSV(rgResponse.Value)
-		} else {
-			ac.processResourceGroupsConcurrently(rgResponse.Value)
+	} else if ac.Config.Summary || ac.Config.SummaryOnly {
+		if err := ac.printSummary(ac.inventorySummary(results)); err != nil {
+			return err
 		}
 	}
 
 	// Write CSV data if output is enabled
 	if outputCSV {
-		if err := ac.writeCSVFile(csvData); err != nil {
-			return fmt.Errorf("failed to write CSV file: %w", err)
+		if ac.Config.CSVLayout == csvLayoutResource {
+			if err := writeCSVRecords(ac.Config.OutputCSV, ac.explodedCSVRecords(results)); err != nil {
+				return fmt.Errorf("failed to write CSV file: %w", err)
+			}
+		} else if ac.Columns != nil {
+			if err := ac.writeColumnsCSV(results); err != nil {
+				return fmt.Errorf("failed to write CSV file: %w", err)
+			}
+		} else {
+			csvData := make([]CSVRow, 0, len(results))
+			for _, result := range results {
+				csvData = append(csvData, ac.convertToCSVRow(result, listResources, result.Resources))
+			}
+			if err := ac.writeCSVFile(csvData); err != nil {
+				return fmt.Errorf("failed to write CSV file: %w", err)
+			}
 		}
-		if !ac.Config.Porcelain {
+		if ac.humanOutput() {
 			fmt.Printf("CSV output written to: %s\n", ac.Config.OutputCSV)
 		}
 	}
 
+	if ac.Config.OutputResourcesCSV != "" {
+		if err := writeResourcesCSV(ac.Config.OutputResourcesCSV, results); err != nil {
+			return fmt.Errorf("failed to write resources CSV file: %w", err)
+		}
+		if ac.humanOutput() {
+			fmt.Printf("Resources CSV output written to: %s\n", ac.Config.OutputResourcesCSV)
+		}
+	}
+
+	if ac.Config.OutputXLSX != "" {
+		if err := ac.writeXLSX(ac.Config.OutputXLSX, results); err != nil {
+			return fmt.Errorf("failed to write Excel file: %w", err)
+		}
+		if ac.humanOutput() {
+			fmt.Printf("Excel output written to: %s\n", ac.Config.OutputXLSX)
+		}
+	}
+
+	if ac.Config.OutputHTML != "" {
+		if err := ac.writeHTMLReport(ac.Config.OutputHTML, results); err != nil {
+			return fmt.Errorf("failed to write HTML report: %w", err)
+		}
+		if ac.humanOutput() {
+			fmt.Printf("HTML report written to: %s\n", ac.Config.OutputHTML)
+		}
+	}
+
 	return nil
 }
 
+// listResourceGroups fetches every resource group in the configured subscription
+func (ac *AzureClient) listResourceGroups() ([]ResourceGroup, error) {
+	url := NewSubscriptionResourceID(ac.Config.SubscriptionID).URL("resourcegroups", apiVersion("2021-04-01"))
+
+	resp, err := ac.makeAzureRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch resource groups: %w", err)
+	}
+
+	var rgResponse ResourceGroupsResponse
+	if err := decodeAzureResponse(resp, &rgResponse); err != nil {
+		return nil, err
+	}
+
+	return rgResponse.Value, nil
+}
+
 // processResourceGroupsConcurrently processes resource groups concurrently for better performance
-func (ac *AzureClient) processResourceGroupsConcurrently(resourceGroups []ResourceGroup) {
+func (ac *AzureClient) processResourceGroupsConcurrently(resourceGroups []ResourceGroup) []ResourceGroupResult {
+	results := ac.collectResourceGroupResults(resourceGroups, false, "Processing resource groups...")
+
+	// Print all results
+	for _, result := range results {
+		ac.printResourceGroupResult(result, false)
+	}
+
+	return results
+}
+
+// processResourceGroupsConcurrentlyWithResources processes resource groups with detailed resource listing
+func (ac *AzureClient) processResourceGroupsConcurrentlyWithResources(resourceGroups []ResourceGroup) []ResourceGroupResult {
+	results := ac.collectResourceGroupResults(resourceGroups, true, "Processing resource groups with resources...")
+
+	for _, result := range results {
+		ac.printResourceGroupResultWithResources(result, result.Resources)
+	}
+
+	return results
+}
+
+// collectResourceGroupResults fetches each resource group's resources and creation time
+// concurrently, drops groups rejected by the creation time filters and applies the optional
+// enrichments to the rest, then drops groups rejected by the compliance filter. Results keep
+// the input order.
+func (ac *AzureClient) collectResourceGroupResults(resourceGroups []ResourceGroup, listResources bool, spinnerMessage string) []ResourceGroupResult {
 	var wg sync.WaitGroup
 	results := make([]ResourceGroupResult, len(resourceGroups))
+	matched := make([]bool, len(resourceGroups))
 
 	// Ensure MaxConcurrency is at least 1 to prevent hanging
 	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
@@ -461,10 +1055,10 @@ func (ac *AzureClient) processResourceGroupsConcurrently(resourceGroups []Resour
 	// Use a semaphore to limit concurrent goroutines
 	semaphore := make(chan struct{}, maxConcurrency)
 
-	// Start spinner if not in porcelain mode
+	// Start spinner if not in porcelain or structured output mode
 	var spinner *Spinner
-	if !ac.Config.Porcelain {
-		spinner = NewSpinner("Processing resource groups...")
+	if ac.humanOutput() {
+		spinner = NewSpinner(spinnerMessage)
 		spinner.Start()
 	}
 
@@ -478,12 +1072,30 @@ func (ac *AzureClient) processResourceGroupsConcurrently(resourceGroups []Resour
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			createdTime, err := ac.fetchResourceGroupCreatedTime(rg.Name)
-			results[i] = ResourceGroupResult{
-				ResourceGroup: rg,
-				CreatedTime:   createdTime,
-				Error:         err,
+			// The creation time is usually inferred from the resources, so keep them for the
+			// outputs (resource counts, --list-resources) rather than fetching them twice
+			result := ResourceGroupResult{ResourceGroup: rg}
+			result.Resources, result.Error = ac.fetchResourcesInGroup(rg.Name)
+
+			// The tag, deployment and Activity Log sources do not need the resource listing,
+			// so they still apply when it failed
+			ac.resolveCreatedTime(&result)
+
+			// Skip enrichment lookups for groups the creation time filters reject. A group
+			// whose resources could not be listed and has no creation time from another source
+			// is kept so the error is reported rather than filtered away.
+			if (result.Error == nil || result.CreatedTime != nil) && !ac.Filter.MatchCreatedTime(result.CreatedTime) {
+				return
+			}
+
+			ac.enrichResourceGroupResult(&result)
+
+			// Likewise keep a group whose compliance lookup failed under --non-compliant-only
+			if result.PolicyError == nil && !ac.Filter.MatchPolicyCompliance(result.Policy) {
+				return
 			}
+			results[i] = result
+			matched[i] = true
 		}(i, rg)
 	}
 
@@ -495,36 +1107,26 @@ func (ac *AzureClient) processResourceGroupsConcurrently(resourceGroups []Resour
 		spinner.Stop()
 	}
 
-	// Print all results
-	for _, result := range results {
-		ac.printResourceGroupResult(result, false)
+	filtered := make([]ResourceGroupResult, 0, len(results))
+	for i, result := range results {
+		if matched[i] {
+			filtered = append(filtered, result)
+		}
 	}
+	return filtered
 }
 
-// processResourceGroupsConcurrentlyWithResources processes resource groups with detailed resource listing
-func (ac *AzureClient) processResourceGroupsConcurrentlyWithResources(resourceGroups []ResourceGroup) {
-	// Start spinner if not in porcelain mode
-	var spinner *Spinner
-	if !ac.Config.Porcelain {
-		spinner = NewSpinner("Processing resource groups with resources...")
-		spinner.Start()
-	}
-
-	// For resource listing, we don't need concurrency for the initial setup
-	// The concurrency will be handled by the resource listing itself
-	for _, rg := range resourceGroups {
-		result := ResourceGroupResult{
-			ResourceGroup: rg,
-			CreatedTime:   nil, // Will be handled in resource listing
-			Error:         nil,
+// earliestCreatedTime returns the earliest creation time among resources, or nil if none is known
+func earliestCreatedTime(resources []Resource) *time.Time {
+	var earliestTime *time.Time
+	for _, resource := range resources {
+		if resource.CreatedTime != nil {
+			if earliestTime == nil || resource.CreatedTime.Before(*earliestTime) {
+				earliestTime = resource.CreatedTime
+			}
 		}
-		ac.printResourceGroupResult(result, true)
-	}
-
-	// Stop spinner if it was started
-	if spinner != nil {
-		spinner.Stop()
 	}
+	return earliestTime
 }
 
 // printResourceGroupResult prints the result of processing a resource group
@@ -534,28 +1136,30 @@ func (ac *AzureClient) printResourceGroupResult(result ResourceGroupResult, list
 	// Check if this is a default resource group
 	defaultInfo := checkIfDefaultResourceGroup(rg.Name)
 
-	if ac.Config.Porcelain {
+	if ac.Config.Porcelain && ac.Columns != nil {
+		fmt.Println(strings.Join(columnValues(ac.Columns, result, columnStylePorcelain), "\t"))
+	} else if ac.Config.Porcelain {
 		// Porcelain mode: compact, single-line format for scripts
-		createdTime := ""
-		if result.Error != nil {
-			createdTime = "ERROR"
-		} else if result.CreatedTime != nil {
-			createdTime = result.CreatedTime.Format(time.RFC3339)
-		} else {
-			createdTime = "N/A"
-		}
+		createdTime := columnCreatedTime(result, columnStylePorcelain)
 
 		isDefault := "false"
 		if defaultInfo.IsDefault {
 			isDefault = "true"
 		}
 
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\n",
+		fields := append([]string{
 			rg.Name,
 			rg.Location,
 			rg.Properties.ProvisioningState,
 			createdTime,
-			isDefault)
+			isDefault,
+		}, ac.protectionValues(result)...)
+		fields = append(fields, ac.lastModifiedValues(result)...)
+		fields = append(fields, ac.ownershipValues(result)...)
+		fields = append(fields, ac.policyValues(result)...)
+		fields = append(fields, ac.costValues(result)...)
+		fields = append(fields, ac.tagInheritanceValues(result)...)
+		writePorcelainRow(os.Stdout, fields...)
 	} else {
 		// Human-readable format
 		fmt.Printf("Resource Group: %s\n", rg.Name)
@@ -568,243 +1172,117 @@ func (ac *AzureClient) printResourceGroupResult(result ResourceGroupResult, list
 			fmt.Printf("  📝 Description: %s\n", defaultInfo.Description)
 		}
 
+		ac.printProtectionDetails(result)
+
 		if listResources {
-			// List all resources in this resource group
-			if err := ac.listResourcesInGroup(rg.Name); err != nil {
-				fmt.Printf("  Error listing resources: %v\n", err)
-			}
+			// List the resources fetched with the creation time
+			printResources(result.Resources, result.Error)
 		} else {
 			// Just show the creation time
-			if result.Error != nil {
-				fmt.Printf("  Created Time: Error fetching (%v)\n", result.Error)
-			} else if result.CreatedTime != nil {
+			if result.CreatedTime != nil {
 				fmt.Printf("  Created Time: %s\n", result.CreatedTime.Format(time.RFC3339))
+				if result.Error != nil {
+					fmt.Printf("  Resources: Error fetching (%v)\n", result.Error)
+				}
+			} else if result.Error != nil {
+				fmt.Printf("  Created Time: Error fetching (%v)\n", result.Error)
 			} else {
 				fmt.Printf("  Created Time: Not available\n")
 			}
+			ac.printCreationDetails(result)
 		}
+		ac.printLastModified(result)
+		ac.printOwnership(result)
+		ac.printPolicyCompliance(result)
+		ac.printCost(result)
+		ac.printTagInheritance(result)
 
 		fmt.Println()
 	}
 }
 
 func (ac *AzureClient) fetchResourceGroupCreatedTime(resourceGroupName string) (*time.Time, error) {
-	url := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/resources?$expand=createdTime&api-version=2019-10-01",
-		ac.Config.SubscriptionID, resourceGroupName)
-
-	resp, err := ac.makeAzureRequest(url)
+	resources, err := ac.fetchResourcesInGroup(resourceGroupName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch resources: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Warning: failed to close response body: %v", err)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var resourcesResponse ResourcesResponse
-	if err := json.Unmarshal(body, &resourcesResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, err
 	}
 
 	// Find the earliest created time among all resources in the resource group
-	var earliestTime *time.Time
-	for _, resource := range resourcesResponse.Value {
-		if resource.CreatedTime != nil {
-			if earliestTime == nil || resource.CreatedTime.Before(*earliestTime) {
-				earliestTime = resource.CreatedTime
-			}
-		}
-	}
-
-	return earliestTime, nil
+	return earliestCreatedTime(resources), nil
 }
 
-func (ac *AzureClient) listResourcesInGroup(resourceGroupName string) error {
-	url := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/resources?$expand=createdTime&api-version=2019-10-01",
-		ac.Config.SubscriptionID, resourceGroupName)
-
-	resp, err := ac.makeAzureRequest(url)
-	if err != nil {
-		return fmt.Errorf("failed to fetch resources: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Warning: failed to close response body: %v", err)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
+// printResources prints a group's resources, or why they could not be listed
+func printResources(resources []Resource, err error) {
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	var resourcesResponse ResourcesResponse
-	if err := json.Unmarshal(body, &resourcesResponse); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		fmt.Printf("  Error listing resources: %v\n", err)
+		return
 	}
-
-	if len(resourcesResponse.Value) == 0 {
+	if len(resources) == 0 {
 		fmt.Printf("  No resources found in this resource group\n")
-		return nil
+		return
 	}
 
-	fmt.Printf("  Resources (%d):\n", len(resourcesResponse.Value))
-	for _, resource := range resourcesResponse.Value {
+	fmt.Printf("  Resources (%d):\n", len(resources))
+	for _, resource := range resources {
 		fmt.Printf("    - %s (%s)\n", resource.Name, resource.Type)
 		if resource.CreatedTime != nil {
 			fmt.Printf("      Created: %s\n", resource.CreatedTime.Format(time.RFC3339))
 		} else {
 			fmt.Printf("      Created: Not available\n")
 		}
+		printResourceLineage(resource)
 	}
-
-	return nil
 }
 
-// CSV Row structure for output
-type CSVRow struct {
-	ResourceGroupName string
-	Location          string
-	ProvisioningState string
-	CreatedTime       string
-	IsDefault         string
-	CreatedBy         string
-	Description       string
-	Resources         string
-}
-
-// processResourceGroupsConcurrentlyCSV processes resource groups concurrently and returns CSV data
-func (ac *AzureClient) processResourceGroupsConcurrentlyCSV(resourceGroups []ResourceGroup) []CSVRow {
-	var wg sync.WaitGroup
-	results := make([]ResourceGroupResult, len(resourceGroups))
-
-	// Ensure MaxConcurrency is at least 1 to prevent hanging
-	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
-
-	// Use a semaphore to limit concurrent goroutines
-	semaphore := make(chan struct{}, maxConcurrency)
-
-	// Start spinner if not in porcelain mode
-	var spinner *Spinner
-	if !ac.Config.Porcelain {
-		spinner = NewSpinner("Processing resource groups for CSV...")
-		spinner.Start()
+// printResourceLineage prints the provider and, for child resources, the parent parsed from a resource ID
+func printResourceLineage(resource Resource) {
+	if provider := resource.ProviderNamespace(); provider != "" {
+		fmt.Printf("      Provider: %s\n", provider)
 	}
-
-	// Start workers
-	for i, rg := range resourceGroups {
-		wg.Add(1)
-		go func(i int, rg ResourceGroup) {
-			defer wg.Done()
-
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			createdTime, err := ac.fetchResourceGroupCreatedTime(rg.Name)
-			results[i] = ResourceGroupResult{
-				ResourceGroup: rg,
-				CreatedTime:   createdTime,
-				Error:         err,
-			}
-		}(i, rg)
+	if parent := resource.ParentName(); parent != "" {
+		fmt.Printf("      Parent: %s\n", parent)
 	}
-
-	// Wait for all workers to complete
-	wg.Wait()
-
-	// Stop spinner if it was started
-	if spinner != nil {
-		spinner.Stop()
-	}
-
-	// Convert results to CSV format
-	csvData := make([]CSVRow, 0, len(results))
-	for _, result := range results {
-		csvRow := ac.convertToCSVRow(result, false, nil)
-		csvData = append(csvData, csvRow)
-		// Also print to console
-		ac.printResourceGroupResult(result, false)
-	}
-
-	return csvData
 }
 
-// processResourceGroupsConcurrentlyWithResourcesCSV processes resource groups with resources and returns CSV data
-func (ac *AzureClient) processResourceGroupsConcurrentlyWithResourcesCSV(resourceGroups []ResourceGroup) []CSVRow {
-	csvData := make([]CSVRow, 0, len(resourceGroups))
-
-	// Start spinner if not in porcelain mode
-	var spinner *Spinner
-	if !ac.Config.Porcelain {
-		spinner = NewSpinner("Processing resource groups with resources for CSV...")
-		spinner.Start()
-	}
-
-	for _, rg := range resourceGroups {
-		// Fetch resources for this resource group
-		resources, err := ac.fetchResourcesInGroup(rg.Name)
-		if err != nil {
-			// Create a result with error
-			result := ResourceGroupResult{
-				ResourceGroup: rg,
-				CreatedTime:   nil,
-				Error:         err,
-			}
-			csvRow := ac.convertToCSVRow(result, true, nil)
-			csvData = append(csvData, csvRow)
-			ac.printResourceGroupResult(result, true)
-			continue
-		}
-
-		// Create result with resources
-		result := ResourceGroupResult{
-			ResourceGroup: rg,
-			CreatedTime:   nil, // Will be calculated from resources
-			Error:         nil,
-		}
-		csvRow := ac.convertToCSVRow(result, true, resources)
-		csvData = append(csvData, csvRow)
-		ac.printResourceGroupResultWithResources(result, resources)
-	}
-
-	// Stop spinner if it was started
-	if spinner != nil {
-		spinner.Stop()
-	}
-
-	return csvData
+// CSV Row structure for output
+type CSVRow struct {
+	ResourceGroupName     string
+	ResourceGroupID       string
+	Location              string
+	ProvisioningState     string
+	CreatedTime           string
+	IsDefault             string
+	CreatedBy             string
+	Description           string
+	Resources             string
+	Protected             string
+	ProtectionReason      string
+	Locks                 string
+	LastModified          string
+	DaysSinceChange       string
+	InferredOwners        string
+	RoleAssignments       string
+	Cost                  string
+	CostCurrency          string
+	NonCompliantResources string
+	NonCompliantPolicies  string
+	TagGapResources       string
+	TagGaps               string
 }
 
 // fetchResourcesInGroup fetches resources in a resource group and returns them
 func (ac *AzureClient) fetchResourcesInGroup(resourceGroupName string) ([]Resource, error) {
-	url := fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/resources?$expand=createdTime&api-version=2019-10-01",
-		ac.Config.SubscriptionID, resourceGroupName)
+	url := ac.resourcesInGroupURL(resourceGroupName)
 
-	resp, err := ac.makeAzureRequest(url)
+	// Failed requests come back as *AzureAPIError, like the other Azure lookups
+	resp, err := ac.doAzureRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch resources: %w", err)
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Warning: failed to close response body: %v", err)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
 
 	var resourcesResponse ResourcesResponse
-	if err := json.Unmarshal(body, &resourcesResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodeAzureResponse(resp, &resourcesResponse); err != nil {
+		return nil, err
 	}
 
 	return resourcesResponse.Value, nil
@@ -818,41 +1296,62 @@ func (ac *AzureClient) convertToCSVRow(result ResourceGroupResult, listResources
 	defaultInfo := checkIfDefaultResourceGroup(rg.Name)
 
 	// Format created time
-	createdTimeStr := ""
-	if result.Error != nil {
-		createdTimeStr = "Error: " + result.Error.Error()
-	} else if result.CreatedTime != nil {
-		createdTimeStr = result.CreatedTime.Format(time.RFC3339)
-	} else {
-		createdTimeStr = "Not available"
-	}
+	createdTimeStr := columnCreatedTime(result, columnStyleCSV)
 
 	// Format resources as a single field if listResources is true
 	resourcesStr := ""
 	if listResources && resources != nil {
-		resourcesList := make([]string, 0, len(resources))
-		for _, resource := range resources {
-			resourceInfo := fmt.Sprintf("%s (%s)", resource.Name, resource.Type)
-			if resource.CreatedTime != nil {
-				resourceInfo += " - Created: " + resource.CreatedTime.Format(time.RFC3339)
-			} else {
-				resourceInfo += " - Created: Not available"
-			}
-			resourcesList = append(resourcesList, resourceInfo)
-		}
-		resourcesStr = strings.Join(resourcesList, "; ")
+		resourcesStr = formatResourcesCell(resources)
+	}
+
+	// Format locks, keeping lookup failures visible
+	locksStr := formatLocks(result.Locks)
+	if result.LocksError != nil {
+		locksStr = "Error: " + result.LocksError.Error()
 	}
 
 	return CSVRow{
-		ResourceGroupName: rg.Name,
-		Location:          rg.Location,
-		ProvisioningState: rg.Properties.ProvisioningState,
-		CreatedTime:       createdTimeStr,
-		IsDefault:         fmt.Sprintf("%v", defaultInfo.IsDefault),
-		CreatedBy:         defaultInfo.CreatedBy,
-		Description:       defaultInfo.Description,
-		Resources:         resourcesStr,
+		ResourceGroupName:     rg.Name,
+		ResourceGroupID:       rg.ID,
+		Location:              rg.Location,
+		ProvisioningState:     rg.Properties.ProvisioningState,
+		CreatedTime:           createdTimeStr,
+		IsDefault:             fmt.Sprintf("%v", defaultInfo.IsDefault),
+		CreatedBy:             defaultInfo.CreatedBy,
+		Description:           defaultInfo.Description,
+		Resources:             resourcesStr,
+		Protected:             fmt.Sprintf("%v", result.Protected),
+		ProtectionReason:      result.ProtectionReason,
+		Locks:                 locksStr,
+		LastModified:          columnLastModified(result, columnStyleCSV),
+		DaysSinceChange:       columnDaysSinceChange(result, columnStyleCSV),
+		InferredOwners:        columnInferredOwners(result, columnStyleCSV),
+		RoleAssignments:       columnRoleAssignments(result, columnStyleCSV),
+		Cost:                  columnCost(result, columnStyleCSV),
+		CostCurrency:          result.CostCurrency,
+		NonCompliantResources: columnNonCompliantResources(result, columnStyleCSV),
+		NonCompliantPolicies:  columnNonCompliantPolicies(result, columnStyleCSV),
+		TagGapResources:       columnTagGapResources(result, columnStyleCSV),
+		TagGaps:               columnTagGaps(result, columnStyleCSV),
+	}
+}
+
+// formatResourcesCell renders resources as a single CSV field, one "name (type) - Created: ..." entry per resource
+func formatResourcesCell(resources []Resource) string {
+	resourcesList := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		resourceInfo := fmt.Sprintf("%s (%s)", resource.Name, resource.Type)
+		if resource.CreatedTime != nil {
+			resourceInfo += " - Created: " + resource.CreatedTime.Format(time.RFC3339)
+		} else {
+			resourceInfo += " - Created: Not available"
+		}
+		if parent := resource.ParentName(); parent != "" {
+			resourceInfo += " - Parent: " + parent
+		}
+		resourcesList = append(resourcesList, resourceInfo)
 	}
+	return strings.Join(resourcesList, "; ")
 }
 
 // printResourceGroupResultWithResources prints a resource group result with resources
@@ -862,39 +1361,30 @@ func (ac *AzureClient) printResourceGroupResultWithResources(result ResourceGrou
 	// Check if this is a default resource group
 	defaultInfo := checkIfDefaultResourceGroup(rg.Name)
 
-	if ac.Config.Porcelain {
-		// For porcelain mode, we need to get creation time from resources
-		createdTime := ""
-		if len(resources) > 0 {
-			// Find the earliest created time among all resources
-			var earliestTime *time.Time
-			for _, resource := range resources {
-				if resource.CreatedTime != nil {
-					if earliestTime == nil || resource.CreatedTime.Before(*earliestTime) {
-						earliestTime = resource.CreatedTime
-					}
-				}
-			}
-			if earliestTime != nil {
-				createdTime = earliestTime.Format(time.RFC3339)
-			} else {
-				createdTime = "N/A"
-			}
-		} else {
-			createdTime = "N/A"
-		}
+	if ac.Config.Porcelain && ac.Columns != nil {
+		fmt.Println(strings.Join(columnValues(ac.Columns, result, columnStylePorcelain), "\t"))
+	} else if ac.Config.Porcelain {
+		// Use the resolved creation time so every output agrees on the source chain
+		createdTime := columnCreatedTime(result, columnStylePorcelain)
 
 		isDefault := "false"
 		if defaultInfo.IsDefault {
 			isDefault = "true"
 		}
 
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\n",
+		fields := append([]string{
 			rg.Name,
 			rg.Location,
 			rg.Properties.ProvisioningState,
 			createdTime,
-			isDefault)
+			isDefault,
+		}, ac.protectionValues(result)...)
+		fields = append(fields, ac.lastModifiedValues(result)...)
+		fields = append(fields, ac.ownershipValues(result)...)
+		fields = append(fields, ac.policyValues(result)...)
+		fields = append(fields, ac.costValues(result)...)
+		fields = append(fields, ac.tagInheritanceValues(result)...)
+		writePorcelainRow(os.Stdout, fields...)
 	} else {
 		// Human-readable format
 		fmt.Printf("Resource Group: %s\n", rg.Name)
@@ -907,20 +1397,16 @@ func (ac *AzureClient) printResourceGroupResultWithResources(result ResourceGrou
 			fmt.Printf("  📝 Description: %s\n", defaultInfo.Description)
 		}
 
+		ac.printProtectionDetails(result)
+		ac.printCreationDetails(result)
+		ac.printLastModified(result)
+		ac.printOwnership(result)
+		ac.printPolicyCompliance(result)
+		ac.printCost(result)
+		ac.printTagInheritance(result)
+
 		// Print resources
-		if len(resources) == 0 {
-			fmt.Printf("  No resources found in this resource group\n")
-		} else {
-			fmt.Printf("  Resources (%d):\n", len(resources))
-			for _, resource := range resources {
-				fmt.Printf("    - %s (%s)\n", resource.Name, resource.Type)
-				if resource.CreatedTime != nil {
-					fmt.Printf("      Created: %s\n", resource.CreatedTime.Format(time.RFC3339))
-				} else {
-					fmt.Printf("      Created: Not available\n")
-				}
-			}
-		}
+		printResources(resources, result.Error)
 
 		fmt.Println()
 	}
@@ -957,6 +1443,30 @@ func (ac *AzureClient) writeCSVFile(csvData []CSVRow) error {
 		"Description",
 		"Resources",
 	}
+	if ac.Protection != nil {
+		header = append(header, "Protected", "ProtectionReason")
+	}
+	if ac.Config.CheckLocks {
+		header = append(header, "Locks")
+	}
+	if ac.Config.LastModified {
+		header = append(header, "LastModified", "DaysSinceChange")
+	}
+	if ac.Config.RoleAssignments {
+		header = append(header, "InferredOwners", "RoleAssignments")
+	}
+	if ac.Config.PolicyCompliance {
+		header = append(header, "NonCompliantResources", "NonCompliantPolicies")
+	}
+	if ac.Config.Cost {
+		header = append(header, "Cost", "CostCurrency")
+	}
+	if ac.Config.TagInheritance {
+		header = append(header, "TagGapResources", "TagGaps")
+	}
+	if ac.Config.OutputResourcesCSV != "" {
+		header = append(header, "ResourceGroupID")
+	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
@@ -973,6 +1483,30 @@ func (ac *AzureClient) writeCSVFile(csvData []CSVRow) error {
 			row.Description,
 			row.Resources,
 		}
+		if ac.Protection != nil {
+			record = append(record, row.Protected, row.ProtectionReason)
+		}
+		if ac.Config.CheckLocks {
+			record = append(record, row.Locks)
+		}
+		if ac.Config.LastModified {
+			record = append(record, row.LastModified, row.DaysSinceChange)
+		}
+		if ac.Config.RoleAssignments {
+			record = append(record, row.InferredOwners, row.RoleAssignments)
+		}
+		if ac.Config.PolicyCompliance {
+			record = append(record, row.NonCompliantResources, row.NonCompliantPolicies)
+		}
+		if ac.Config.Cost {
+			record = append(record, row.Cost, row.CostCurrency)
+		}
+		if ac.Config.TagInheritance {
+			record = append(record, row.TagGapResources, row.TagGaps)
+		}
+		if ac.Config.OutputResourcesCSV != "" {
+			record = append(record, row.ResourceGroupID)
+		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
@@ -981,6 +1515,25 @@ func (ac *AzureClient) writeCSVFile(csvData []CSVRow) error {
 	return nil
 }
 
+// writeCSVRecords writes a header record followed by data records to a CSV file
+func writeCSVRecords(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("Warning: failed to close CSV file: %v", err)
+		}
+	}()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV records: %w", err)
+	}
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
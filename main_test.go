@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -1431,7 +1432,8 @@ func TestPrintResourceGroupResultWithResources_Porcelain(t *testing.T) {
 	rg := ResourceGroup{Name: "my-rg", Location: "eastus", Properties: struct {
 		ProvisioningState string `json:"provisioningState"`
 	}{ProvisioningState: "Succeeded"}}
-	result := ResourceGroupResult{ResourceGroup: rg}
+	// The creation time is resolved before printing (here from the resources, the default chain)
+	result := ResourceGroupResult{ResourceGroup: rg, CreatedTime: earliestCreatedTime(resources)}
 
 	old := os.Stdout
 	r, w, pipeErr := os.Pipe()
@@ -1493,3 +1495,58 @@ func TestPrintResourceGroupResultWithResources_Human(t *testing.T) {
 		t.Errorf("expected no resources message, got:\n%s", output)
 	}
 }
+
+func TestPrintResourceGroupResultListsFetchedResources(t *testing.T) {
+	// Printing must not list the resources again: they were fetched with the creation time
+	ac := &AzureClient{Config: Config{Porcelain: false}, HTTPClient: &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request %s", req.URL)
+			return nil, fmt.Errorf("unexpected request")
+		},
+	}}
+
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := ResourceGroupResult{
+		ResourceGroup: ResourceGroup{Name: "my-rg", Location: "eastus"},
+		Resources:     []Resource{{Name: "vm1", Type: "Microsoft.Compute/virtualMachines", CreatedTime: &created}},
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	ac.printResourceGroupResult(result, true)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe writer: %v", err)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.Contains(buf.String(), "  Resources (1):\n    - vm1 (Microsoft.Compute/virtualMachines)\n      Created: 2023-01-01T00:00:00Z\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestFetchResourcesInGroupReturnsAzureAPIError(t *testing.T) {
+	ac := &AzureClient{Config: Config{SubscriptionID: "sub", AccessToken: "token"}, HTTPClient: &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(`{"error": "denied"}`))}, nil
+		},
+	}}
+
+	_, err := ac.fetchResourcesInGroup("my-rg")
+	var apiErr *AzureAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected an AzureAPIError with status 403, got %v", err)
+	}
+	if _, err := ac.fetchResourceGroupCreatedTime("my-rg"); !errors.As(err, &apiErr) {
+		t.Errorf("expected an AzureAPIError from the creation time lookup, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Markdown output format for --output
+const outputMarkdown = "markdown"
+
+// Section groupings for --markdown-group-by
+const (
+	markdownGroupByNone     = "none"
+	markdownGroupByLocation = "location"
+	markdownGroupByCategory = "category"
+)
+
+// validateMarkdownGroupBy checks a --markdown-group-by value
+func validateMarkdownGroupBy(groupBy string) error {
+	switch groupBy {
+	case markdownGroupByNone, markdownGroupByLocation, markdownGroupByCategory:
+		return nil
+	}
+	return fmt.Errorf("unsupported Markdown grouping %q (supported: none, location, category)", groupBy)
+}
+
+// renderMarkdown writes a GitHub-flavoured Markdown report: a summary followed by a table of
+// resource groups, optionally split into sections by location or default category. Table cells
+// come from the same columns as the CSV output (the --columns selection, or the fixed CSV
+// columns), and with --list-resources each group's resources follow in a <details> block.
+func (ac *AzureClient) renderMarkdown(w io.Writer, results []ResourceGroupResult, listResources bool) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# Resource group inventory\n\n")
+	fmt.Fprintf(bw, "Subscription `%s`, generated %s\n\n", ac.Config.SubscriptionID, inventoryNow().UTC().Format("2006-01-02 15:04 UTC"))
+	writeMarkdownSummary(bw, buildSummary(results, 0), ac.Config.CheckLocks)
+
+	columns := ac.csvGroupColumns()
+	switch ac.Config.MarkdownGroupBy {
+	case markdownGroupByLocation:
+		ac.writeMarkdownSections(bw, results, columns, listResources, func(r ResourceGroupResult) string { return r.ResourceGroup.Location })
+	case markdownGroupByCategory:
+		ac.writeMarkdownSections(bw, results, columns, listResources, func(r ResourceGroupResult) string { return groupCategory(r.ResourceGroup.Name) })
+	default:
+		fmt.Fprintf(bw, "## Resource groups\n\n")
+		writeMarkdownGroups(bw, results, columns, listResources)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write Markdown: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownSummary writes the counts of the text summary, so both report the same numbers
+func writeMarkdownSummary(w io.Writer, summary InventorySummary, checkLocks bool) {
+	userCreated := 0
+	for _, category := range summary.Categories {
+		if category.Label == userCategory {
+			userCreated = category.Count
+		}
+	}
+
+	rows := [][]string{
+		{"Resource groups", strconv.Itoa(summary.ResourceGroups)},
+		{"Created by Azure", strconv.Itoa(summary.ResourceGroups - userCreated)},
+		{userCategory, strconv.Itoa(userCreated)},
+		{"Resources", strconv.Itoa(summary.Resources)},
+	}
+	for _, row := range summary.Errors.rows(checkLocks) {
+		rows = append(rows, []string{row.Label, strconv.Itoa(row.Count)})
+	}
+
+	fmt.Fprintf(w, "## Summary\n\n")
+	writeMarkdownTable(w, []string{"", "Count"}, rows)
+
+	fmt.Fprintf(w, "**By location:** %s\n\n", formatLabelCounts(summary.Locations))
+	fmt.Fprintf(w, "**By age:** %s\n\n", formatLabelCounts(summary.Ages))
+}
+
+// writeMarkdownSections writes one section per label, largest first
+func (ac *AzureClient) writeMarkdownSections(w io.Writer, results []ResourceGroupResult, columns []Column, listResources bool, label func(ResourceGroupResult) string) {
+	for _, section := range countBy(results, label) {
+		var sectionResults []ResourceGroupResult
+		for _, result := range results {
+			if label(result) == section.Label {
+				sectionResults = append(sectionResults, result)
+			}
+		}
+		title := section.Label
+		if title == "" {
+			title = "(none)"
+		}
+		fmt.Fprintf(w, "## %s (%d)\n\n", escapeMarkdown(title), section.Count)
+		writeMarkdownGroups(w, sectionResults, columns, listResources)
+	}
+}
+
+func writeMarkdownGroups(w io.Writer, results []ResourceGroupResult, columns []Column, listResources bool) {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, columnValues(columns, result, columnStyleCSV))
+	}
+	writeMarkdownTable(w, columnHeaders(columns, columnStyleCSV), rows)
+
+	if !listResources {
+		return
+	}
+	for _, result := range results {
+		if len(result.Resources) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "<details>\n<summary>%s (%d resources)</summary>\n\n", escapeMarkdown(result.ResourceGroup.Name), len(result.Resources))
+		resourceRows := make([][]string, 0, len(result.Resources))
+		for _, resource := range result.Resources {
+			created := "Not available"
+			if resource.CreatedTime != nil {
+				created = resource.CreatedTime.Format(time.RFC3339)
+			}
+			resourceRows = append(resourceRows, []string{resource.Name, resource.Type, created})
+		}
+		writeMarkdownTable(w, []string{"Name", "Type", "Created"}, resourceRows)
+		fmt.Fprintf(w, "</details>\n\n")
+	}
+}
+
+// writeMarkdownTable writes a GFM table followed by a blank line
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) {
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(w, header)
+	writeMarkdownRow(w, separators)
+	for _, row := range rows {
+		writeMarkdownRow(w, row)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeMarkdownRow(w io.Writer, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeMarkdown(cell)
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+// markdownEscaper keeps cell text from breaking tables or being read as HTML. Backslashes are
+// escaped too so a trailing one cannot swallow the escape of a following pipe.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\r", "<br>", "\n", "<br>", "<", "&lt;", ">", "&gt;")
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// formatLabelCounts renders a breakdown inline, e.g. "eastus (3), westus (1)"
+func formatLabelCounts(counts []LabelCount) string {
+	parts := make([]string, 0, len(counts))
+	for _, count := range counts {
+		label := count.Label
+		if label == "" {
+			label = "(none)"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", escapeMarkdown(label), count.Count))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
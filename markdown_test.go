@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	results := outputTestResults()
+	results[0].ResourceGroup.Tags["note"] = "a|b"
+	columns, err := ParseColumns("name,location,created_time,tags.note")
+	if err != nil {
+		t.Fatalf("failed to parse columns: %v", err)
+	}
+	client := &AzureClient{
+		Config:  Config{SubscriptionID: "test-subscription", OutputFormat: outputMarkdown},
+		Columns: columns,
+	}
+
+	var buf bytes.Buffer
+	if err := client.renderInventory(&buf, results, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		"Subscription `test-subscription`, generated 2023-03-01 12:00 UTC\n",
+		"| Resource groups | 2 |\n",
+		"| Created by Azure | 1 |\n",
+		"**By location:** eastus (1), westus (1)\n",
+		"**By age:** &lt; 30 days (0), 30-90 days (1), 90-365 days (0), &gt; 1 year (0), Unknown (1)\n",
+		"## Resource groups\n\n| ResourceGroupName | Location | CreatedTime | tags.note |\n| --- | --- | --- | --- |\n",
+		"| sandbox-alice | eastus | 2023-01-01T12:00:00Z | a\\|b |\n",
+		"| NetworkWatcherRG | westus | Not available |  |\n",
+		"<details>\n<summary>sandbox-alice (1 resources)</summary>\n\n| Name | Type | Created |\n",
+		"| db/main | Microsoft.Sql/servers/databases | 2023-01-01T12:00:00Z |\n\n</details>\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "NetworkWatcherRG (0 resources)") {
+		t.Error("Expected no <details> block for a group without resources")
+	}
+}
+
+func TestRenderMarkdownGroupedByCategory(t *testing.T) {
+	client := &AzureClient{Config: Config{OutputFormat: outputMarkdown, MarkdownGroupBy: markdownGroupByCategory}}
+
+	var buf bytes.Buffer
+	if err := client.renderMarkdown(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	output := buf.String()
+
+	// Without --columns the table has the fixed CSV group columns
+	header := "| ResourceGroupName | Location | ProvisioningState | CreatedTime | IsDefault | CreatedBy | Description |"
+	if strings.Count(output, header) != 2 {
+		t.Errorf("Expected the CSV header in each section:\n%s", output)
+	}
+	azure := strings.Index(output, "## Azure Network Watcher (1)")
+	user := strings.Index(output, "## User-created (1)")
+	if azure < 0 || user < 0 {
+		t.Fatalf("Expected a section per category:\n%s", output)
+	}
+	// Sections are ordered by size, then name, so NetworkWatcherRG sits between the two headings
+	if watcher := strings.Index(output, "| NetworkWatcherRG |"); watcher < azure || watcher > user {
+		t.Errorf("Expected NetworkWatcherRG in its category section:\n%s", output)
+	}
+	if strings.Contains(output, "<details>") {
+		t.Error("Expected no resource details without --list-resources")
+	}
+}
+
+func TestValidateMarkdownGroupBy(t *testing.T) {
+	for _, groupBy := range []string{markdownGroupByNone, markdownGroupByLocation, markdownGroupByCategory} {
+		if err := validateMarkdownGroupBy(groupBy); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", groupBy, err)
+		}
+	}
+	if err := validateMarkdownGroupBy("owner"); err == nil {
+		t.Error("Expected error for unknown grouping")
+	}
+	if err := validateOutputFormat(outputMarkdown); err != nil {
+		t.Errorf("Expected markdown to be a valid output format, got %v", err)
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	for text, want := range map[string]string{
+		`a|b`:           `a\|b`,
+		`trailing\|`:    `trailing\\\|`,
+		"one\r\ntwo\n3": "one<br>two<br>3",
+		"old\rmac":      "old<br>mac",
+		"<b>":           "&lt;b&gt;",
+	} {
+		if got := escapeMarkdown(text); got != want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
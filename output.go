@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jmespath/go-jmespath"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured output formats for --output
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// outputRenderers render the (possibly queried) generic inventory in each structured format
+var outputRenderers = map[string]func(w io.Writer, data interface{}, query string) error{
+	outputJSON:  renderJSON,
+	outputYAML:  renderYAML,
+	outputTable: renderTable,
+}
+
+// tableColumnOrder puts the most useful inventory fields first in table output
+var tableColumnOrder = []string{"name", "location", "provisioningState", "createdTime"}
+
+// multiselectKeyPattern finds the keys of a JMESPath multiselect hash such as {Name: name, Loc: location}
+var multiselectKeyPattern = regexp.MustCompile(`[{,]\s*([A-Za-z_][A-Za-z0-9_]*|"[^"]*")\s*:`)
+
+// validateOutputFormat checks an --output value; "" selects the classic human/porcelain output
+func validateOutputFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	if _, ok := outputRenderers[format]; !ok && format != outputMarkdown {
+		return fmt.Errorf("unsupported output format %q (supported: json, yaml, table, markdown)", format)
+	}
+	return nil
+}
+
+// compileQuery compiles a --query JMESPath expression; an empty expression returns nil
+func compileQuery(expr string) (*jmespath.JMESPath, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	query, err := jmespath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --query expression: %w", err)
+	}
+	return query, nil
+}
+
+// humanOutput reports whether the classic human-readable output (with progress messages
+// and the spinner) is in use; porcelain, structured and template output keep stdout clean
+func (ac *AzureClient) humanOutput() bool {
+	return !ac.Config.Porcelain && ac.Config.OutputFormat == "" && ac.Template == nil
+}
+
+// renderInventory writes results in the configured structured format, after applying --query
+func (ac *AzureClient) renderInventory(w io.Writer, results []ResourceGroupResult, listResources bool) error {
+	// Markdown is rendered from the results, like the CSV output, rather than the generic inventory
+	if ac.Config.OutputFormat == outputMarkdown {
+		return ac.renderMarkdown(w, results, listResources)
+	}
+
+	// --columns picks the table columns unless a query reshapes the data
+	if ac.Config.OutputFormat == outputTable && ac.Columns != nil && ac.Query == nil {
+		return ac.renderColumnsTable(w, results)
+	}
+
+	data, err := toGenericJSON(ac.buildInventory(results, listResources))
+	if err != nil {
+		return err
+	}
+
+	if ac.Query != nil {
+		data, err = ac.Query.Search(data)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate --query: %w", err)
+		}
+	}
+
+	render, ok := outputRenderers[ac.Config.OutputFormat]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", ac.Config.OutputFormat)
+	}
+	return render(w, data, ac.Config.Query)
+}
+
+func renderJSON(w io.Writer, data interface{}, _ string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func renderYAML(w io.Writer, data interface{}, _ string) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return encoder.Close()
+}
+
+// renderTable prints scalar fields as aligned columns, like the az CLI table output. Nested
+// objects and lists are left out; project them with --query to show them. A list of objects
+// becomes one row per object, a single object one row, and scalars a single "Result" column.
+func renderTable(w io.Writer, data interface{}, query string) error {
+	var rows []map[string]interface{}
+	switch value := data.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, item := range value {
+			if row, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, row)
+			} else {
+				rows = append(rows, map[string]interface{}{"Result": item})
+			}
+		}
+	case map[string]interface{}:
+		rows = append(rows, value)
+	default:
+		rows = append(rows, map[string]interface{}{"Result": value})
+	}
+
+	columns := tableColumns(rows, query)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	separators := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = tableHeader(column)
+		separators[i] = strings.Repeat("-", len(headers[i]))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	fmt.Fprintln(tw, strings.Join(separators, "\t"))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = formatTableCell(row[column])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
+
+// tableColumns returns the scalar keys of the rows: keys named in a multiselect hash of the
+// query come first in query order, then the preferred inventory fields, then the rest sorted
+func tableColumns(rows []map[string]interface{}, query string) []string {
+	scalar := make(map[string]bool)
+	for _, row := range rows {
+		for key, value := range row {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				continue
+			}
+			scalar[key] = true
+		}
+	}
+
+	var columns []string
+	added := make(map[string]bool)
+	add := func(key string) {
+		if scalar[key] && !added[key] {
+			added[key] = true
+			columns = append(columns, key)
+		}
+	}
+
+	for _, match := range multiselectKeyPattern.FindAllStringSubmatch(query, -1) {
+		add(strings.Trim(match[1], `"`))
+	}
+	for _, key := range tableColumnOrder {
+		add(key)
+	}
+
+	remaining := make([]string, 0, len(scalar))
+	for key := range scalar {
+		if !added[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		add(key)
+	}
+	return columns
+}
+
+// tableHeader turns a camelCase key into a header, e.g. provisioningState -> ProvisioningState
+func tableHeader(key string) string {
+	if key == "" {
+		return key
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
+
+func formatTableCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
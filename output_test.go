@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func outputTestResults() []ResourceGroupResult {
+	created := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	alice := ResourceGroup{ID: "/subscriptions/test/resourceGroups/sandbox-alice", Name: "sandbox-alice", Location: "eastus", Tags: map[string]string{"owner": "alice"}}
+	alice.Properties.ProvisioningState = "Succeeded"
+	watcher := ResourceGroup{ID: "/subscriptions/test/resourceGroups/NetworkWatcherRG", Name: "NetworkWatcherRG", Location: "westus"}
+	watcher.Properties.ProvisioningState = "Succeeded"
+
+	return []ResourceGroupResult{
+		{
+			ResourceGroup: alice,
+			CreatedTime:   &created,
+			Resources: []Resource{{
+				ID:          "/subscriptions/test/resourceGroups/sandbox-alice/providers/Microsoft.Sql/servers/db/databases/main",
+				Name:        "db/main",
+				Type:        "Microsoft.Sql/servers/databases",
+				CreatedTime: &created,
+			}},
+		},
+		{ResourceGroup: watcher},
+	}
+}
+
+func TestRenderInventoryJSONWithQuery(t *testing.T) {
+	query, err := compileQuery("[?detection.isDefault].{Name: name, CreatedBy: detection.createdBy}")
+	if err != nil {
+		t.Fatalf("failed to compile query: %v", err)
+	}
+	client := &AzureClient{Config: Config{OutputFormat: outputJSON}, Query: query}
+
+	var buf bytes.Buffer
+	if err := client.renderInventory(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0]["Name"] != "NetworkWatcherRG" || got[0]["CreatedBy"] != "Azure Network Watcher" {
+		t.Errorf("unexpected query result %v", got)
+	}
+}
+
+func TestRenderInventoryNestedResources(t *testing.T) {
+	query, err := compileQuery("[].resources[].{name: name, provider: provider, parent: parent}")
+	if err != nil {
+		t.Fatalf("failed to compile query: %v", err)
+	}
+	client := &AzureClient{Config: Config{OutputFormat: outputYAML}, Query: query}
+
+	var buf bytes.Buffer
+	if err := client.renderInventory(&buf, outputTestResults(), true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got []map[string]string
+	if err := yaml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid YAML, got %v:\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0]["provider"] != "Microsoft.Sql" || got[0]["parent"] != "db" {
+		t.Errorf("unexpected nested resources %v", got)
+	}
+}
+
+func TestRenderInventoryTable(t *testing.T) {
+	client := &AzureClient{Config: Config{OutputFormat: outputTable}}
+
+	var buf bytes.Buffer
+	if err := client.renderInventory(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected header, separator and 2 rows, got:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "Name") || !strings.Contains(lines[0], "ProvisioningState") {
+		t.Errorf("Expected preferred columns first, got header %q", lines[0])
+	}
+	if strings.Contains(lines[0], "Tags") || strings.Contains(lines[0], "Detection") {
+		t.Errorf("Expected nested fields to be left out of the table, got header %q", lines[0])
+	}
+
+	query, _ := compileQuery("[].{Rg: name, Loc: location}")
+	client.Query = query
+	client.Config.Query = "[].{Rg: name, Loc: location}"
+	buf.Reset()
+	if err := client.renderInventory(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fields := strings.Fields(strings.Split(buf.String(), "\n")[0]); len(fields) != 2 || fields[0] != "Rg" || fields[1] != "Loc" {
+		t.Errorf("Expected columns in query order, got %q", fields)
+	}
+
+	// Scalar results are shown in a single column
+	query, _ = compileQuery("length(@)")
+	client.Query = query
+	client.Config.Query = "length(@)"
+	buf.Reset()
+	if err := client.renderInventory(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Result") || !strings.Contains(buf.String(), "2") {
+		t.Errorf("unexpected scalar table:\n%s", buf.String())
+	}
+}
+
+func TestOutputValidation(t *testing.T) {
+	if err := validateOutputFormat("xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	for _, format := range []string{"", outputJSON, outputYAML, outputTable} {
+		if err := validateOutputFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", format, err)
+		}
+	}
+	if _, err := compileQuery("[?name=="); err == nil {
+		t.Error("Expected error for invalid query")
+	}
+	if query, err := compileQuery(""); query != nil || err != nil {
+		t.Errorf("Expected no query for empty expression, got %v, %v", query, err)
+	}
+}
+
+func TestFetchResourceGroupsStructuredOutput(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/resources") {
+				return jsonResponse(http.StatusOK, `{"value": [{"name": "res", "type": "Microsoft.Web/sites", "createdTime": "2023-01-01T12:00:00Z"}]}`), nil
+			}
+			return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+		},
+	}
+
+	query, err := compileQuery("[?location=='eastus'].name")
+	if err != nil {
+		t.Fatalf("failed to compile query: %v", err)
+	}
+	client := &AzureClient{
+		Config: Config{
+			SubscriptionID: "test-subscription",
+			AccessToken:    "test-token",
+			MaxConcurrency: 2,
+			OutputFormat:   outputJSON,
+		},
+		HTTPClient: mockClient,
+		Query:      query,
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	err = client.FetchResourceGroups()
+
+	if closeErr := w.Close(); closeErr != nil {
+		t.Errorf("Failed to close pipe writer: %v", closeErr)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Errorf("Failed to copy output: %v", copyErr)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Stdout must contain only the rendered document, without progress messages
+	var names []string
+	if err := json.Unmarshal(buf.Bytes(), &names); err != nil {
+		t.Fatalf("Expected stdout to be valid JSON, got %v:\n%s", err, buf.String())
+	}
+	if strings.Join(names, ",") != "sandbox-alice,prod-app,NetworkWatcherRG" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultGraphEndpoint is the Microsoft Graph endpoint used to resolve principals
+const defaultGraphEndpoint = "https://graph.microsoft.com"
+
+// graphGetByIDsLimit is the number of IDs Microsoft Graph accepts in one getByIds call
+const graphGetByIDsLimit = 1000
+
+// ownershipRoles maps the built-in role definition IDs that imply ownership to their names
+var ownershipRoles = map[string]string{
+	"8e3af657-a8ff-443c-a75c-2fe8c4bcb635": "Owner",
+	"b24988ac-6180-42a0-ab88-20f7382dd24c": "Contributor",
+}
+
+// ownershipRoleOrder ranks the roles, strongest first
+var ownershipRoleOrder = []string{"Owner", "Contributor"}
+
+// RoleAssignment is an Owner or Contributor assignment made directly on a resource group. The
+// display name and user principal name are filled in when principals are resolved.
+type RoleAssignment struct {
+	PrincipalID       string
+	PrincipalType     string
+	Role              string
+	DisplayName       string
+	UserPrincipalName string
+}
+
+// RoleAssignmentsResponse is one page of role assignments
+type RoleAssignmentsResponse struct {
+	Value []struct {
+		Properties struct {
+			RoleDefinitionID string `json:"roleDefinitionId"`
+			PrincipalID      string `json:"principalId"`
+			PrincipalType    string `json:"principalType"`
+			Scope            string `json:"scope"`
+		} `json:"properties"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// fetchOwnershipRoleAssignments lists the Owner and Contributor assignments scoped directly to a
+// resource group; assignments inherited from the subscription or a management group are left
+// out because they say nothing about who owns this group
+func (ac *AzureClient) fetchOwnershipRoleAssignments(resourceGroupName string) ([]RoleAssignment, error) {
+	groupID := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName)
+	query := apiVersion("2022-04-01")
+	query.Set("$filter", "atScope()")
+	url := groupID.URL("providers/Microsoft.Authorization/roleAssignments", query)
+
+	var assignments []RoleAssignment
+	for url != "" {
+		resp, err := ac.makeAzureRequest(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch role assignments: %w", err)
+		}
+
+		var page RoleAssignmentsResponse
+		if err := decodeAzureResponse(resp, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Value {
+			props := item.Properties
+			if !strings.EqualFold(strings.TrimSuffix(props.Scope, "/"), groupID.String()) {
+				continue
+			}
+			role, ok := ownershipRoles[strings.ToLower(lastPathSegment(props.RoleDefinitionID))]
+			if !ok {
+				continue
+			}
+			assignments = append(assignments, RoleAssignment{PrincipalID: props.PrincipalID, PrincipalType: props.PrincipalType, Role: role})
+		}
+		url = page.NextLink
+	}
+
+	sortRoleAssignments(assignments)
+	return assignments, nil
+}
+
+// lastPathSegment returns the part of an ID after its last slash
+func lastPathSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// sortRoleAssignments orders assignments by role (Owner first), then principal ID
+func sortRoleAssignments(assignments []RoleAssignment) {
+	rank := func(role string) int {
+		for i, r := range ownershipRoleOrder {
+			if r == role {
+				return i
+			}
+		}
+		return len(ownershipRoleOrder)
+	}
+	sort.SliceStable(assignments, func(i, j int) bool {
+		if ri, rj := rank(assignments[i].Role), rank(assignments[j].Role); ri != rj {
+			return ri < rj
+		}
+		return assignments[i].PrincipalID < assignments[j].PrincipalID
+	})
+}
+
+// Principal is a directory object returned by Microsoft Graph
+type Principal struct {
+	ID                string `json:"id"`
+	ODataType         string `json:"@odata.type"`
+	DisplayName       string `json:"displayName"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// PrincipalResolver resolves principal IDs to names through a Graph-compatible endpoint. Results
+// are cached for the whole run, since the same people and pipelines own many groups.
+type PrincipalResolver struct {
+	Endpoint   string
+	Token      string
+	HTTPClient HTTPClient
+
+	mu    sync.Mutex
+	cache map[string]Principal
+}
+
+// NewPrincipalResolver creates a resolver for a Graph endpoint such as https://graph.microsoft.com
+func NewPrincipalResolver(endpoint, token string, client HTTPClient) (*PrincipalResolver, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Graph endpoint %q", endpoint)
+	}
+	return &PrincipalResolver{
+		Endpoint:   strings.TrimSuffix(endpoint, "/"),
+		Token:      token,
+		HTTPClient: client,
+		cache:      make(map[string]Principal),
+	}, nil
+}
+
+// Resolve fills in the display names of the assignments' principals. Principals that Graph does
+// not return (deleted or in another tenant) keep only their ID.
+func (r *PrincipalResolver) Resolve(assignments []RoleAssignment) error {
+	var missing []string
+	r.mu.Lock()
+	for _, assignment := range assignments {
+		if _, ok := r.cache[assignment.PrincipalID]; !ok && !containsString(missing, assignment.PrincipalID) {
+			missing = append(missing, assignment.PrincipalID)
+		}
+	}
+	r.mu.Unlock()
+
+	for start := 0; start < len(missing); start += graphGetByIDsLimit {
+		end := start + graphGetByIDsLimit
+		if end > len(missing) {
+			end = len(missing)
+		}
+		principals, err := r.getByIDs(missing[start:end])
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		for _, id := range missing[start:end] {
+			r.cache[id] = Principal{ID: id}
+		}
+		for _, principal := range principals {
+			r.cache[principal.ID] = principal
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range assignments {
+		principal := r.cache[assignments[i].PrincipalID]
+		assignments[i].DisplayName = principal.DisplayName
+		assignments[i].UserPrincipalName = principal.UserPrincipalName
+	}
+	return nil
+}
+
+// getByIDs calls POST /v1.0/directoryObjects/getByIds
+func (r *PrincipalResolver) getByIDs(ids []string) ([]Principal, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"ids":   ids,
+		"types": []string{"user", "group", "servicePrincipal"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode principal lookup: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.Endpoint+"/v1.0/directoryObjects/getByIds", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+r.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve principals: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := readResponseBody(resp)
+		return nil, fmt.Errorf("failed to resolve principals: Graph request failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var page struct {
+		Value []Principal `json:"value"`
+	}
+	if err := decodeAzureResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return page.Value, nil
+}
+
+// resolveOwnership looks up the group's ownership role assignments and, with a resolver,
+// the names of their principals
+func (ac *AzureClient) resolveOwnership(result *ResourceGroupResult) {
+	result.RoleAssignments, result.RoleAssignmentsError = ac.fetchOwnershipRoleAssignments(result.ResourceGroup.Name)
+	if result.RoleAssignmentsError != nil || ac.Principals == nil {
+		return
+	}
+	result.PrincipalsError = ac.Principals.Resolve(result.RoleAssignments)
+}
+
+// principalLabel names a principal: "Name <upn>" for users, the display name for groups and
+// service principals, or "<type> <id>" when it was not resolved
+func principalLabel(assignment RoleAssignment) string {
+	switch {
+	case assignment.DisplayName != "" && assignment.UserPrincipalName != "":
+		return fmt.Sprintf("%s <%s>", assignment.DisplayName, assignment.UserPrincipalName)
+	case assignment.DisplayName != "":
+		return assignment.DisplayName
+	case assignment.UserPrincipalName != "":
+		return assignment.UserPrincipalName
+	case assignment.PrincipalType != "":
+		return assignment.PrincipalType + " " + assignment.PrincipalID
+	default:
+		return assignment.PrincipalID
+	}
+}
+
+// inferredOwners lists each principal once with its strongest role, e.g. "Alice <alice@contoso.com> (Owner)"
+func inferredOwners(assignments []RoleAssignment) []string {
+	seen := make(map[string]bool, len(assignments))
+	owners := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		if seen[assignment.PrincipalID] {
+			continue
+		}
+		seen[assignment.PrincipalID] = true
+		owners = append(owners, fmt.Sprintf("%s (%s)", principalLabel(assignment), assignment.Role))
+	}
+	return owners
+}
+
+// formatRoleAssignments renders the raw assignments as "Role: Type ID" entries joined by "; "
+func formatRoleAssignments(assignments []RoleAssignment) string {
+	parts := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		parts = append(parts, fmt.Sprintf("%s: %s %s", assignment.Role, assignment.PrincipalType, assignment.PrincipalID))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func columnInferredOwners(r ResourceGroupResult, style columnStyle) string {
+	if r.RoleAssignmentsError != nil {
+		if style == columnStyleCSV {
+			return fmt.Sprintf("Error: %v", r.RoleAssignmentsError)
+		}
+		return "ERROR"
+	}
+	owners := inferredOwners(r.RoleAssignments)
+	if len(owners) == 0 && style != columnStyleCSV {
+		return "none"
+	}
+	return strings.Join(owners, "; ")
+}
+
+func columnRoleAssignments(r ResourceGroupResult, style columnStyle) string {
+	if r.RoleAssignmentsError != nil {
+		if style == columnStyleCSV {
+			return fmt.Sprintf("Error: %v", r.RoleAssignmentsError)
+		}
+		return "ERROR"
+	}
+	if len(r.RoleAssignments) == 0 && style != columnStyleCSV {
+		return "none"
+	}
+	return formatRoleAssignments(r.RoleAssignments)
+}
+
+// ownershipColumns returns the extra porcelain header column when --role-assignments is set
+func (ac *AzureClient) ownershipColumns() []string {
+	if !ac.Config.RoleAssignments {
+		return nil
+	}
+	return []string{"INFERRED_OWNERS"}
+}
+
+// ownershipValues returns the porcelain values matching ownershipColumns
+func (ac *AzureClient) ownershipValues(result ResourceGroupResult) []string {
+	if !ac.Config.RoleAssignments {
+		return nil
+	}
+	return []string{columnInferredOwners(result, columnStylePorcelain)}
+}
+
+// printOwnership prints the inferred owners in the human-readable output
+func (ac *AzureClient) printOwnership(result ResourceGroupResult) {
+	if !ac.Config.RoleAssignments {
+		return
+	}
+	switch {
+	case result.RoleAssignmentsError != nil:
+		fmt.Printf("  👤 Inferred Owners: Error fetching (%v)\n", result.RoleAssignmentsError)
+	case len(result.RoleAssignments) == 0:
+		fmt.Printf("  👤 Inferred Owners: none\n")
+	default:
+		fmt.Printf("  👤 Inferred Owners: %s\n", strings.Join(inferredOwners(result.RoleAssignments), "; "))
+	}
+	if result.PrincipalsError != nil {
+		fmt.Printf("  👤 Principal names: lookup failed (%v)\n", result.PrincipalsError)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testRoleAssignments = `{"value": [
+	{"properties": {"roleDefinitionId": "/subscriptions/test-subscription/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c",
+		"principalId": "2222", "principalType": "ServicePrincipal", "scope": "/subscriptions/test-subscription/resourceGroups/sandbox-alice"}},
+	{"properties": {"roleDefinitionId": "/subscriptions/test-subscription/providers/Microsoft.Authorization/roleDefinitions/8E3AF657-A8FF-443C-A75C-2FE8C4BCB635",
+		"principalId": "1111", "principalType": "User", "scope": "/subscriptions/test-subscription/resourcegroups/SANDBOX-ALICE"}},
+	{"properties": {"roleDefinitionId": "/subscriptions/test-subscription/providers/Microsoft.Authorization/roleDefinitions/8e3af657-a8ff-443c-a75c-2fe8c4bcb635",
+		"principalId": "9999", "principalType": "User", "scope": "/subscriptions/test-subscription"}},
+	{"properties": {"roleDefinitionId": "/subscriptions/test-subscription/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7",
+		"principalId": "3333", "principalType": "Group", "scope": "/subscriptions/test-subscription/resourceGroups/sandbox-alice"}}
+]}`
+
+func TestFetchOwnershipRoleAssignments(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/resourceGroups/sandbox-alice/providers/Microsoft.Authorization/roleAssignments") {
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+		if req.URL.Query().Get("$filter") != "atScope()" {
+			t.Errorf("expected atScope() filter, got %q", req.URL.RawQuery)
+		}
+		return jsonResponse(http.StatusOK, testRoleAssignments), nil
+	})
+
+	assignments, err := client.fetchOwnershipRoleAssignments("sandbox-alice")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Inherited and non-ownership assignments are dropped; Owner sorts first
+	if got := formatRoleAssignments(assignments); got != "Owner: User 1111; Contributor: ServicePrincipal 2222" {
+		t.Errorf("unexpected assignments %q", got)
+	}
+}
+
+func TestPrincipalResolver(t *testing.T) {
+	var calls int32
+	graph := &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		if req.Method != http.MethodPost || req.URL.String() != "http://localhost:8080/v1.0/directoryObjects/getByIds" {
+			t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		}
+		if req.Header.Get("Authorization") != "Bearer graph-token" {
+			t.Errorf("unexpected authorization %q", req.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(req.Body)
+		var lookup struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.Unmarshal(body, &lookup); err != nil {
+			t.Fatalf("invalid request body %s", body)
+		}
+		if strings.Join(lookup.IDs, ",") != "1111,2222" {
+			t.Errorf("unexpected ids %v", lookup.IDs)
+		}
+		return jsonResponse(http.StatusOK, `{"value": [{"id": "1111", "@odata.type": "#microsoft.graph.user", "displayName": "Alice", "userPrincipalName": "alice@contoso.com"}]}`), nil
+	}}
+
+	resolver, err := NewPrincipalResolver("http://localhost:8080/", "graph-token", graph)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	assignments := []RoleAssignment{
+		{PrincipalID: "1111", PrincipalType: "User", Role: "Owner"},
+		{PrincipalID: "2222", PrincipalType: "ServicePrincipal", Role: "Contributor"},
+		{PrincipalID: "1111", PrincipalType: "User", Role: "Contributor"},
+	}
+	if err := resolver.Resolve(assignments); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The cache answers the second lookup
+	if err := resolver.Resolve(assignments); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected one Graph call, got %d", calls)
+	}
+
+	owners := strings.Join(inferredOwners(assignments), "; ")
+	if owners != "Alice <alice@contoso.com> (Owner); ServicePrincipal 2222 (Contributor)" {
+		t.Errorf("unexpected owners %q", owners)
+	}
+
+	if _, err := NewPrincipalResolver("not a url", "", graph); err == nil {
+		t.Error("Expected error for an invalid endpoint")
+	}
+}
+
+func TestOwnershipOutputs(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/resourceGroups/broken/") {
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		}
+		return jsonResponse(http.StatusOK, testRoleAssignments), nil
+	})
+	client.Config.RoleAssignments = true
+	client.Principals, _ = NewPrincipalResolver("http://graph.test", "token", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusServiceUnavailable, `unavailable`), nil
+	}})
+
+	owned := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "sandbox-alice"}}
+	client.enrichResourceGroupResult(&owned)
+	if owned.RoleAssignmentsError != nil || len(owned.RoleAssignments) != 2 {
+		t.Fatalf("unexpected assignments %v (%v)", owned.RoleAssignments, owned.RoleAssignmentsError)
+	}
+	if owned.PrincipalsError == nil {
+		t.Error("expected the Graph failure to be reported")
+	}
+
+	broken := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "broken"}}
+	client.enrichResourceGroupResult(&broken)
+
+	if got := client.ownershipValues(owned); got[0] != "User 1111 (Owner); ServicePrincipal 2222 (Contributor)" {
+		t.Errorf("unexpected porcelain value %v", got)
+	}
+	if got := client.ownershipValues(broken); got[0] != "ERROR" {
+		t.Errorf("unexpected porcelain value for a failed lookup %v", got)
+	}
+	if got := columnInferredOwners(ResourceGroupResult{}, columnStylePorcelain); got != "none" {
+		t.Errorf("expected none, got %q", got)
+	}
+
+	inventory := client.buildInventory([]ResourceGroupResult{owned, broken}, false)
+	if len(inventory[0].InferredOwners) != 2 || inventory[0].RoleAssignments[0].PrincipalID != "1111" {
+		t.Errorf("unexpected inventory %+v", inventory[0])
+	}
+	if !strings.Contains(inventory[0].Error, "principal lookup failed") || !strings.Contains(inventory[1].Error, "role assignment lookup failed") {
+		t.Errorf("unexpected errors %q, %q", inventory[0].Error, inventory[1].Error)
+	}
+
+	client.Config.RoleAssignments = false
+	if client.ownershipColumns() != nil || client.ownershipValues(owned) != nil {
+		t.Error("expected no columns without --role-assignments")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PolicySummary is the policy compliance state of a resource group's resources
+type PolicySummary struct {
+	NonCompliantResources int `json:"nonCompliantResources"`
+	NonCompliantPolicies  int `json:"nonCompliantPolicies"`
+}
+
+// PolicyStatesSummaryResponse is the response of policyStates/latest/summarize
+type PolicyStatesSummaryResponse struct {
+	Value []struct {
+		Results PolicySummary `json:"results"`
+	} `json:"value"`
+}
+
+// NonCompliant reports whether any resource in the group is non-compliant
+func (s *PolicySummary) NonCompliant() bool {
+	return s != nil && (s.NonCompliantResources > 0 || s.NonCompliantPolicies > 0)
+}
+
+// fetchPolicySummary summarizes the latest policy states at the resource group's scope
+func (ac *AzureClient) fetchPolicySummary(resourceGroupName string) (*PolicySummary, error) {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).
+		URL("providers/Microsoft.PolicyInsights/policyStates/latest/summarize", apiVersion("2019-10-01"))
+
+	resp, err := ac.doAzureRequest("POST", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize policy states: %w", err)
+	}
+
+	var summary PolicyStatesSummaryResponse
+	if err := decodeAzureResponse(resp, &summary); err != nil {
+		return nil, err
+	}
+
+	// The summary has a single entry; a group without policy evaluations has none
+	if len(summary.Value) == 0 {
+		return &PolicySummary{}, nil
+	}
+	return &summary.Value[0].Results, nil
+}
+
+func columnNonCompliantResources(r ResourceGroupResult, style columnStyle) string {
+	return policyCount(r, style, func(s *PolicySummary) int { return s.NonCompliantResources })
+}
+
+func columnNonCompliantPolicies(r ResourceGroupResult, style columnStyle) string {
+	return policyCount(r, style, func(s *PolicySummary) int { return s.NonCompliantPolicies })
+}
+
+func policyCount(r ResourceGroupResult, style columnStyle, count func(*PolicySummary) int) string {
+	switch {
+	case r.PolicyError != nil && style == columnStyleCSV:
+		return fmt.Sprintf("Error: %v", r.PolicyError)
+	case r.PolicyError != nil:
+		return "ERROR"
+	case r.Policy != nil:
+		return strconv.Itoa(count(r.Policy))
+	case style == columnStyleCSV:
+		return ""
+	default:
+		return "N/A"
+	}
+}
+
+// policyColumns returns the extra porcelain header columns when --policy-compliance is set
+func (ac *AzureClient) policyColumns() []string {
+	if !ac.Config.PolicyCompliance {
+		return nil
+	}
+	return []string{"NON_COMPLIANT_RESOURCES", "NON_COMPLIANT_POLICIES"}
+}
+
+// policyValues returns the porcelain values matching policyColumns
+func (ac *AzureClient) policyValues(result ResourceGroupResult) []string {
+	if !ac.Config.PolicyCompliance {
+		return nil
+	}
+	return []string{columnNonCompliantResources(result, columnStylePorcelain), columnNonCompliantPolicies(result, columnStylePorcelain)}
+}
+
+// printPolicyCompliance prints the compliance state in the human-readable output
+func (ac *AzureClient) printPolicyCompliance(result ResourceGroupResult) {
+	if !ac.Config.PolicyCompliance {
+		return
+	}
+	switch {
+	case result.PolicyError != nil:
+		fmt.Printf("  📋 Policy: Error fetching (%v)\n", result.PolicyError)
+	case result.Policy.NonCompliant():
+		fmt.Printf("  📋 Policy: %d non-compliant resources, %d non-compliant policies\n",
+			result.Policy.NonCompliantResources, result.Policy.NonCompliantPolicies)
+	case result.Policy != nil:
+		fmt.Printf("  📋 Policy: compliant\n")
+	}
+}
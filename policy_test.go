@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPolicySummary(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/providers/Microsoft.PolicyInsights/policyStates/latest/summarize") {
+			t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		}
+		switch {
+		case strings.Contains(req.URL.Path, "/resourceGroups/prod-app/"):
+			return jsonResponse(http.StatusOK, `{"value": [{"results": {"nonCompliantResources": 3, "nonCompliantPolicies": 2}}]}`), nil
+		case strings.Contains(req.URL.Path, "/resourceGroups/broken/"):
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+
+	summary, err := client.fetchPolicySummary("prod-app")
+	if err != nil || summary.NonCompliantResources != 3 || summary.NonCompliantPolicies != 2 || !summary.NonCompliant() {
+		t.Errorf("unexpected summary %+v (%v)", summary, err)
+	}
+	summary, err = client.fetchPolicySummary("sandbox-alice")
+	if err != nil || summary.NonCompliant() {
+		t.Errorf("expected a compliant group, got %+v (%v)", summary, err)
+	}
+	if _, err := client.fetchPolicySummary("broken"); err == nil {
+		t.Error("Expected error for a failed lookup")
+	}
+}
+
+func TestNonCompliantOnly(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/summarize") && strings.Contains(req.URL.Path, "/resourceGroups/prod-app/"):
+			return jsonResponse(http.StatusOK, `{"value": [{"results": {"nonCompliantResources": 1, "nonCompliantPolicies": 1}}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/summarize") && strings.Contains(req.URL.Path, "/resourceGroups/sandbox-bob/"):
+			return jsonResponse(http.StatusInternalServerError, `oops`), nil
+		case strings.HasSuffix(req.URL.Path, "/summarize"):
+			return jsonResponse(http.StatusOK, `{"value": [{"results": {"nonCompliantResources": 0, "nonCompliantPolicies": 0}}]}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+	client.Config.PolicyCompliance = true
+	client.Filter, _ = NewResourceGroupFilter(FilterOptions{NonCompliantOnly: true}, time.Now())
+
+	// sandbox-bob's lookup failed: it is kept so the error is reported, not silently dropped
+	results := client.collectResourceGroupResults(costTestGroups(t), false, "")
+	if len(results) != 2 || results[0].ResourceGroup.Name != "sandbox-bob" || results[0].PolicyError == nil || results[1].ResourceGroup.Name != "prod-app" {
+		t.Fatalf("expected the failed sandbox-bob and prod-app, got %v", results)
+	}
+	if got := strings.Join(client.policyValues(results[0]), ","); got != "ERROR,ERROR" {
+		t.Errorf("unexpected porcelain values for the failed lookup %s", got)
+	}
+	if got := strings.Join(client.policyValues(results[1]), ","); got != "1,1" {
+		t.Errorf("unexpected porcelain values %s", got)
+	}
+
+	inventory := client.buildInventory(results, false)
+	if inventory[1].PolicyCompliance == nil || inventory[1].PolicyCompliance.NonCompliantResources != 1 {
+		t.Errorf("unexpected inventory %+v", inventory[1])
+	}
+
+	failed := ResourceGroupResult{PolicyError: errors.New("lookup failed")}
+	if got := columnNonCompliantResources(failed, columnStyleCSV); got != "Error: lookup failed" {
+		t.Errorf("unexpected CSV value %q", got)
+	}
+	if got := columnNonCompliantPolicies(ResourceGroupResult{}, columnStylePorcelain); got != "N/A" {
+		t.Errorf("expected N/A, got %q", got)
+	}
+}
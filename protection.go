@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProtectionConfig is the "protected" section of a protection config file. Example (YAML):
+//
+//	protected:
+//	  names: [prod-core, shared-networking]
+//	  patterns: ["^prod-", "(?i)-keep$"]
+//	  tags: ["do-not-delete", "env=prod"]
+type ProtectionConfig struct {
+	Names    []string `mapstructure:"names"`
+	Patterns []string `mapstructure:"patterns"`
+	Tags     []string `mapstructure:"tags"`
+}
+
+// ProtectionRules decides whether a resource group must never be deleted
+type ProtectionRules struct {
+	names    map[string]bool
+	patterns []*regexp.Regexp
+	tags     []tagRule
+}
+
+// tagRule matches a tag by key (case-insensitive) and optionally by value
+type tagRule struct {
+	raw      string
+	key      string
+	value    string
+	hasValue bool
+}
+
+// ManagementLock is an Azure management lock applied at or above a resource group's scope
+type ManagementLock struct {
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Properties ManagementLockProperties `json:"properties"`
+}
+
+// ManagementLockProperties holds the lock level and notes
+type ManagementLockProperties struct {
+	Level string `json:"level"`
+	Notes string `json:"notes,omitempty"`
+}
+
+type ManagementLocksResponse struct {
+	Value []ManagementLock `json:"value"`
+}
+
+// loadProtectionRules reads a protection config file (any format viper supports: YAML, JSON, TOML)
+func loadProtectionRules(path string) (*ProtectionRules, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read protection config: %w", err)
+	}
+
+	var cfg ProtectionConfig
+	if err := v.UnmarshalKey("protected", &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse protection config: %w", err)
+	}
+
+	return NewProtectionRules(cfg)
+}
+
+// NewProtectionRules compiles a ProtectionConfig
+func NewProtectionRules(cfg ProtectionConfig) (*ProtectionRules, error) {
+	rules := &ProtectionRules{names: make(map[string]bool, len(cfg.Names))}
+
+	for _, name := range cfg.Names {
+		rules.names[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	for _, pattern := range cfg.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protected pattern %q: %w", pattern, err)
+		}
+		rules.patterns = append(rules.patterns, compiled)
+	}
+
+	for _, selector := range cfg.Tags {
+		rule := tagRule{raw: selector}
+		key, value, hasValue := strings.Cut(selector, "=")
+		rule.key = strings.TrimSpace(key)
+		rule.value = strings.TrimSpace(value)
+		rule.hasValue = hasValue
+		if rule.key == "" {
+			return nil, fmt.Errorf("invalid protected tag selector %q: missing key", selector)
+		}
+		rules.tags = append(rules.tags, rule)
+	}
+
+	return rules, nil
+}
+
+// Check reports whether a resource group is protected and which rule protected it
+func (p *ProtectionRules) Check(rg ResourceGroup) (bool, string) {
+	if p == nil {
+		return false, ""
+	}
+
+	if p.names[strings.ToLower(rg.Name)] {
+		return true, "name is on the protected list"
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(rg.Name) {
+			return true, fmt.Sprintf("name matches protected pattern %q", pattern.String())
+		}
+	}
+
+	for _, rule := range p.tags {
+		if rule.matches(rg.Tags) {
+			return true, fmt.Sprintf("tag matches protected selector %q", rule.raw)
+		}
+	}
+
+	return false, ""
+}
+
+func (r tagRule) matches(tags map[string]string) bool {
+	for key, value := range tags {
+		if !strings.EqualFold(strings.TrimSpace(key), r.key) {
+			continue
+		}
+		if !r.hasValue || strings.EqualFold(strings.TrimSpace(value), r.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchResourceGroupLocks lists the management locks that apply at the resource group's scope
+// (locks on the group itself or inherited from the subscription)
+func (ac *AzureClient) fetchResourceGroupLocks(resourceGroupName string) ([]ManagementLock, error) {
+	query := apiVersion("2016-09-01")
+	query.Set("$filter", "atScope()")
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).
+		URL("providers/Microsoft.Authorization/locks", query)
+
+	resp, err := ac.makeAzureRequest(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch locks: %w", err)
+	}
+
+	var locksResponse ManagementLocksResponse
+	if err := decodeAzureResponse(resp, &locksResponse); err != nil {
+		return nil, err
+	}
+
+	return locksResponse.Value, nil
+}
+
+// formatLocks renders locks as "Level (name)" entries joined by ", "
+func formatLocks(locks []ManagementLock) string {
+	parts := make([]string, 0, len(locks))
+	for _, lock := range locks {
+		parts = append(parts, fmt.Sprintf("%s (%s)", lock.Properties.Level, lock.Name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lockLevels returns the distinct lock levels, e.g. "CanNotDelete,ReadOnly", or "none"
+func lockLevels(locks []ManagementLock) string {
+	if len(locks) == 0 {
+		return "none"
+	}
+	seen := make(map[string]bool, len(locks))
+	levels := make([]string, 0, len(locks))
+	for _, lock := range locks {
+		if !seen[lock.Properties.Level] {
+			seen[lock.Properties.Level] = true
+			levels = append(levels, lock.Properties.Level)
+		}
+	}
+	return strings.Join(levels, ",")
+}
+
+// protectionColumns returns the extra porcelain header columns for enabled guard rails
+func (ac *AzureClient) protectionColumns() []string {
+	var columns []string
+	if ac.Protection != nil {
+		columns = append(columns, "PROTECTED")
+	}
+	if ac.Config.CheckLocks {
+		columns = append(columns, "LOCKS")
+	}
+	return columns
+}
+
+// protectionValues returns the porcelain values matching protectionColumns
+func (ac *AzureClient) protectionValues(result ResourceGroupResult) []string {
+	var values []string
+	if ac.Protection != nil {
+		values = append(values, fmt.Sprintf("%v", result.Protected))
+	}
+	if ac.Config.CheckLocks {
+		if result.LocksError != nil {
+			values = append(values, "ERROR")
+		} else {
+			values = append(values, lockLevels(result.Locks))
+		}
+	}
+	return values
+}
+
+// printProtectionDetails prints guard-rail information in the human-readable output
+func (ac *AzureClient) printProtectionDetails(result ResourceGroupResult) {
+	if result.Protected {
+		fmt.Printf("  🛡️  PROTECTED: %s\n", result.ProtectionReason)
+	}
+	if ac.Config.CheckLocks {
+		switch {
+		case result.LocksError != nil:
+			fmt.Printf("  🔒 Locks: Error fetching (%v)\n", result.LocksError)
+		case len(result.Locks) > 0:
+			fmt.Printf("  🔒 Locks: %s\n", formatLocks(result.Locks))
+		}
+	}
+}
+
+// cleanupGuardReason returns why a resource group must not be planned for deletion, or "" if it may be
+func (ac *AzureClient) cleanupGuardReason(rg ResourceGroup) string {
+	if protected, reason := ac.Protection.Check(rg); protected {
+		return "protected: " + reason
+	}
+
+	locks, err := ac.fetchResourceGroupLocks(rg.Name)
+	if err != nil {
+		return fmt.Sprintf("lock lookup failed: %v", err)
+	}
+	if len(locks) > 0 {
+		return "locked: " + formatLocks(locks)
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProtectionRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protected.yaml")
+	content := `protected:
+  names:
+    - Shared-Networking
+  patterns:
+    - "^prod-"
+  tags:
+    - do-not-delete
+    - env=prod
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	rules, err := loadProtectionRules(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		rg        ResourceGroup
+		protected bool
+		reason    string
+	}{
+		{"listed name is case-insensitive", ResourceGroup{Name: "shared-networking"}, true, "name is on the protected list"},
+		{"pattern", ResourceGroup{Name: "prod-api"}, true, `name matches protected pattern "^prod-"`},
+		{"tag key only", ResourceGroup{Name: "misc", Tags: map[string]string{"Do-Not-Delete": ""}}, true, `tag matches protected selector "do-not-delete"`},
+		{"tag key and value", ResourceGroup{Name: "misc", Tags: map[string]string{"env": "Prod"}}, true, `tag matches protected selector "env=prod"`},
+		{"tag value mismatch", ResourceGroup{Name: "misc", Tags: map[string]string{"env": "dev"}}, false, ""},
+		{"unprotected", ResourceGroup{Name: "sandbox-alice"}, false, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			protected, reason := rules.Check(tc.rg)
+			if protected != tc.protected {
+				t.Errorf("Expected protected=%v, got %v", tc.protected, protected)
+			}
+			if reason != tc.reason {
+				t.Errorf("Expected reason %q, got %q", tc.reason, reason)
+			}
+		})
+	}
+}
+
+func TestProtectionRulesErrors(t *testing.T) {
+	if _, err := NewProtectionRules(ProtectionConfig{Patterns: []string{"("}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+	if _, err := NewProtectionRules(ProtectionConfig{Tags: []string{"=prod"}}); err == nil {
+		t.Error("Expected error for tag selector without key")
+	}
+	if _, err := loadProtectionRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing config file")
+	}
+
+	var rules *ProtectionRules
+	if protected, _ := rules.Check(ResourceGroup{Name: "anything"}); protected {
+		t.Error("Expected nil rules to protect nothing")
+	}
+}
+
+func TestFetchResourceGroupsWithProtectionColumns(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			switch {
+			case strings.Contains(req.URL.Path, "Microsoft.Authorization/locks"):
+				if strings.Contains(req.URL.Path, "/prod-app/") {
+					return jsonResponse(http.StatusOK, `{"value": [{"name": "no-delete", "properties": {"level": "CanNotDelete"}}, {"name": "ro", "properties": {"level": "ReadOnly"}}]}`), nil
+				}
+				return jsonResponse(http.StatusOK, `{"value": []}`), nil
+			case strings.Contains(req.URL.Path, "resourcegroups"):
+				return jsonResponse(http.StatusOK, `{"value": [
+					{"id": "/subscriptions/test/resourceGroups/prod-app", "name": "prod-app", "location": "eastus", "properties": {"provisioningState": "Succeeded"}},
+					{"id": "/subscriptions/test/resourceGroups/sandbox", "name": "sandbox", "location": "eastus", "properties": {"provisioningState": "Succeeded"}}
+				]}`), nil
+			default:
+				return jsonResponse(http.StatusOK, `{"value": []}`), nil
+			}
+		},
+	}
+
+	rules, err := NewProtectionRules(ProtectionConfig{Patterns: []string{"^prod-"}})
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "out.csv")
+	client := &AzureClient{
+		Config: Config{
+			SubscriptionID: "test-subscription",
+			AccessToken:    "test-token",
+			MaxConcurrency: 2,
+			Porcelain:      true,
+			CheckLocks:     true,
+			OutputCSV:      csvPath,
+		},
+		HTTPClient: mockClient,
+		Protection: rules,
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	err = client.FetchResourceGroups()
+
+	if closeErr := w.Close(); closeErr != nil {
+		t.Errorf("Failed to close pipe writer: %v", closeErr)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Errorf("Failed to copy output: %v", copyErr)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "NAME\tLOCATION\tPROVISIONING_STATE\tCREATED_TIME\tIS_DEFAULT\tPROTECTED\tLOCKS\n") {
+		t.Errorf("Expected porcelain header with guard-rail columns, got:\n%s", output)
+	}
+	if !strings.Contains(output, "prod-app\teastus\tSucceeded\tN/A\tfalse\ttrue\tCanNotDelete,ReadOnly\n") {
+		t.Errorf("Expected protected and locked row, got:\n%s", output)
+	}
+	if !strings.Contains(output, "sandbox\teastus\tSucceeded\tN/A\tfalse\tfalse\tnone\n") {
+		t.Errorf("Expected unprotected row, got:\n%s", output)
+	}
+
+	csvContent, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	csvStr := string(csvContent)
+	if !strings.Contains(csvStr, "Resources,Protected,ProtectionReason,Locks") {
+		t.Errorf("Expected guard-rail CSV columns, got:\n%s", csvStr)
+	}
+	if !strings.Contains(csvStr, `true,"name matches protected pattern ""^prod-""","CanNotDelete (no-delete), ReadOnly (ro)"`) {
+		t.Errorf("Expected guard-rail values in CSV, got:\n%s", csvStr)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sources of a resource group's creation time, tried in the order given by --created-time-sources
+const (
+	createdTimeSourceResources   = "resources"
+	createdTimeSourceTag         = "tag"
+	createdTimeSourceDeployment  = "deployment"
+	createdTimeSourceActivityLog = "activity-log"
+)
+
+// createdTimeSourceNames lists the supported sources
+var createdTimeSourceNames = []string{createdTimeSourceResources, createdTimeSourceTag, createdTimeSourceDeployment, createdTimeSourceActivityLog}
+
+// defaultCreatedTimeSources keeps the original behaviour: the earliest resource createdTime
+var defaultCreatedTimeSources = []string{createdTimeSourceResources}
+
+// defaultCreatedTimeTags are the tag keys read by the tag source: a common policy-assigned key
+// and the key written by the tag command
+var defaultCreatedTimeTags = []string{"createdTime", defaultCreatedTimeTag}
+
+// createdTimeTagLayouts are the accepted formats of a creation time tag value
+var createdTimeTagLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02", "01/02/2006"}
+
+// DeploymentsResponse is one page of a resource group's deployment history
+type DeploymentsResponse struct {
+	Value []struct {
+		Properties struct {
+			Timestamp *time.Time `json:"timestamp"`
+		} `json:"properties"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// parseCreatedTimeSources validates a --created-time-sources list; names are case-insensitive
+// and duplicates are dropped
+func parseCreatedTimeSources(sources []string) ([]string, error) {
+	var chain []string
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" || seen[source] {
+			continue
+		}
+		if !containsString(createdTimeSourceNames, source) {
+			return nil, fmt.Errorf("unknown creation time source %q (supported: %s)", source, strings.Join(createdTimeSourceNames, ", "))
+		}
+		seen[source] = true
+		chain = append(chain, source)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("at least one creation time source is required")
+	}
+	return chain, nil
+}
+
+// createdTimeSources returns the effective source chain. --activity-log puts the Activity Log
+// first when the chain does not already include it.
+func (ac *AzureClient) createdTimeSources() []string {
+	chain := ac.Config.CreatedTimeSources
+	if len(chain) == 0 {
+		chain = defaultCreatedTimeSources
+	}
+	if ac.Config.ActivityLog && !containsString(chain, createdTimeSourceActivityLog) {
+		chain = append([]string{createdTimeSourceActivityLog}, chain...)
+	}
+	return chain
+}
+
+// customCreatedTimeSources reports whether anything other than the default chain is in use,
+// in which case the human output shows where each creation time came from
+func (ac *AzureClient) customCreatedTimeSources() bool {
+	chain := ac.createdTimeSources()
+	return len(chain) != 1 || chain[0] != createdTimeSourceResources
+}
+
+// resolveCreatedTime sets the creation time from the first source in the chain that has one,
+// recording the source. Lookup failures are kept on the result and the next source is tried.
+func (ac *AzureClient) resolveCreatedTime(result *ResourceGroupResult) {
+	result.CreatedTime, result.CreatedTimeSource = nil, ""
+
+	for _, source := range ac.createdTimeSources() {
+		var created *time.Time
+		switch source {
+		case createdTimeSourceResources:
+			created = earliestCreatedTime(result.Resources)
+		case createdTimeSourceTag:
+			created = createdTimeFromTags(result.ResourceGroup.Tags, ac.createdTimeTags())
+		case createdTimeSourceDeployment:
+			if history, err := ac.deploymentHistory(result); err == nil {
+				created = history.Earliest
+			}
+		case createdTimeSourceActivityLog:
+			created = ac.activityLogCreatedTime(result)
+		}
+
+		if created != nil {
+			result.CreatedTime = created
+			result.CreatedTimeSource = source
+			return
+		}
+	}
+}
+
+func (ac *AzureClient) createdTimeTags() []string {
+	if len(ac.Config.CreatedTimeTags) == 0 {
+		return defaultCreatedTimeTags
+	}
+	return ac.Config.CreatedTimeTags
+}
+
+// createdTimeFromTags returns the first creation time tag (by key order, case-insensitive)
+// whose value parses; unparseable values are skipped
+func createdTimeFromTags(tags map[string]string, keys []string) *time.Time {
+	for _, key := range keys {
+		_, value, found := lookupTag(tags, key)
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		for _, layout := range createdTimeTagLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				t = t.UTC()
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+// DeploymentHistory is the time span covered by a resource group's deployment history
+type DeploymentHistory struct {
+	Earliest *time.Time
+	Latest   *time.Time
+}
+
+// deploymentHistory returns the group's deployment history, fetching it on first use so the
+// creation time chain and the last modified time share one lookup
+func (ac *AzureClient) deploymentHistory(result *ResourceGroupResult) (*DeploymentHistory, error) {
+	if result.Deployments == nil && result.DeploymentsError == nil {
+		result.Deployments, result.DeploymentsError = ac.fetchDeploymentHistory(result.ResourceGroup.Name)
+	}
+	return result.Deployments, result.DeploymentsError
+}
+
+// fetchDeploymentHistory returns the timestamps of the oldest and newest deployments still in
+// the group's deployment history; both are nil when there are none
+func (ac *AzureClient) fetchDeploymentHistory(resourceGroupName string) (*DeploymentHistory, error) {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).
+		URL("providers/Microsoft.Resources/deployments", apiVersion("2021-04-01"))
+
+	history := &DeploymentHistory{}
+	for url != "" {
+		resp, err := ac.makeAzureRequest(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch deployments: %w", err)
+		}
+
+		var page DeploymentsResponse
+		if err := decodeAzureResponse(resp, &page); err != nil {
+			return nil, err
+		}
+
+		for _, deployment := range page.Value {
+			timestamp := deployment.Properties.Timestamp
+			if timestamp == nil {
+				continue
+			}
+			t := timestamp.UTC()
+			if history.Earliest == nil || t.Before(*history.Earliest) {
+				history.Earliest = &t
+			}
+			if history.Latest == nil || t.After(*history.Latest) {
+				history.Latest = &t
+			}
+		}
+		url = page.NextLink
+	}
+
+	return history, nil
+}
+
+// printCreationDetails prints where the creation time came from and who created the group
+// when a non-default source chain is configured
+func (ac *AzureClient) printCreationDetails(result ResourceGroupResult) {
+	if !ac.customCreatedTimeSources() {
+		return
+	}
+	if result.CreatedTimeSource != "" {
+		fmt.Printf("  Created Time Source: %s\n", result.CreatedTimeSource)
+	}
+	if result.Creator != "" {
+		fmt.Printf("  Creator: %s\n", result.Creator)
+	}
+	if result.DeploymentsError != nil {
+		fmt.Printf("  Deployments: lookup failed (%v)\n", result.DeploymentsError)
+	}
+	if result.ActivityLogError != nil {
+		fmt.Printf("  Activity Log: lookup failed (%v)\n", result.ActivityLogError)
+	}
+}
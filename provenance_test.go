@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCreatedTimeSources(t *testing.T) {
+	chain, err := parseCreatedTimeSources([]string{" Tag", "resources", "tag", "", "DEPLOYMENT"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(chain, ",") != "tag,resources,deployment" {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	for _, sources := range [][]string{nil, {""}, {"resources", "guess"}} {
+		if _, err := parseCreatedTimeSources(sources); err == nil {
+			t.Errorf("Expected error for %v", sources)
+		}
+	}
+}
+
+func TestCreatedTimeFromTags(t *testing.T) {
+	keys := []string{"createdTime", "CreatedOn"}
+	tests := []struct {
+		tags     map[string]string
+		expected string
+	}{
+		{map[string]string{"CREATEDTIME": "2023-04-05T06:07:08+02:00"}, "2023-04-05T04:07:08Z"},
+		{map[string]string{"createdOn": "2023-04-05"}, "2023-04-05T00:00:00Z"},
+		{map[string]string{"createdOn": "04/05/2023"}, "2023-04-05T00:00:00Z"},
+		{map[string]string{"createdTime": "last spring", "CreatedOn": "2023-04-05 10:00:00"}, "2023-04-05T10:00:00Z"},
+		{map[string]string{"owner": "alice"}, ""},
+	}
+	for _, tt := range tests {
+		got := createdTimeFromTags(tt.tags, keys)
+		switch {
+		case tt.expected == "" && got != nil:
+			t.Errorf("%v: expected no time, got %v", tt.tags, got)
+		case tt.expected != "" && (got == nil || got.Format(time.RFC3339) != tt.expected):
+			t.Errorf("%v: expected %s, got %v", tt.tags, tt.expected, got)
+		}
+	}
+}
+
+func TestResolveCreatedTimeChain(t *testing.T) {
+	deploymentCalls := 0
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/providers/Microsoft.Resources/deployments") {
+			t.Fatalf("unexpected request %s", req.URL)
+		}
+		deploymentCalls++
+		switch {
+		case strings.Contains(req.URL.Path, "/resourceGroups/broken/"):
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		case req.URL.Query().Get("page") == "2":
+			return jsonResponse(http.StatusOK, `{"value": [{"properties": {"timestamp": "2022-11-30T08:00:00Z"}}]}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": [{"properties": {"timestamp": "2023-02-01T08:00:00Z"}}, {"properties": {}}],
+			"nextLink": "https://management.azure.com/subscriptions/test-subscription/resourceGroups/empty/providers/Microsoft.Resources/deployments?page=2"}`), nil
+	})
+	client.Config.CreatedTimeSources = []string{createdTimeSourceTag, createdTimeSourceResources, createdTimeSourceDeployment}
+	client.Config.CreatedTimeTags = []string{"createdTime"}
+
+	resourceTime := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	resources := []Resource{{Name: "vm", CreatedTime: &resourceTime}}
+
+	// The tag comes first in the chain, so it wins over the resources
+	tagged := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "tagged", Tags: map[string]string{"createdTime": "2023-01-01"}}, Resources: resources}
+	client.resolveCreatedTime(&tagged)
+	if tagged.CreatedTimeSource != createdTimeSourceTag || tagged.CreatedTime.Format("2006-01-02") != "2023-01-01" {
+		t.Errorf("unexpected result %v from %q", tagged.CreatedTime, tagged.CreatedTimeSource)
+	}
+
+	untagged := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "untagged"}, Resources: resources}
+	client.resolveCreatedTime(&untagged)
+	if untagged.CreatedTimeSource != createdTimeSourceResources || !untagged.CreatedTime.Equal(resourceTime) {
+		t.Errorf("unexpected result %v from %q", untagged.CreatedTime, untagged.CreatedTimeSource)
+	}
+	if deploymentCalls != 0 {
+		t.Errorf("Expected later sources to be skipped once a time is found, got %d deployment calls", deploymentCalls)
+	}
+
+	// Empty groups fall through to the earliest deployment across pages
+	empty := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "empty"}}
+	client.resolveCreatedTime(&empty)
+	if empty.CreatedTimeSource != createdTimeSourceDeployment || empty.CreatedTime.Format(time.RFC3339) != "2022-11-30T08:00:00Z" {
+		t.Errorf("unexpected result %v from %q", empty.CreatedTime, empty.CreatedTimeSource)
+	}
+
+	broken := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "broken"}}
+	client.resolveCreatedTime(&broken)
+	if broken.CreatedTime != nil || broken.CreatedTimeSource != "" || broken.DeploymentsError == nil {
+		t.Errorf("Expected no creation time and a deployment error, got %+v", broken)
+	}
+}
+
+func TestCreatedTimeSourcesWithActivityLog(t *testing.T) {
+	client := &AzureClient{}
+	if client.customCreatedTimeSources() {
+		t.Error("Expected the default chain without configuration")
+	}
+
+	client.Config.ActivityLog = true
+	if chain := client.createdTimeSources(); strings.Join(chain, ",") != "activity-log,resources" {
+		t.Errorf("Expected --activity-log to put the Activity Log first, got %v", chain)
+	}
+
+	client.Config.CreatedTimeSources = []string{createdTimeSourceResources, createdTimeSourceActivityLog}
+	if chain := client.createdTimeSources(); strings.Join(chain, ",") != "resources,activity-log" {
+		t.Errorf("Expected an explicit chain to be kept, got %v", chain)
+	}
+	if !client.customCreatedTimeSources() {
+		t.Error("Expected a custom chain")
+	}
+}
+
+func TestListResourcesPorcelainUsesResolvedCreatedTime(t *testing.T) {
+	client := &AzureClient{Config: Config{SubscriptionID: "test-subscription", Porcelain: true}}
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := ResourceGroupResult{
+		ResourceGroup:     ResourceGroup{Name: "tagged", Location: "eastus"},
+		CreatedTime:       &created,
+		CreatedTimeSource: createdTimeSourceTag,
+		Resources:         []Resource{{Name: "vm"}},
+	}
+
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	client.printResourceGroupResultWithResources(result, result.Resources)
+	if err := w.Close(); err != nil {
+		t.Errorf("Failed to close pipe writer: %v", err)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Errorf("Failed to copy output: %v", err)
+	}
+	if buf.String() != "tagged\teastus\t\t2023-01-01T00:00:00Z\tfalse\n" {
+		t.Errorf("unexpected porcelain output %q", buf.String())
+	}
+}
+
+func TestCreatedTimeFallbacksWhenResourcesFail(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/resources"):
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		case strings.Contains(req.URL.Path, "/resourceGroups/deployed/"):
+			return jsonResponse(http.StatusOK, `{"value": [{"properties": {"timestamp": "2020-01-01T00:00:00Z"}}]}`), nil
+		case strings.Contains(req.URL.Path, "/resourceGroups/recent/"):
+			return jsonResponse(http.StatusOK, `{"value": [{"properties": {"timestamp": "2099-01-01T00:00:00Z"}}]}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+	client.Config.CreatedTimeSources = []string{createdTimeSourceResources, createdTimeSourceDeployment}
+	client.Filter, _ = NewResourceGroupFilter(FilterOptions{CreatedBefore: "2021-01-01"}, time.Now())
+
+	groups := []ResourceGroup{{Name: "deployed"}, {Name: "recent"}, {Name: "unknown"}}
+	results := client.collectResourceGroupResults(groups, false, "")
+
+	// "recent" is filtered out by its deployment time; "unknown" has no time from any source
+	// and is kept so the listing error is reported
+	if len(results) != 2 || results[0].ResourceGroup.Name != "deployed" || results[1].ResourceGroup.Name != "unknown" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].CreatedTimeSource != createdTimeSourceDeployment || results[0].Error == nil {
+		t.Errorf("expected a deployment creation time alongside the resource error, got %+v", results[0])
+	}
+	if got := columnCreatedTime(results[0], columnStylePorcelain); got != "2020-01-01T00:00:00Z" {
+		t.Errorf("unexpected created time %q", got)
+	}
+	if got := columnCreatedTime(results[1], columnStylePorcelain); got != "ERROR" || results[1].Error == nil {
+		t.Errorf("expected ERROR for the unresolved group, got %q", got)
+	}
+}
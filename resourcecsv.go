@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// CSV layouts for --csv-layout
+const (
+	csvLayoutGroup    = "group"
+	csvLayoutResource = "resource"
+)
+
+// resourceCSVHeaders are the per-resource columns of the exploded layout and the resources CSV
+var resourceCSVHeaders = []string{
+	"ResourceID",
+	"ResourceName",
+	"ResourceType",
+	"ResourceLocation",
+	"ResourceCreatedTime",
+	"ResourceChangedTime",
+}
+
+// validateCSVLayout checks a --csv-layout value
+func validateCSVLayout(layout string) error {
+	switch layout {
+	case csvLayoutGroup, csvLayoutResource:
+		return nil
+	}
+	return fmt.Errorf("unsupported CSV layout %q (supported: group, resource)", layout)
+}
+
+// csvGroupColumns returns the group columns repeated on each row of the exploded layout: the
+// --columns selection, or the fixed CSV columns without the packed Resources cell
+func (ac *AzureClient) csvGroupColumns() []Column {
+	if ac.Columns != nil {
+		return ac.Columns
+	}
+
+	keys := []string{"name", "location", "state", "created_time", "is_default", "created_by", "description"}
+	if ac.Protection != nil {
+		keys = append(keys, "protected", "protection_reason")
+	}
+	if ac.Config.CheckLocks {
+		keys = append(keys, "locks")
+	}
+	if ac.Config.LastModified {
+		keys = append(keys, "last_modified", "days_since_change")
+	}
+	if ac.Config.RoleAssignments {
+		keys = append(keys, "inferred_owners", "role_assignments")
+	}
+	if ac.Config.PolicyCompliance {
+		keys = append(keys, "non_compliant_resources", "non_compliant_policies")
+	}
+	if ac.Config.Cost {
+		keys = append(keys, "cost", "cost_currency")
+	}
+	if ac.Config.TagInheritance {
+		keys = append(keys, "tag_gap_resources", "tag_gaps")
+	}
+
+	columns := make([]Column, 0, len(keys))
+	for _, key := range keys {
+		column, _ := lookupColumn(key)
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// resourceCSVValues renders the per-resource columns; unknown times are left empty so
+// spreadsheets can treat the column as dates
+func resourceCSVValues(resource Resource) []string {
+	return []string{
+		resource.ID,
+		resource.Name,
+		resource.Type,
+		resource.Location,
+		formatCSVTime(resource.CreatedTime),
+		formatCSVTime(resource.ChangedTime),
+	}
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// explodedCSVRecords builds the resource layout: one row per resource with the group columns
+// repeated. Groups without resources, or whose resource lookup failed, still get one row with
+// empty resource columns so no group disappears from the file.
+func (ac *AzureClient) explodedCSVRecords(results []ResourceGroupResult) [][]string {
+	groupColumns := ac.csvGroupColumns()
+	header := append(columnHeaders(groupColumns, columnStyleCSV), resourceCSVHeaders...)
+
+	records := [][]string{header}
+	emptyResource := make([]string, len(resourceCSVHeaders))
+	for _, result := range results {
+		groupValues := columnValues(groupColumns, result, columnStyleCSV)
+		if len(result.Resources) == 0 {
+			records = append(records, append(append([]string{}, groupValues...), emptyResource...))
+			continue
+		}
+		for _, resource := range result.Resources {
+			records = append(records, append(append([]string{}, groupValues...), resourceCSVValues(resource)...))
+		}
+	}
+	return records
+}
+
+// writeResourcesCSV writes one row per resource to path, linked to the group CSV by ResourceGroupID
+func writeResourcesCSV(path string, results []ResourceGroupResult) error {
+	records := [][]string{append([]string{"ResourceGroupID", "ResourceGroupName"}, resourceCSVHeaders...)}
+	for _, result := range results {
+		for _, resource := range result.Resources {
+			row := append([]string{result.ResourceGroup.ID, result.ResourceGroup.Name}, resourceCSVValues(resource)...)
+			records = append(records, row)
+		}
+	}
+	return writeCSVRecords(path, records)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readCSVFile(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV file: %v", err)
+	}
+	return records
+}
+
+func TestExplodedAndResourcesCSV(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.Contains(req.URL.Path, "/resourceGroups/sandbox-alice/resources"):
+			return jsonResponse(http.StatusOK, `{"value": [
+				{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-alice/providers/Microsoft.Web/sites/app", "name": "app", "type": "Microsoft.Web/sites", "location": "eastus", "createdTime": "2023-01-01T12:00:00Z", "changedTime": "2023-06-01T08:30:00Z"},
+				{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-alice/providers/Microsoft.Storage/storageAccounts/data", "name": "data", "type": "Microsoft.Storage/storageAccounts", "location": "eastus"}
+			]}`), nil
+		case strings.Contains(req.URL.Path, "/resourceGroups/prod-app/resources"):
+			return jsonResponse(http.StatusInternalServerError, `{"error": "boom"}`), nil
+		case strings.HasSuffix(req.URL.Path, "/resources"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+
+	dir := t.TempDir()
+	client.Config.Porcelain = false
+	client.Config.OutputFormat = outputJSON
+	client.Config.OutputCSV = filepath.Join(dir, "groups.csv")
+	client.Config.OutputResourcesCSV = filepath.Join(dir, "resources.csv")
+	client.Config.CSVLayout = csvLayoutResource
+	client.Filter, _ = NewResourceGroupFilter(FilterOptions{Exclude: []string{"networkwatcherrg"}}, inventoryNow())
+
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	os.Stdout = devNull
+	err = client.FetchResourceGroups()
+	os.Stdout = stdout
+	devNull.Close()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	groups := readCSVFile(t, client.Config.OutputCSV)
+	expectedHeader := "ResourceGroupName,Location,ProvisioningState,CreatedTime,IsDefault,CreatedBy,Description," +
+		"ResourceID,ResourceName,ResourceType,ResourceLocation,ResourceCreatedTime,ResourceChangedTime"
+	if strings.Join(groups[0], ",") != expectedHeader {
+		t.Fatalf("unexpected header %v", groups[0])
+	}
+	if len(groups) != 5 {
+		t.Fatalf("Expected 4 data rows (2 resources, 1 empty group, 1 failed group), got %d: %v", len(groups)-1, groups)
+	}
+
+	if row := groups[1]; row[0] != "sandbox-alice" || row[3] != "2023-01-01T12:00:00Z" || row[8] != "app" ||
+		row[10] != "eastus" || row[11] != "2023-01-01T12:00:00Z" || row[12] != "2023-06-01T08:30:00Z" {
+		t.Errorf("unexpected first resource row %v", row)
+	}
+	if row := groups[2]; row[0] != "sandbox-alice" || row[8] != "data" || row[11] != "" || row[12] != "" {
+		t.Errorf("unexpected second resource row %v", row)
+	}
+	if row := groups[3]; row[0] != "sandbox-bob" || row[3] != "Not available" || row[7] != "" {
+		t.Errorf("unexpected row for a group without resources %v", row)
+	}
+	if row := groups[4]; row[0] != "prod-app" || !strings.HasPrefix(row[3], "Error: ") || row[7] != "" {
+		t.Errorf("unexpected row for a failed group %v", row)
+	}
+
+	resources := readCSVFile(t, client.Config.OutputResourcesCSV)
+	if strings.Join(resources[0], ",") != "ResourceGroupID,ResourceGroupName,ResourceID,ResourceName,ResourceType,ResourceLocation,ResourceCreatedTime,ResourceChangedTime" {
+		t.Fatalf("unexpected resources header %v", resources[0])
+	}
+	if len(resources) != 3 {
+		t.Fatalf("Expected 2 resource rows, got %d: %v", len(resources)-1, resources)
+	}
+	if resources[1][0] != "/subscriptions/test-subscription/resourceGroups/sandbox-alice" || resources[1][3] != "app" {
+		t.Errorf("unexpected resource row %v", resources[1])
+	}
+}
+
+func TestGroupCSVLinksToResourcesCSV(t *testing.T) {
+	dir := t.TempDir()
+	client := &AzureClient{Config: Config{
+		OutputCSV:          filepath.Join(dir, "groups.csv"),
+		OutputResourcesCSV: filepath.Join(dir, "resources.csv"),
+	}}
+
+	rg := ResourceGroup{ID: "/subscriptions/sub/resourceGroups/rg-one", Name: "rg-one"}
+	row := client.convertToCSVRow(ResourceGroupResult{ResourceGroup: rg}, false, nil)
+	if err := client.writeCSVFile([]CSVRow{row}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records := readCSVFile(t, client.Config.OutputCSV)
+	if last := records[0][len(records[0])-1]; last != "ResourceGroupID" {
+		t.Errorf("Expected ResourceGroupID as the last column, got %q", last)
+	}
+	if last := records[1][len(records[1])-1]; last != rg.ID {
+		t.Errorf("Expected group ID %q, got %q", rg.ID, last)
+	}
+}
+
+func TestValidateCSVLayout(t *testing.T) {
+	for _, layout := range []string{csvLayoutGroup, csvLayoutResource} {
+		if err := validateCSVLayout(layout); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", layout, err)
+		}
+	}
+	if err := validateCSVLayout("rows"); err == nil {
+		t.Error("Expected error for unknown layout")
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// managementEndpoint is the Azure Resource Manager endpoint all request URLs are built against
+const managementEndpoint = "https://management.azure.com"
+
+// ResourceID is a parsed Azure Resource Manager ID of the form
+// /subscriptions/{sub}/resourceGroups/{rg}/providers/{namespace}/{type}/{name}[/{childType}/{childName}...]
+// Any trailing part may be absent, so subscription and resource group IDs are represented too.
+type ResourceID struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Provider       string
+	Types          []string
+	Names          []string
+}
+
+// NewSubscriptionResourceID returns the ID of a subscription
+func NewSubscriptionResourceID(subscriptionID string) ResourceID {
+	return ResourceID{SubscriptionID: subscriptionID}
+}
+
+// NewResourceGroupResourceID returns the ID of a resource group
+func NewResourceGroupResourceID(subscriptionID, resourceGroup string) ResourceID {
+	return ResourceID{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup}
+}
+
+// ParseResourceID parses an ARM resource ID. Keywords (subscriptions, resourceGroups, providers)
+// are matched case-insensitively because Azure is not consistent about their casing.
+func ParseResourceID(id string) (ResourceID, error) {
+	var parsed ResourceID
+
+	trimmed := strings.Trim(id, "/")
+	if trimmed == "" {
+		return parsed, fmt.Errorf("invalid resource ID %q: empty", id)
+	}
+	segments := strings.Split(trimmed, "/")
+
+	if len(segments) < 2 || !strings.EqualFold(segments[0], "subscriptions") || segments[1] == "" {
+		return parsed, fmt.Errorf("invalid resource ID %q: must start with /subscriptions/{id}", id)
+	}
+	parsed.SubscriptionID = segments[1]
+	segments = segments[2:]
+
+	if len(segments) > 0 && strings.EqualFold(segments[0], "resourceGroups") {
+		if len(segments) < 2 || segments[1] == "" {
+			return parsed, fmt.Errorf("invalid resource ID %q: missing resource group name", id)
+		}
+		parsed.ResourceGroup = segments[1]
+		segments = segments[2:]
+	}
+
+	if len(segments) == 0 {
+		return parsed, nil
+	}
+
+	if !strings.EqualFold(segments[0], "providers") || len(segments) < 2 || segments[1] == "" {
+		return parsed, fmt.Errorf("invalid resource ID %q: expected providers/{namespace}", id)
+	}
+	parsed.Provider = segments[1]
+	segments = segments[2:]
+
+	if len(segments) == 0 || len(segments)%2 != 0 {
+		return parsed, fmt.Errorf("invalid resource ID %q: resource types and names must come in pairs", id)
+	}
+
+	for i := 0; i < len(segments); i += 2 {
+		if strings.EqualFold(segments[i], "providers") {
+			return parsed, fmt.Errorf("invalid resource ID %q: extension resources are not supported", id)
+		}
+		if segments[i] == "" || segments[i+1] == "" {
+			return parsed, fmt.Errorf("invalid resource ID %q: empty resource type or name", id)
+		}
+		parsed.Types = append(parsed.Types, segments[i])
+		parsed.Names = append(parsed.Names, segments[i+1])
+	}
+
+	return parsed, nil
+}
+
+// ResourceType returns the full resource type, e.g. Microsoft.Network/virtualNetworks/subnets
+func (id ResourceID) ResourceType() string {
+	if id.Provider == "" {
+		return ""
+	}
+	return strings.Join(append([]string{id.Provider}, id.Types...), "/")
+}
+
+// Name returns the name of the innermost resource the ID refers to
+func (id ResourceID) Name() string {
+	switch {
+	case len(id.Names) > 0:
+		return id.Names[len(id.Names)-1]
+	case id.ResourceGroup != "":
+		return id.ResourceGroup
+	default:
+		return id.SubscriptionID
+	}
+}
+
+// IsChild reports whether the ID refers to a child resource (e.g. a subnet of a virtual network)
+func (id ResourceID) IsChild() bool {
+	return len(id.Types) > 1
+}
+
+// Parent returns the ID of the enclosing resource, resource group or subscription.
+// The second return value is false for subscription IDs, which have no parent.
+func (id ResourceID) Parent() (ResourceID, bool) {
+	switch {
+	case len(id.Types) > 1:
+		parent := id
+		parent.Types = id.Types[: len(id.Types)-1 : len(id.Types)-1]
+		parent.Names = id.Names[: len(id.Names)-1 : len(id.Names)-1]
+		return parent, true
+	case len(id.Types) == 1 || id.Provider != "":
+		return ResourceID{SubscriptionID: id.SubscriptionID, ResourceGroup: id.ResourceGroup}, true
+	case id.ResourceGroup != "":
+		return NewSubscriptionResourceID(id.SubscriptionID), true
+	default:
+		return ResourceID{}, false
+	}
+}
+
+// ParentName returns the name of the parent resource for child resources and "" otherwise
+func (id ResourceID) ParentName() string {
+	if !id.IsChild() {
+		return ""
+	}
+	parent, _ := id.Parent()
+	return parent.Name()
+}
+
+// String returns the canonical, unescaped form of the ID
+func (id ResourceID) String() string {
+	return id.build(func(segment string) string { return segment })
+}
+
+// Path returns the ID with every variable segment escaped for use in a request URL
+func (id ResourceID) Path() string {
+	return id.build(url.PathEscape)
+}
+
+// URL builds a management API URL for the ID, optionally followed by a fixed suffix
+// (e.g. "resources" or "providers/Microsoft.Authorization/locks") and query parameters
+func (id ResourceID) URL(suffix string, query url.Values) string {
+	u := managementEndpoint + id.Path()
+	if suffix != "" {
+		u += "/" + strings.Trim(suffix, "/")
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (id ResourceID) build(escape func(string) string) string {
+	var b strings.Builder
+	b.WriteString("/subscriptions/")
+	b.WriteString(escape(id.SubscriptionID))
+	if id.ResourceGroup != "" {
+		b.WriteString("/resourceGroups/")
+		b.WriteString(escape(id.ResourceGroup))
+	}
+	if id.Provider != "" {
+		b.WriteString("/providers/")
+		b.WriteString(escape(id.Provider))
+		for i := range id.Types {
+			b.WriteString("/")
+			b.WriteString(escape(id.Types[i]))
+			b.WriteString("/")
+			b.WriteString(escape(id.Names[i]))
+		}
+	}
+	return b.String()
+}
+
+// apiVersion returns query parameters holding only the given api-version
+func apiVersion(version string) url.Values {
+	return url.Values{"api-version": []string{version}}
+}
+
+// resourcesInGroupURL builds the URL listing a resource group's resources with their creation
+// and last change times
+func (ac *AzureClient) resourcesInGroupURL(resourceGroupName string) string {
+	query := apiVersion("2019-10-01")
+	query.Set("$expand", "createdTime,changedTime")
+	return NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).URL("resources", query)
+}
+
+// ParsedID parses the resource's ID; resources with malformed IDs return an error
+func (r Resource) ParsedID() (ResourceID, error) {
+	return ParseResourceID(r.ID)
+}
+
+// ProviderNamespace returns the resource provider parsed from the resource ID,
+// falling back to the namespace prefix of the resource type
+func (r Resource) ProviderNamespace() string {
+	if id, err := r.ParsedID(); err == nil && id.Provider != "" {
+		return id.Provider
+	}
+	if i := strings.Index(r.Type, "/"); i > 0 {
+		return r.Type[:i]
+	}
+	return ""
+}
+
+// ParentName returns the name of the parent resource for child resources and "" otherwise
+func (r Resource) ParentName() string {
+	id, err := r.ParsedID()
+	if err != nil {
+		return ""
+	}
+	return id.ParentName()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResourceID(t *testing.T) {
+	testCases := []struct {
+		name          string
+		id            string
+		expectedGroup string
+		expectedType  string
+		expectedName  string
+		expectedChild bool
+		parentName    string
+	}{
+		{
+			name:          "subscription",
+			id:            "/subscriptions/sub-1",
+			expectedName:  "sub-1",
+			expectedGroup: "",
+		},
+		{
+			name:          "resource group",
+			id:            "/subscriptions/sub-1/resourceGroups/my-rg",
+			expectedGroup: "my-rg",
+			expectedName:  "my-rg",
+		},
+		{
+			name:          "top-level resource",
+			id:            "/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Storage/storageAccounts/mystorage",
+			expectedGroup: "my-rg",
+			expectedType:  "Microsoft.Storage/storageAccounts",
+			expectedName:  "mystorage",
+		},
+		{
+			name:          "child resource",
+			id:            "/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default",
+			expectedGroup: "my-rg",
+			expectedType:  "Microsoft.Network/virtualNetworks/subnets",
+			expectedName:  "default",
+			expectedChild: true,
+			parentName:    "vnet1",
+		},
+		{
+			name:          "grandchild resource with lower-case keywords",
+			id:            "/SUBSCRIPTIONS/sub-1/resourcegroups/My (RG)/providers/Microsoft.Sql/servers/sql1/databases/db1/backupShortTermRetentionPolicies/default",
+			expectedGroup: "My (RG)",
+			expectedType:  "Microsoft.Sql/servers/databases/backupShortTermRetentionPolicies",
+			expectedName:  "default",
+			expectedChild: true,
+			parentName:    "db1",
+		},
+		{
+			name:         "subscription-scoped provider resource",
+			id:           "/subscriptions/sub-1/providers/Microsoft.Authorization/locks/no-delete",
+			expectedType: "Microsoft.Authorization/locks",
+			expectedName: "no-delete",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := ParseResourceID(tc.id)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if id.SubscriptionID != "sub-1" {
+				t.Errorf("Expected subscription 'sub-1', got %q", id.SubscriptionID)
+			}
+			if id.ResourceGroup != tc.expectedGroup {
+				t.Errorf("Expected resource group %q, got %q", tc.expectedGroup, id.ResourceGroup)
+			}
+			if id.ResourceType() != tc.expectedType {
+				t.Errorf("Expected type %q, got %q", tc.expectedType, id.ResourceType())
+			}
+			if id.Name() != tc.expectedName {
+				t.Errorf("Expected name %q, got %q", tc.expectedName, id.Name())
+			}
+			if id.IsChild() != tc.expectedChild {
+				t.Errorf("Expected IsChild=%v, got %v", tc.expectedChild, id.IsChild())
+			}
+			if id.ParentName() != tc.parentName {
+				t.Errorf("Expected parent %q, got %q", tc.parentName, id.ParentName())
+			}
+
+			reparsed, err := ParseResourceID(id.String())
+			if err != nil {
+				t.Fatalf("Expected canonical ID to parse, got %v", err)
+			}
+			if reparsed.String() != id.String() {
+				t.Errorf("Expected round trip %q, got %q", id.String(), reparsed.String())
+			}
+		})
+	}
+}
+
+func TestParseResourceIDInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"/",
+		"/resourceGroups/my-rg",
+		"/subscriptions/",
+		"/subscriptions/sub-1/resourceGroups",
+		"/subscriptions/sub-1/resourceGroups/my-rg/providers",
+		"/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Web/sites",
+		"/subscriptions/sub-1/resourceGroups/my-rg/somethingElse/x",
+		"/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Web/sites/app/providers/Microsoft.Authorization/locks/l",
+	}
+
+	for _, id := range invalid {
+		if _, err := ParseResourceID(id); err == nil {
+			t.Errorf("Expected error parsing %q", id)
+		}
+	}
+}
+
+func TestResourceIDParent(t *testing.T) {
+	id, err := ParseResourceID("/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"/subscriptions/sub-1/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/vnet1",
+		"/subscriptions/sub-1/resourceGroups/my-rg",
+		"/subscriptions/sub-1",
+	}
+	for _, want := range expected {
+		parent, ok := id.Parent()
+		if !ok {
+			t.Fatalf("Expected a parent for %s", id)
+		}
+		if parent.String() != want {
+			t.Errorf("Expected parent %q, got %q", want, parent.String())
+		}
+		id = parent
+	}
+
+	if _, ok := id.Parent(); ok {
+		t.Error("Expected subscription to have no parent")
+	}
+}
+
+func TestResourceIDURLEscaping(t *testing.T) {
+	id := NewResourceGroupResourceID("sub-1", "my rg (test)#1")
+
+	url := id.URL("resources", apiVersion("2019-10-01"))
+	expected := "https://management.azure.com/subscriptions/sub-1/resourceGroups/my%20rg%20%28test%29%231/resources?api-version=2019-10-01"
+	if url != expected {
+		t.Errorf("Expected %q, got %q", expected, url)
+	}
+
+	if id.String() != "/subscriptions/sub-1/resourceGroups/my rg (test)#1" {
+		t.Errorf("Expected unescaped canonical ID, got %q", id.String())
+	}
+}
+
+func TestFetchResourcesInGroupEscapesName(t *testing.T) {
+	var requested string
+	client := &AzureClient{
+		Config: Config{SubscriptionID: "test-subscription", AccessToken: "test-token", Porcelain: true},
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				requested = req.URL.EscapedPath()
+				if req.URL.Query().Get("$expand") != "createdTime,changedTime" {
+					t.Errorf("Expected $expand=createdTime,changedTime, got query %q", req.URL.RawQuery)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"value": []}`))}, nil
+			},
+		},
+	}
+
+	if _, err := client.fetchResourcesInGroup("legacy app (old)"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(requested, "/resourceGroups/legacy%20app%20%28old%29/resources") {
+		t.Errorf("Expected escaped resource group in path, got %q", requested)
+	}
+}
+
+func TestResourceLineageInOutput(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	resources := []Resource{
+		{
+			ID:          "/subscriptions/sub-1/resourceGroups/net-rg/providers/Microsoft.Network/virtualNetworks/vnet1/subnets/default",
+			Name:        "vnet1/default",
+			Type:        "Microsoft.Network/virtualNetworks/subnets",
+			CreatedTime: &created,
+		},
+	}
+	result := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "net-rg", Location: "eastus"}}
+	client := &AzureClient{Config: Config{}}
+
+	row := client.convertToCSVRow(result, true, resources)
+	if !strings.Contains(row.Resources, "Parent: vnet1") {
+		t.Errorf("Expected parent in CSV resources field, got %q", row.Resources)
+	}
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	client.printResourceGroupResultWithResources(result, resources)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe writer: %v", err)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Provider: Microsoft.Network") || !strings.Contains(output, "Parent: vnet1") {
+		t.Errorf("Expected provider and parent in human output, got:\n%s", output)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// GroupSelection picks resource groups by name, regular expression or cleanup plan.
+// A group is selected when it matches any of the criteria.
+type GroupSelection struct {
+	Groups      []string
+	NamePattern string
+	PlanFile    string
+}
+
+// IsEmpty reports whether no selection criteria were given
+func (s GroupSelection) IsEmpty() bool {
+	for _, name := range s.Groups {
+		if strings.TrimSpace(name) != "" {
+			return false
+		}
+	}
+	return s.NamePattern == "" && s.PlanFile == ""
+}
+
+// selectResourceGroups resolves a selection against the live resource group list.
+// An empty selection selects every resource group.
+func (ac *AzureClient) selectResourceGroups(sel GroupSelection) ([]ResourceGroup, error) {
+	wanted := make(map[string]bool)
+	for _, name := range sel.Groups {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[strings.ToLower(name)] = true
+		}
+	}
+
+	if sel.PlanFile != "" {
+		plan, err := readCleanupPlan(sel.PlanFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range plan.Groups {
+			wanted[strings.ToLower(entry.Name)] = true
+		}
+	}
+
+	var pattern *regexp.Regexp
+	if sel.NamePattern != "" {
+		compiled, err := regexp.Compile(sel.NamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match pattern: %w", err)
+		}
+		pattern = compiled
+	}
+
+	resourceGroups, err := ac.listResourceGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	if sel.IsEmpty() {
+		return resourceGroups, nil
+	}
+
+	selected := make([]ResourceGroup, 0)
+	found := make(map[string]bool, len(wanted))
+	for _, rg := range resourceGroups {
+		key := strings.ToLower(rg.Name)
+		if wanted[key] || (pattern != nil && pattern.MatchString(rg.Name)) {
+			found[key] = true
+			selected = append(selected, rg)
+		}
+	}
+
+	for name := range wanted {
+		if !found[name] {
+			log.Printf("Warning: resource group %q was not found in the subscription", name)
+		}
+	}
+
+	return selected, nil
+}
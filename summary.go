@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Age buckets used by the summaries, youngest first
+const (
+	ageBucketUnder30Days = "< 30 days"
+	ageBucket30To90Days  = "30-90 days"
+	ageBucket90To365Days = "90-365 days"
+	ageBucketOverYear    = "> 1 year"
+	ageBucketUnknown     = "Unknown"
+)
+
+// ageBucketOrder is the display order of the age buckets
+var ageBucketOrder = []string{ageBucketUnder30Days, ageBucket30To90Days, ageBucket90To365Days, ageBucketOverYear, ageBucketUnknown}
+
+// userCategory is the category of resource groups that were not created by an Azure service
+const userCategory = "User-created"
+
+// defaultSummaryTop is the default number of resource types listed in the summary
+const defaultSummaryTop = 10
+
+// InventorySummary aggregates a scan for --summary and --summary-only
+type InventorySummary struct {
+	ResourceGroups         int           `json:"resourceGroups"`
+	Resources              int           `json:"resources"`
+	Locations              []LabelCount  `json:"locations"`
+	ProvisioningStates     []LabelCount  `json:"provisioningStates"`
+	Categories             []LabelCount  `json:"categories"`
+	Ages                   []LabelCount  `json:"ages"`
+	EmptyGroups            int           `json:"emptyGroups"`
+	CreatedTimeUnavailable int           `json:"createdTimeUnavailable"`
+	TopResourceTypes       []LabelCount  `json:"topResourceTypes"`
+	Errors                 SummaryErrors `json:"errors"`
+	Cost                   *CostSummary  `json:"cost,omitempty"`
+}
+
+// SummaryErrors counts failed lookups by kind
+type SummaryErrors struct {
+	Resources       int `json:"resources"`
+	Locks           int `json:"locks"`
+	Deployments     int `json:"deployments"`
+	ActivityLog     int `json:"activityLog"`
+	RoleAssignments int `json:"roleAssignments"`
+	Principals      int `json:"principals"`
+	Policy          int `json:"policy"`
+}
+
+// add counts the failed lookups of one result
+func (e *SummaryErrors) add(result ResourceGroupResult) {
+	for _, lookup := range []struct {
+		err   error
+		count *int
+	}{
+		{result.Error, &e.Resources},
+		{result.LocksError, &e.Locks},
+		{result.DeploymentsError, &e.Deployments},
+		{result.ActivityLogError, &e.ActivityLog},
+		{result.RoleAssignmentsError, &e.RoleAssignments},
+		{result.PrincipalsError, &e.Principals},
+		{result.PolicyError, &e.Policy},
+	} {
+		if lookup.err != nil {
+			*lookup.count++
+		}
+	}
+}
+
+// rows lists the failed lookups every summary format reports: resource lookups always, lock
+// lookups when locks were checked, and the optional lookups only when they failed
+func (e SummaryErrors) rows(checkLocks bool) []LabelCount {
+	rows := []LabelCount{{"Resource lookup errors", e.Resources}}
+	if checkLocks || e.Locks > 0 {
+		rows = append(rows, LabelCount{"Lock lookup errors", e.Locks})
+	}
+	for _, lookup := range []LabelCount{
+		{"Deployment lookup errors", e.Deployments},
+		{"Activity Log lookup errors", e.ActivityLog},
+		{"Role assignment lookup errors", e.RoleAssignments},
+		{"Principal lookup errors", e.Principals},
+		{"Policy lookup errors", e.Policy},
+	} {
+		if lookup.Count > 0 {
+			rows = append(rows, lookup)
+		}
+	}
+	return rows
+}
+
+// LabelCount is one row of a summary breakdown
+type LabelCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// ageBucket places a creation time in one of the age buckets
+func ageBucket(created *time.Time) string {
+	if created == nil {
+		return ageBucketUnknown
+	}
+	switch days := ageInDays(*created); {
+	case days < 30:
+		return ageBucketUnder30Days
+	case days < 90:
+		return ageBucket30To90Days
+	case days < 365:
+		return ageBucket90To365Days
+	default:
+		return ageBucketOverYear
+	}
+}
+
+// groupCategory returns the Azure service that created a default resource group, or
+// userCategory for everything else
+func groupCategory(name string) string {
+	if info := checkIfDefaultResourceGroup(name); info.IsDefault {
+		return info.CreatedBy
+	}
+	return userCategory
+}
+
+// countBy counts results by label, most common first (ties by label)
+func countBy(results []ResourceGroupResult, label func(ResourceGroupResult) string) []LabelCount {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[label(result)]++
+	}
+
+	breakdown := make([]LabelCount, 0, len(counts))
+	for l, count := range counts {
+		breakdown = append(breakdown, LabelCount{Label: l, Count: count})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Count != breakdown[j].Count {
+			return breakdown[i].Count > breakdown[j].Count
+		}
+		return breakdown[i].Label < breakdown[j].Label
+	})
+	return breakdown
+}
+
+// countByAge counts results per age bucket in bucket order, including empty buckets
+func countByAge(results []ResourceGroupResult) []LabelCount {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[ageBucket(result.CreatedTime)]++
+	}
+
+	breakdown := make([]LabelCount, 0, len(ageBucketOrder))
+	for _, bucket := range ageBucketOrder {
+		breakdown = append(breakdown, LabelCount{Label: bucket, Count: counts[bucket]})
+	}
+	return breakdown
+}
+
+// buildSummary aggregates the results; top limits the resource types listed (0 lists all).
+// Groups whose resource lookup failed count as errors, not as empty or undated groups.
+func buildSummary(results []ResourceGroupResult, top int) InventorySummary {
+	summary := InventorySummary{
+		ResourceGroups:     len(results),
+		Locations:          countBy(results, func(r ResourceGroupResult) string { return r.ResourceGroup.Location }),
+		ProvisioningStates: countBy(results, func(r ResourceGroupResult) string { return r.ResourceGroup.Properties.ProvisioningState }),
+		Categories:         countBy(results, func(r ResourceGroupResult) string { return groupCategory(r.ResourceGroup.Name) }),
+		Ages:               countByAge(results),
+	}
+
+	typeCounts := make(map[string]int)
+	for _, result := range results {
+		summary.Errors.add(result)
+		if result.Error != nil {
+			continue
+		}
+		if len(result.Resources) == 0 {
+			summary.EmptyGroups++
+		}
+		if result.CreatedTime == nil {
+			summary.CreatedTimeUnavailable++
+		}
+		summary.Resources += len(result.Resources)
+		for _, resource := range result.Resources {
+			typeCounts[resource.Type]++
+		}
+	}
+
+	summary.TopResourceTypes = make([]LabelCount, 0, len(typeCounts))
+	for resourceType, count := range typeCounts {
+		summary.TopResourceTypes = append(summary.TopResourceTypes, LabelCount{Label: resourceType, Count: count})
+	}
+	sort.Slice(summary.TopResourceTypes, func(i, j int) bool {
+		a, b := summary.TopResourceTypes[i], summary.TopResourceTypes[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Label < b.Label
+	})
+	if top > 0 && len(summary.TopResourceTypes) > top {
+		summary.TopResourceTypes = summary.TopResourceTypes[:top]
+	}
+	return summary
+}
+
+// inventorySummary builds the summary of a scan, adding the cost totals when costs were queried
+func (ac *AzureClient) inventorySummary(results []ResourceGroupResult) InventorySummary {
+	summary := buildSummary(results, ac.Config.SummaryTop)
+	summary.Cost = buildCostSummary(results, ac.Costs)
+	return summary
+}
+
+// printSummary prints the summary after the scan. Human output goes to stdout; in porcelain,
+// structured and template modes it goes to stderr so stdout stays machine-readable.
+func (ac *AzureClient) printSummary(summary InventorySummary) error {
+	if ac.humanOutput() {
+		return writeSummaryText(os.Stdout, summary, ac.Config.CheckLocks)
+	}
+	return writeSummaryText(os.Stderr, summary, ac.Config.CheckLocks)
+}
+
+// renderSummary writes the summary as JSON or YAML for --summary-only, after applying --query
+func (ac *AzureClient) renderSummary(w io.Writer, summary InventorySummary) error {
+	data, err := toGenericJSON(summary)
+	if err != nil {
+		return err
+	}
+	if ac.Query != nil {
+		data, err = ac.Query.Search(data)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate --query: %w", err)
+		}
+	}
+
+	render, ok := outputRenderers[ac.Config.OutputFormat]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", ac.Config.OutputFormat)
+	}
+	return render(w, data, ac.Config.Query)
+}
+
+// writeSummaryText writes the human-readable summary; lock lookup errors are listed when
+// checkLocks is set or a lock lookup failed
+func writeSummaryText(w io.Writer, summary InventorySummary, checkLocks bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nSummary\n")
+	fmt.Fprintf(tw, "  Resource groups:\t%d\n", summary.ResourceGroups)
+	fmt.Fprintf(tw, "  Resources:\t%d\n", summary.Resources)
+	fmt.Fprintf(tw, "  Empty groups:\t%d\n", summary.EmptyGroups)
+	fmt.Fprintf(tw, "  Creation time unavailable:\t%d\n", summary.CreatedTimeUnavailable)
+	for _, row := range summary.Errors.rows(checkLocks) {
+		fmt.Fprintf(tw, "  %s:\t%d\n", row.Label, row.Count)
+	}
+	if cost := summary.Cost; cost != nil {
+		fmt.Fprintf(tw, "\nCost (%s):\n", cost.Period)
+		fmt.Fprintf(tw, "  Total:\t%s %s\n", formatCost(cost.Total), cost.Currency)
+		fmt.Fprintf(tw, "  Created by Azure:\t%s %s\n", formatCost(cost.Default), cost.Currency)
+		fmt.Fprintf(tw, "  %s:\t%s %s\n", userCategory, formatCost(cost.User), cost.Currency)
+	}
+
+	sections := []struct {
+		title     string
+		breakdown []LabelCount
+	}{
+		{"By location", summary.Locations},
+		{"By provisioning state", summary.ProvisioningStates},
+		{"By category", summary.Categories},
+		{"By age", summary.Ages},
+		{"Top resource types", summary.TopResourceTypes},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(tw, "\n%s:\n", section.title)
+		if len(section.breakdown) == 0 {
+			fmt.Fprintf(tw, "  (none)\n")
+		}
+		for _, entry := range section.breakdown {
+			label := entry.Label
+			if label == "" {
+				label = "(none)"
+			}
+			fmt.Fprintf(tw, "  %s\t%d\n", label, entry.Count)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
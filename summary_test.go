@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAgeBucket(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	withInventoryNow(t, now)
+
+	daysAgo := func(days int) *time.Time {
+		created := now.AddDate(0, 0, -days)
+		return &created
+	}
+
+	tests := []struct {
+		created  *time.Time
+		expected string
+	}{
+		{daysAgo(0), ageBucketUnder30Days},
+		{daysAgo(29), ageBucketUnder30Days},
+		{daysAgo(30), ageBucket30To90Days},
+		{daysAgo(90), ageBucket90To365Days},
+		{daysAgo(364), ageBucket90To365Days},
+		{daysAgo(365), ageBucketOverYear},
+		{nil, ageBucketUnknown},
+	}
+	for _, tt := range tests {
+		if got := ageBucket(tt.created); got != tt.expected {
+			t.Errorf("ageBucket(%v) = %q, expected %q", tt.created, got, tt.expected)
+		}
+	}
+}
+
+func TestCountBy(t *testing.T) {
+	results := []ResourceGroupResult{
+		{ResourceGroup: ResourceGroup{Name: "a", Location: "westus"}},
+		{ResourceGroup: ResourceGroup{Name: "NetworkWatcherRG", Location: "eastus"}},
+		{ResourceGroup: ResourceGroup{Name: "b", Location: "eastus"}},
+		{ResourceGroup: ResourceGroup{Name: "c", Location: "northeurope"}},
+	}
+
+	byLocation := countBy(results, func(r ResourceGroupResult) string { return r.ResourceGroup.Location })
+	expected := []LabelCount{{"eastus", 2}, {"northeurope", 1}, {"westus", 1}}
+	if len(byLocation) != len(expected) {
+		t.Fatalf("unexpected breakdown %v", byLocation)
+	}
+	for i := range expected {
+		if byLocation[i] != expected[i] {
+			t.Errorf("entry %d: expected %v, got %v", i, expected[i], byLocation[i])
+		}
+	}
+
+	byCategory := countBy(results, func(r ResourceGroupResult) string { return groupCategory(r.ResourceGroup.Name) })
+	if byCategory[0] != (LabelCount{userCategory, 3}) || byCategory[1] != (LabelCount{"Azure Network Watcher", 1}) {
+		t.Errorf("unexpected category breakdown %v", byCategory)
+	}
+
+	byAge := countByAge(results)
+	if len(byAge) != len(ageBucketOrder) || byAge[len(byAge)-1] != (LabelCount{ageBucketUnknown, 4}) {
+		t.Errorf("unexpected age breakdown %v", byAge)
+	}
+}
+
+func TestBuildSummary(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	results := outputTestResults()
+	results[0].Resources = append(results[0].Resources,
+		Resource{Name: "web", Type: "Microsoft.Web/sites"},
+		Resource{Name: "api", Type: "Microsoft.Web/sites"})
+	failed := ResourceGroup{Name: "broken", Location: "eastus"}
+	failed.Properties.ProvisioningState = "Failed"
+	results = append(results, ResourceGroupResult{ResourceGroup: failed, Error: errors.New("boom"), LocksError: errors.New("denied"),
+		DeploymentsError: errors.New("denied"), PolicyError: errors.New("denied")})
+
+	summary := buildSummary(results, 1)
+	if summary.ResourceGroups != 3 || summary.Resources != 3 {
+		t.Errorf("unexpected totals %+v", summary)
+	}
+	if summary.EmptyGroups != 1 || summary.CreatedTimeUnavailable != 1 {
+		t.Errorf("Expected the failed group to count only as an error, got %+v", summary)
+	}
+	if summary.Errors != (SummaryErrors{Resources: 1, Locks: 1, Deployments: 1, Policy: 1}) {
+		t.Errorf("unexpected errors %+v", summary.Errors)
+	}
+	if len(summary.TopResourceTypes) != 1 || summary.TopResourceTypes[0] != (LabelCount{"Microsoft.Web/sites", 2}) {
+		t.Errorf("unexpected top resource types %v", summary.TopResourceTypes)
+	}
+	if summary.ProvisioningStates[0] != (LabelCount{"Succeeded", 2}) || summary.ProvisioningStates[1] != (LabelCount{"Failed", 1}) {
+		t.Errorf("unexpected provisioning states %v", summary.ProvisioningStates)
+	}
+	if summary.Locations[0] != (LabelCount{"eastus", 2}) {
+		t.Errorf("unexpected locations %v", summary.Locations)
+	}
+
+	var buf bytes.Buffer
+	if err := writeSummaryText(&buf, summary, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{"Resource groups:", "Top resource types:\n  Microsoft.Web/sites  2\n", "By category:", "Policy lookup errors:"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in summary:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestSummaryOnlyJSON(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/resources") {
+			return jsonResponse(http.StatusOK, `{"value": [{"name": "vm", "type": "Microsoft.Compute/virtualMachines", "createdTime": "2023-01-01T00:00:00Z"}]}`), nil
+		}
+		return jsonResponse(http.StatusOK, cleanupTestResourceGroups), nil
+	})
+	client.Config.Porcelain = false
+	client.Config.SummaryOnly = true
+	client.Config.SummaryTop = defaultSummaryTop
+	client.Config.OutputFormat = outputJSON
+
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	fetchErr := client.FetchResourceGroups()
+	if err := w.Close(); err != nil {
+		t.Errorf("Failed to close pipe writer: %v", err)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Errorf("Failed to copy output: %v", err)
+	}
+	if fetchErr != nil {
+		t.Fatalf("Expected no error, got %v", fetchErr)
+	}
+
+	var summary InventorySummary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("Expected only the summary as JSON, got %v:\n%s", err, buf.String())
+	}
+	if summary.ResourceGroups != 4 || summary.Resources != 4 || summary.EmptyGroups != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(summary.Categories) != 2 || summary.Categories[0] != (LabelCount{userCategory, 3}) {
+		t.Errorf("unexpected categories %v", summary.Categories)
+	}
+}
+
+func TestSummaryErrorsAcrossFormats(t *testing.T) {
+	failed := ResourceGroupResult{ResourceGroup: ResourceGroup{Name: "broken", Location: "eastus"}, PolicyError: errors.New("denied")}
+	summary := buildSummary([]ResourceGroupResult{failed}, 0)
+
+	var text bytes.Buffer
+	if err := writeSummaryText(&text, summary, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(text.String(), "Lock lookup errors") || !strings.Contains(text.String(), "Policy lookup errors:") {
+		t.Errorf("Expected lock errors only with --check-locks and the policy error listed:\n%s", text.String())
+	}
+	text.Reset()
+	if err := writeSummaryText(&text, summary, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(text.String(), "Lock lookup errors:") {
+		t.Errorf("Expected lock lookup errors with --check-locks:\n%s", text.String())
+	}
+
+	// Markdown and HTML count the same lookups as the text summary
+	var markdown bytes.Buffer
+	writeMarkdownSummary(&markdown, summary, false)
+	if !strings.Contains(markdown.String(), "| Resource lookup errors | 0 |\n| Policy lookup errors | 1 |\n") {
+		t.Errorf("unexpected Markdown summary:\n%s", markdown.String())
+	}
+	client := &AzureClient{}
+	report := client.buildHTMLReport([]ResourceGroupResult{failed})
+	if len(report.Errors) != 2 || report.Errors[1] != (LabelCount{"Policy lookup errors", 1}) {
+		t.Errorf("unexpected HTML error counts %v", report.Errors)
+	}
+}
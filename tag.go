@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Default tag keys written by the tag command
+const (
+	defaultCreatedTimeTag       = "inventory-created-time"
+	defaultCreatedTimeSourceTag = "inventory-created-time-source"
+	defaultLastScanTag          = "inventory-last-scan"
+	defaultCreatedByTag         = "created-by"
+)
+
+// Tag change actions shown in the diff
+const (
+	tagActionAdd    = "add"
+	tagActionUpdate = "update"
+	tagActionKeep   = "keep"
+)
+
+// TagOptions controls which resource groups are tagged and with which keys.
+// An empty key disables that tag.
+type TagOptions struct {
+	GroupSelection
+	CreatedTimeTag       string
+	CreatedTimeSourceTag string // written alongside CreatedTimeTag
+	LastScanTag          string
+	CreatedByTag         string
+	Execute              bool
+}
+
+// TagChange is a single entry of the tag diff for a resource group
+type TagChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+	Action   string
+}
+
+// TagOutcome records the computed diff, and whether it was applied, for one resource group
+type TagOutcome struct {
+	ResourceGroup string
+	Changes       []TagChange
+	Applied       bool
+	Error         error
+}
+
+// tagsPatchRequest is the body of a Microsoft.Resources/tags PATCH
+type tagsPatchRequest struct {
+	Operation  string `json:"operation"`
+	Properties struct {
+		Tags map[string]string `json:"tags"`
+	} `json:"properties"`
+}
+
+// Tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Write discovered inventory facts back to resource groups as tags",
+	Long: `Tags resource groups with the facts the inventory discovers, so they survive the run:
+the inferred creation time and the source it came from (see --created-time-sources), the
+time of the last scan and, for default resource groups, the service that created them. Existing tags are never overwritten, with the exception
+of the last-scan tag which this command owns. Runs as a dry run printing the tag diff
+unless --execute is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		createdTimeTag, _ := cmd.Flags().GetString("created-time-tag")
+		createdTimeSourceTag, _ := cmd.Flags().GetString("created-time-source-tag")
+		lastScanTag, _ := cmd.Flags().GetString("last-scan-tag")
+		createdByTag, _ := cmd.Flags().GetString("created-by-tag")
+		execute, _ := cmd.Flags().GetBool("execute")
+
+		if cmd.Flags().Changed("created-time-sources") {
+			sources, _ := cmd.Flags().GetStringSlice("created-time-sources")
+			chain, err := parseCreatedTimeSources(sources)
+			if err != nil {
+				log.Fatalf("Invalid creation time settings: %v", err)
+			}
+			azureClient.Config.CreatedTimeSources = chain
+		}
+		if cmd.Flags().Changed("created-time-tags") {
+			azureClient.Config.CreatedTimeTags, _ = cmd.Flags().GetStringSlice("created-time-tags")
+		}
+
+		if _, err := azureClient.TagResourceGroups(TagOptions{
+			GroupSelection:       GroupSelection{Groups: groups, NamePattern: match},
+			CreatedTimeTag:       createdTimeTag,
+			CreatedTimeSourceTag: createdTimeSourceTag,
+			LastScanTag:          lastScanTag,
+			CreatedByTag:         createdByTag,
+			Execute:              execute,
+		}); err != nil {
+			log.Fatalf("Error tagging resource groups: %v", err)
+		}
+	},
+}
+
+func init() {
+	tagCmd.Flags().StringSlice("group", nil, "Resource group name to tag (repeatable or comma-separated); defaults to all groups")
+	tagCmd.Flags().String("match", "", "Regular expression selecting resource group names to tag")
+	tagCmd.Flags().String("created-time-tag", defaultCreatedTimeTag, "Tag key for the inferred creation time (empty to skip)")
+	tagCmd.Flags().String("created-time-source-tag", defaultCreatedTimeSourceTag, "Tag key for the source of the inferred creation time (empty to skip)")
+	tagCmd.Flags().StringSlice("created-time-sources", defaultCreatedTimeSources, "Creation time sources to try in order: resources, tag, deployment, activity-log")
+	tagCmd.Flags().StringSlice("created-time-tags", defaultCreatedTimeTags, "Tag keys holding a creation time, read by the tag source (case-insensitive)")
+	tagCmd.Flags().String("last-scan-tag", defaultLastScanTag, "Tag key for the time of this scan (empty to skip)")
+	tagCmd.Flags().String("created-by-tag", defaultCreatedByTag, "Tag key for the detected creator of default resource groups (empty to skip)")
+	tagCmd.Flags().Bool("execute", false, "Apply the tag changes (default is a dry run)")
+
+	rootCmd.AddCommand(tagCmd)
+}
+
+// TagResourceGroups computes and, when opts.Execute is set, applies the tag diff for the selected groups
+func (ac *AzureClient) TagResourceGroups(opts TagOptions) ([]TagOutcome, error) {
+	if opts.CreatedTimeTag == "" && opts.LastScanTag == "" && opts.CreatedByTag == "" {
+		return nil, fmt.Errorf("no tags to write: all tag keys are empty")
+	}
+
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no resource groups matched the tag selection")
+	}
+
+	scanTime := time.Now().UTC()
+
+	var wg sync.WaitGroup
+	outcomes := make([]TagOutcome, len(selected))
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent tag lookups and updates
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, rg := range selected {
+		wg.Add(1)
+		go func(i int, rg ResourceGroup) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			outcomes[i] = ac.tagResourceGroup(rg, opts, scanTime)
+		}(i, rg)
+	}
+
+	wg.Wait()
+
+	if !opts.Execute {
+		fmt.Println("DRY RUN - no tags will be written. Re-run with --execute to apply.")
+	}
+
+	failed := 0
+	for _, outcome := range outcomes {
+		printTagOutcome(outcome)
+		if outcome.Error != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return outcomes, fmt.Errorf("%d of %d resource groups could not be tagged", failed, len(outcomes))
+	}
+	return outcomes, nil
+}
+
+// tagResourceGroup builds the desired tags for one group, diffs them and merges the additions
+func (ac *AzureClient) tagResourceGroup(rg ResourceGroup, opts TagOptions, scanTime time.Time) TagOutcome {
+	outcome := TagOutcome{ResourceGroup: rg.Name}
+
+	desired := make(map[string]string)
+	if opts.LastScanTag != "" {
+		desired[opts.LastScanTag] = scanTime.Format(time.RFC3339)
+	}
+	if opts.CreatedByTag != "" {
+		if info := checkIfDefaultResourceGroup(rg.Name); info.IsDefault {
+			desired[opts.CreatedByTag] = info.CreatedBy
+		}
+	}
+	// Only look up the creation time when the tag would actually be written. It is resolved
+	// through the same source chain as the inventory, so the tag matches what a run reports.
+	if opts.CreatedTimeTag != "" && !hasTagKey(rg.Tags, opts.CreatedTimeTag) {
+		result := ResourceGroupResult{ResourceGroup: rg}
+		if containsString(ac.createdTimeSources(), createdTimeSourceResources) {
+			result.Resources, result.Error = ac.fetchResourcesInGroup(rg.Name)
+		}
+		ac.resolveCreatedTime(&result)
+		if result.CreatedTime == nil && result.Error != nil {
+			outcome.Error = result.Error
+			return outcome
+		}
+		if result.CreatedTime != nil {
+			desired[opts.CreatedTimeTag] = result.CreatedTime.UTC().Format(time.RFC3339)
+			if opts.CreatedTimeSourceTag != "" {
+				desired[opts.CreatedTimeSourceTag] = result.CreatedTimeSource
+			}
+		}
+	}
+
+	outcome.Changes = diffTags(rg.Tags, desired, opts.LastScanTag)
+
+	merge := make(map[string]string)
+	for _, change := range outcome.Changes {
+		if change.Action != tagActionKeep {
+			merge[change.Key] = change.NewValue
+		}
+	}
+
+	if !opts.Execute || len(merge) == 0 {
+		return outcome
+	}
+
+	if err := ac.mergeResourceGroupTags(rg.Name, merge); err != nil {
+		outcome.Error = err
+		return outcome
+	}
+	outcome.Applied = true
+	return outcome
+}
+
+// diffTags compares desired tags against the existing ones. Tag keys are matched
+// case-insensitively as Azure does; existing values are kept, except for the
+// refreshable key which is updated when its value differs.
+func diffTags(existing, desired map[string]string, refreshable string) []TagChange {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := make([]TagChange, 0, len(keys))
+	for _, key := range keys {
+		newValue := desired[key]
+		existingKey, oldValue, found := lookupTag(existing, key)
+		switch {
+		case !found:
+			changes = append(changes, TagChange{Key: key, NewValue: newValue, Action: tagActionAdd})
+		case oldValue == newValue:
+			// Already up to date; nothing to show
+		case strings.EqualFold(key, refreshable):
+			changes = append(changes, TagChange{Key: existingKey, OldValue: oldValue, NewValue: newValue, Action: tagActionUpdate})
+		default:
+			changes = append(changes, TagChange{Key: existingKey, OldValue: oldValue, NewValue: newValue, Action: tagActionKeep})
+		}
+	}
+	return changes
+}
+
+// lookupTag finds a tag by case-insensitive key, returning the key as stored
+func lookupTag(tags map[string]string, key string) (string, string, bool) {
+	for existingKey, value := range tags {
+		if strings.EqualFold(existingKey, key) {
+			return existingKey, value, true
+		}
+	}
+	return "", "", false
+}
+
+func hasTagKey(tags map[string]string, key string) bool {
+	_, _, found := lookupTag(tags, key)
+	return found
+}
+
+// mergeResourceGroupTags merges tags into a resource group using the Tags API "Merge" operation,
+// which leaves every tag not present in the request untouched
+func (ac *AzureClient) mergeResourceGroupTags(resourceGroupName string, tags map[string]string) error {
+	url := NewResourceGroupResourceID(ac.Config.SubscriptionID, resourceGroupName).
+		URL("providers/Microsoft.Resources/tags/default", apiVersion("2021-04-01"))
+
+	var request tagsPatchRequest
+	request.Operation = "Merge"
+	request.Properties.Tags = tags
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode tag request: %w", err)
+	}
+
+	resp, err := ac.doAzureRequest("PATCH", url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		if _, err := ac.waitForAsyncOperation(resp); err != nil {
+			return fmt.Errorf("failed to update tags: %w", err)
+		}
+		return nil
+	}
+
+	closeResponseBody(resp)
+	return nil
+}
+
+// printTagOutcome prints the tag diff for one resource group
+func printTagOutcome(outcome TagOutcome) {
+	fmt.Printf("%s:\n", outcome.ResourceGroup)
+	if outcome.Error != nil {
+		fmt.Printf("  ! failed: %v\n", outcome.Error)
+		return
+	}
+	if len(outcome.Changes) == 0 {
+		fmt.Println("  (no changes)")
+		return
+	}
+
+	for _, change := range outcome.Changes {
+		switch change.Action {
+		case tagActionAdd:
+			fmt.Printf("  + %s = %s\n", change.Key, change.NewValue)
+		case tagActionUpdate:
+			fmt.Printf("  ~ %s: %s -> %s\n", change.Key, change.OldValue, change.NewValue)
+		case tagActionKeep:
+			fmt.Printf("  = %s = %s (existing value kept, discovered %s)\n", change.Key, change.OldValue, change.NewValue)
+		}
+	}
+	if outcome.Applied {
+		fmt.Println("  applied")
+	}
+}
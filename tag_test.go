@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const tagTestResourceGroups = `{
+	"value": [
+		{"id": "/subscriptions/test-subscription/resourceGroups/sandbox-alice", "name": "sandbox-alice", "location": "eastus", "tags": {"Inventory-Created-Time": "2020-01-01T00:00:00Z", "owner": "alice"}, "properties": {"provisioningState": "Succeeded"}},
+		{"id": "/subscriptions/test-subscription/resourceGroups/NetworkWatcherRG", "name": "NetworkWatcherRG", "location": "eastus", "tags": {"inventory-last-scan": "2024-01-01T00:00:00Z"}, "properties": {"provisioningState": "Succeeded"}},
+		{"id": "/subscriptions/test-subscription/resourceGroups/prod-app", "name": "prod-app", "location": "eastus", "properties": {"provisioningState": "Succeeded"}}
+	]
+}`
+
+func TestDiffTags(t *testing.T) {
+	existing := map[string]string{
+		"Created-By":          "platform-team",
+		"inventory-last-scan": "old",
+		"owner":               "alice",
+	}
+	desired := map[string]string{
+		"created-by":             "Azure Network Watcher",
+		"inventory-created-time": "2023-01-01T00:00:00Z",
+		"inventory-last-scan":    "new",
+		"owner":                  "alice",
+	}
+
+	changes := diffTags(existing, desired, "inventory-last-scan")
+
+	expected := []TagChange{
+		{Key: "Created-By", OldValue: "platform-team", NewValue: "Azure Network Watcher", Action: tagActionKeep},
+		{Key: "inventory-created-time", NewValue: "2023-01-01T00:00:00Z", Action: tagActionAdd},
+		{Key: "inventory-last-scan", OldValue: "old", NewValue: "new", Action: tagActionUpdate},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, expected[i], changes[i])
+		}
+	}
+}
+
+func TestTagResourceGroupsExecute(t *testing.T) {
+	var mu sync.Mutex
+	patches := make(map[string]tagsPatchRequest)
+	createdTimeLookups := 0
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := req.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/providers/Microsoft.Resources/tags/default"):
+			if req.Method != "PATCH" {
+				t.Errorf("Expected PATCH for tags, got %s", req.Method)
+			}
+			body, _ := io.ReadAll(req.Body)
+			var patch tagsPatchRequest
+			if err := json.Unmarshal(body, &patch); err != nil {
+				t.Errorf("invalid tag request body %s: %v", body, err)
+			}
+			group := strings.Split(path, "/")[4]
+			patches[group] = patch
+			return jsonResponse(http.StatusOK, `{}`), nil
+		case strings.HasSuffix(path, "/resources"):
+			createdTimeLookups++
+			return jsonResponse(http.StatusOK, `{"value": [{"name": "vm", "createdTime": "2023-05-01T10:00:00Z"}]}`), nil
+		case strings.Contains(path, "resourcegroups"):
+			return jsonResponse(http.StatusOK, tagTestResourceGroups), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(http.StatusBadRequest, ""), nil
+	})
+
+	outcomes, err := client.TagResourceGroups(TagOptions{
+		CreatedTimeTag:       defaultCreatedTimeTag,
+		CreatedTimeSourceTag: defaultCreatedTimeSourceTag,
+		LastScanTag:          defaultLastScanTag,
+		CreatedByTag:         defaultCreatedByTag,
+		Execute:              true,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(outcomes) != 3 {
+		t.Fatalf("Expected 3 outcomes, got %d", len(outcomes))
+	}
+
+	// sandbox-alice already carries a creation time tag, so no lookup is needed for it
+	if createdTimeLookups != 2 {
+		t.Errorf("Expected 2 creation time lookups, got %d", createdTimeLookups)
+	}
+
+	alice := patches["sandbox-alice"]
+	if alice.Operation != "Merge" {
+		t.Errorf("Expected Merge operation, got %q", alice.Operation)
+	}
+	if _, ok := alice.Properties.Tags["owner"]; ok {
+		t.Error("Expected unrelated existing tags not to be sent")
+	}
+	if len(alice.Properties.Tags) != 1 || alice.Properties.Tags[defaultLastScanTag] == "" {
+		t.Errorf("Expected only the last-scan tag for sandbox-alice, got %v", alice.Properties.Tags)
+	}
+
+	watcher := patches["NetworkWatcherRG"].Properties.Tags
+	if watcher[defaultCreatedByTag] != "Azure Network Watcher" {
+		t.Errorf("Expected created-by tag for NetworkWatcherRG, got %v", watcher)
+	}
+	if watcher[defaultCreatedTimeTag] != "2023-05-01T10:00:00Z" {
+		t.Errorf("Expected created time tag for NetworkWatcherRG, got %v", watcher)
+	}
+	if watcher[defaultCreatedTimeSourceTag] != createdTimeSourceResources {
+		t.Errorf("Expected created time source tag for NetworkWatcherRG, got %v", watcher)
+	}
+	if watcher[defaultLastScanTag] == "2024-01-01T00:00:00Z" {
+		t.Error("Expected last-scan tag to be refreshed")
+	}
+
+	if _, ok := patches["prod-app"].Properties.Tags[defaultCreatedByTag]; ok {
+		t.Error("Expected no created-by tag for a non-default group")
+	}
+
+	for _, outcome := range outcomes {
+		if !outcome.Applied {
+			t.Errorf("Expected %s to be applied", outcome.ResourceGroup)
+		}
+	}
+}
+
+func TestTagResourceGroupsDryRun(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method != "GET":
+			t.Errorf("Expected no writes in dry run, got %s %s", req.Method, req.URL)
+			return jsonResponse(http.StatusBadRequest, ""), nil
+		case strings.HasSuffix(req.URL.Path, "/resources"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, tagTestResourceGroups), nil
+	})
+
+	outcomes, err := client.TagResourceGroups(TagOptions{
+		GroupSelection: GroupSelection{Groups: []string{"prod-app"}},
+		CreatedTimeTag: defaultCreatedTimeTag,
+		CreatedByTag:   defaultCreatedByTag,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(outcomes) != 1 || outcomes[0].Applied || len(outcomes[0].Changes) != 0 {
+		t.Errorf("Expected an unapplied outcome without changes, got %+v", outcomes)
+	}
+
+	if _, err := client.TagResourceGroups(TagOptions{}); err == nil {
+		t.Error("Expected error when every tag key is empty")
+	}
+}
+
+func TestTagResourceGroupsUsesCreatedTimeSources(t *testing.T) {
+	var mu sync.Mutex
+	var patch tagsPatchRequest
+
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := req.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/providers/Microsoft.Resources/tags/default"):
+			body, _ := io.ReadAll(req.Body)
+			if err := json.Unmarshal(body, &patch); err != nil {
+				t.Errorf("invalid tag request body %s: %v", body, err)
+			}
+			return jsonResponse(http.StatusOK, `{}`), nil
+		case strings.HasSuffix(path, "/resources"):
+			t.Error("Expected no resource listing when the chain only reads tags")
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		case strings.Contains(path, "resourcegroups"):
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "/subscriptions/test-subscription/resourceGroups/legacy", "name": "legacy", "location": "eastus", "tags": {"CreatedOn": "2022-03-04"}}]}`), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(http.StatusBadRequest, ""), nil
+	})
+	client.Config.CreatedTimeSources = []string{createdTimeSourceTag}
+	client.Config.CreatedTimeTags = []string{"createdOn"}
+
+	if _, err := client.TagResourceGroups(TagOptions{
+		CreatedTimeTag:       defaultCreatedTimeTag,
+		CreatedTimeSourceTag: defaultCreatedTimeSourceTag,
+		Execute:              true,
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags := patch.Properties.Tags
+	if tags[defaultCreatedTimeTag] != "2022-03-04T00:00:00Z" || tags[defaultCreatedTimeSourceTag] != createdTimeSourceTag {
+		t.Errorf("Expected the creation time and source from the tag source, got %v", tags)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Tag audit output formats for --format
+const (
+	tagAuditFormatText = "text"
+	tagAuditFormatJSON = "json"
+)
+
+// Kinds of tag policy violation
+const (
+	tagViolationMissing    = "missing"
+	tagViolationEmpty      = "empty"
+	tagViolationNotAllowed = "not-allowed"
+	tagViolationMismatch   = "pattern-mismatch"
+)
+
+// TagPolicyConfig is the "tagPolicy" section of a tag policy config file. Example (YAML):
+//
+//	tagPolicy:
+//	  required:
+//	    - key: owner
+//	    - key: env
+//	      allowed: [dev, test, prod]
+//	      ignoreCase: true
+//	    - key: costcenter
+//	      pattern: "^CC-[0-9]{4}$"
+type TagPolicyConfig struct {
+	Required []TagRequirementConfig `mapstructure:"required"`
+}
+
+// TagRequirementConfig is one required tag key, optionally restricted to a list of allowed
+// values or to values matching a regular expression
+type TagRequirementConfig struct {
+	Key        string   `mapstructure:"key"`
+	Allowed    []string `mapstructure:"allowed"`
+	Pattern    string   `mapstructure:"pattern"`
+	IgnoreCase bool     `mapstructure:"ignoreCase"`
+}
+
+// TagPolicy is the compiled form of a TagPolicyConfig
+type TagPolicy struct {
+	requirements []requiredTag
+}
+
+type requiredTag struct {
+	key        string
+	allowed    []string
+	pattern    *regexp.Regexp
+	ignoreCase bool
+}
+
+// TagViolation is one way a resource group's tags break the policy
+type TagViolation struct {
+	Key     string `json:"key"`
+	Kind    string `json:"kind"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// TagAuditGroup is the audit result of one resource group
+type TagAuditGroup struct {
+	Name       string         `json:"name"`
+	Location   string         `json:"location"`
+	Compliant  bool           `json:"compliant"`
+	Violations []TagViolation `json:"violations"`
+}
+
+// TagKeyCompliance counts the groups violating one required key
+type TagKeyCompliance struct {
+	Key        string `json:"key"`
+	Violations int    `json:"violations"`
+}
+
+// TagAuditReport is the result of the tags audit command
+type TagAuditReport struct {
+	SubscriptionID    string             `json:"subscriptionId"`
+	Groups            int                `json:"groups"`
+	CompliantGroups   int                `json:"compliantGroups"`
+	CompliancePercent float64            `json:"compliancePercent"`
+	Keys              []TagKeyCompliance `json:"keys"`
+	Results           []TagAuditGroup    `json:"results"`
+}
+
+// TagAuditOptions controls which groups are audited against which policy
+type TagAuditOptions struct {
+	GroupSelection
+	Policy          *TagPolicy
+	IncludeDefaults bool
+}
+
+// Tags command: read-only analyses of resource group tags. It has no action of its own so that
+// it can never be mistaken for tag, which writes tags.
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Audit and analyse resource group tags (read-only; the tag command writes tags)",
+	Long: `Read-only reports on resource group tags. None of the subcommands change any tags;
+use the tag command to write inventory facts back as tags.`,
+}
+
+// Tags audit command
+var tagsAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check resource group tags against a required tag policy",
+	Long: `Evaluates every resource group's tags against the required keys and allowed values
+(an enum or a regular expression) of a tag policy config file, and reports the violations
+per group and the overall compliance percentage. Default resource groups created by Azure
+are skipped unless --include-defaults is given. With --fail-on-violation or
+--min-compliance the command exits non-zero, for use as a CI gate.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		policyFile, _ := cmd.Flags().GetString("policy")
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		includeDefaults, _ := cmd.Flags().GetBool("include-defaults")
+		format, _ := cmd.Flags().GetString("format")
+		failOnViolation, _ := cmd.Flags().GetBool("fail-on-violation")
+		minCompliance, _ := cmd.Flags().GetFloat64("min-compliance")
+
+		if format != tagAuditFormatText && format != tagAuditFormatJSON {
+			log.Fatalf("Invalid output: unsupported format %q (supported: text, json)", format)
+		}
+		policy, err := loadTagPolicy(policyFile)
+		if err != nil {
+			log.Fatalf("Failed to load tag policy: %v", err)
+		}
+
+		report, err := azureClient.AuditTags(TagAuditOptions{
+			GroupSelection:  GroupSelection{Groups: groups, NamePattern: match},
+			Policy:          policy,
+			IncludeDefaults: includeDefaults,
+		})
+		if err != nil {
+			log.Fatalf("Error auditing tags: %v", err)
+		}
+		if err := azureClient.writeTagAuditReport(os.Stdout, os.Stderr, report, format); err != nil {
+			log.Fatalf("Error writing tag audit: %v", err)
+		}
+
+		if failOnViolation && report.CompliantGroups < report.Groups {
+			log.Fatalf("Tag audit failed: %d of %d resource groups violate the tag policy", report.Groups-report.CompliantGroups, report.Groups)
+		}
+		if report.CompliancePercent < minCompliance {
+			log.Fatalf("Tag audit failed: compliance %.1f%% is below the required %.1f%%", report.CompliancePercent, minCompliance)
+		}
+	},
+}
+
+func init() {
+	tagsAuditCmd.Flags().String("policy", "", "Tag policy config file (YAML/JSON/TOML) with the required keys and allowed values")
+	tagsAuditCmd.Flags().StringSlice("group", nil, "Resource group name to audit (repeatable or comma-separated); defaults to all groups")
+	tagsAuditCmd.Flags().String("match", "", "Regular expression selecting resource group names to audit")
+	tagsAuditCmd.Flags().Bool("include-defaults", false, "Also audit default resource groups created by Azure (e.g. NetworkWatcherRG)")
+	tagsAuditCmd.Flags().String("format", tagAuditFormatText, "Report format: text or json (--porcelain prints one violation per line)")
+	tagsAuditCmd.Flags().Bool("fail-on-violation", false, "Exit non-zero if any resource group violates the policy")
+	tagsAuditCmd.Flags().Float64("min-compliance", 0, "Exit non-zero if the compliance percentage is below this value")
+	if err := tagsAuditCmd.MarkFlagRequired("policy"); err != nil {
+		log.Fatalf("Failed to mark policy flag as required: %v", err)
+	}
+
+	tagsCmd.AddCommand(tagsAuditCmd)
+	rootCmd.AddCommand(tagsCmd)
+}
+
+// loadTagPolicy reads a tag policy config file (any format viper supports: YAML, JSON, TOML)
+func loadTagPolicy(path string) (*TagPolicy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read tag policy: %w", err)
+	}
+
+	var cfg TagPolicyConfig
+	if err := v.UnmarshalKey("tagPolicy", &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse tag policy: %w", err)
+	}
+
+	return NewTagPolicy(cfg)
+}
+
+// NewTagPolicy compiles a TagPolicyConfig
+func NewTagPolicy(cfg TagPolicyConfig) (*TagPolicy, error) {
+	if len(cfg.Required) == 0 {
+		return nil, fmt.Errorf("the tag policy requires no tags")
+	}
+
+	policy := &TagPolicy{}
+	seen := make(map[string]bool, len(cfg.Required))
+	for _, requirement := range cfg.Required {
+		key := strings.TrimSpace(requirement.Key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid tag requirement: missing key")
+		}
+		if seen[strings.ToLower(key)] {
+			return nil, fmt.Errorf("tag %q is required more than once", key)
+		}
+		seen[strings.ToLower(key)] = true
+		if len(requirement.Allowed) > 0 && requirement.Pattern != "" {
+			return nil, fmt.Errorf("tag %q: use either allowed values or a pattern, not both", key)
+		}
+
+		compiled := requiredTag{key: key, allowed: requirement.Allowed, ignoreCase: requirement.IgnoreCase}
+		if requirement.Pattern != "" {
+			pattern := requirement.Pattern
+			if requirement.IgnoreCase {
+				pattern = "(?i)" + pattern
+			}
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tag %q: invalid pattern %q: %w", key, requirement.Pattern, err)
+			}
+			compiled.pattern = regex
+		}
+		policy.requirements = append(policy.requirements, compiled)
+	}
+	return policy, nil
+}
+
+// Keys returns the required keys in policy order
+func (p *TagPolicy) Keys() []string {
+	keys := make([]string, 0, len(p.requirements))
+	for _, requirement := range p.requirements {
+		keys = append(keys, requirement.key)
+	}
+	return keys
+}
+
+// Evaluate returns the violations of a set of tags, in policy order. Keys are matched
+// case-insensitively; values are compared exactly unless the requirement ignores case.
+func (p *TagPolicy) Evaluate(tags map[string]string) []TagViolation {
+	var violations []TagViolation
+	for _, requirement := range p.requirements {
+		_, value, found := lookupTag(tags, requirement.key)
+		switch {
+		case !found:
+			violations = append(violations, TagViolation{Key: requirement.key, Kind: tagViolationMissing,
+				Message: fmt.Sprintf("required tag %q is missing", requirement.key)})
+		case strings.TrimSpace(value) == "":
+			violations = append(violations, TagViolation{Key: requirement.key, Kind: tagViolationEmpty,
+				Message: fmt.Sprintf("tag %q is empty", requirement.key)})
+		case len(requirement.allowed) > 0 && !requirement.allows(value):
+			violations = append(violations, TagViolation{Key: requirement.key, Kind: tagViolationNotAllowed, Value: value,
+				Message: fmt.Sprintf("tag %q value %q is not one of %s", requirement.key, value, strings.Join(requirement.allowed, ", "))})
+		case requirement.pattern != nil && !requirement.pattern.MatchString(value):
+			violations = append(violations, TagViolation{Key: requirement.key, Kind: tagViolationMismatch, Value: value,
+				Message: fmt.Sprintf("tag %q value %q does not match %q", requirement.key, value, requirement.pattern.String())})
+		}
+	}
+	return violations
+}
+
+func (r requiredTag) allows(value string) bool {
+	for _, allowed := range r.allowed {
+		if value == allowed || r.ignoreCase && strings.EqualFold(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditTags evaluates the selected resource groups against the tag policy. Only the resource
+// group listing is needed, so no per-group API calls are made.
+func (ac *AzureClient) AuditTags(opts TagAuditOptions) (*TagAuditReport, error) {
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var audited []ResourceGroup
+	for _, rg := range selected {
+		if opts.IncludeDefaults || !checkIfDefaultResourceGroup(rg.Name).IsDefault {
+			audited = append(audited, rg)
+		}
+	}
+	return buildTagAuditReport(ac.Config.SubscriptionID, opts.Policy, audited), nil
+}
+
+// buildTagAuditReport evaluates the groups, sorted by name. With no groups the compliance is 100%.
+func buildTagAuditReport(subscriptionID string, policy *TagPolicy, resourceGroups []ResourceGroup) *TagAuditReport {
+	report := &TagAuditReport{SubscriptionID: subscriptionID, Groups: len(resourceGroups), CompliancePercent: 100}
+
+	violationsByKey := make(map[string]int)
+	for _, rg := range resourceGroups {
+		violations := policy.Evaluate(rg.Tags)
+		if violations == nil {
+			violations = []TagViolation{}
+			report.CompliantGroups++
+		}
+		for _, violation := range violations {
+			violationsByKey[violation.Key]++
+		}
+		report.Results = append(report.Results, TagAuditGroup{Name: rg.Name, Location: rg.Location, Compliant: len(violations) == 0, Violations: violations})
+	}
+	sort.Slice(report.Results, func(i, j int) bool {
+		return strings.ToLower(report.Results[i].Name) < strings.ToLower(report.Results[j].Name)
+	})
+
+	for _, key := range policy.Keys() {
+		report.Keys = append(report.Keys, TagKeyCompliance{Key: key, Violations: violationsByKey[key]})
+	}
+	if report.Groups > 0 {
+		report.CompliancePercent = math.Round(float64(report.CompliantGroups)*1000/float64(report.Groups)) / 10
+	}
+	return report
+}
+
+// writeTagAuditReport writes the report as text, JSON or porcelain lines, and the violations
+// to the --output-csv file when one is given. In porcelain mode the compliance line goes to
+// status so w stays parseable.
+func (ac *AzureClient) writeTagAuditReport(w, status io.Writer, report *TagAuditReport, format string) error {
+	if ac.Config.OutputCSV != "" {
+		if err := writeCSVRecords(ac.Config.OutputCSV, tagAuditCSVRecords(report)); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+	}
+
+	switch {
+	case ac.Config.Porcelain:
+		fmt.Fprintln(w, "NAME\tKEY\tVIOLATION\tVALUE")
+		for _, group := range report.Results {
+			for _, violation := range group.Violations {
+				writePorcelainRow(w, group.Name, violation.Key, violation.Kind, violation.Value)
+			}
+		}
+		fmt.Fprintf(status, "Tag compliance: %d of %d resource groups (%.1f%%)\n", report.CompliantGroups, report.Groups, report.CompliancePercent)
+		return nil
+	case format == tagAuditFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode tag audit: %w", err)
+		}
+		return nil
+	}
+
+	writeTagAuditText(w, report)
+	if ac.Config.OutputCSV != "" {
+		fmt.Fprintf(w, "CSV output written to: %s\n", ac.Config.OutputCSV)
+	}
+	return nil
+}
+
+func writeTagAuditText(w io.Writer, report *TagAuditReport) {
+	for _, group := range report.Results {
+		if group.Compliant {
+			continue
+		}
+		fmt.Fprintf(w, "%s (%s):\n", group.Name, group.Location)
+		for _, violation := range group.Violations {
+			fmt.Fprintf(w, "  ✗ %s\n", violation.Message)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nTag compliance: %d of %d resource groups (%.1f%%)\n", report.CompliantGroups, report.Groups, report.CompliancePercent)
+	for _, key := range report.Keys {
+		fmt.Fprintf(tw, "  %s\t%d violations\n", key.Key, key.Violations)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Printf("Warning: failed to write tag audit: %v", err)
+	}
+}
+
+// tagAuditCSVRecords returns one row per violation
+func tagAuditCSVRecords(report *TagAuditReport) [][]string {
+	records := [][]string{{"ResourceGroupName", "Location", "Key", "Violation", "Value", "Message"}}
+	for _, group := range report.Results {
+		for _, violation := range group.Violations {
+			records = append(records, []string{group.Name, group.Location, violation.Key, violation.Kind, violation.Value, violation.Message})
+		}
+	}
+	return records
+}
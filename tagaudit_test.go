@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTagPolicy = `
+tagPolicy:
+  required:
+    - key: owner
+    - key: env
+      allowed: [dev, test, prod]
+      ignoreCase: true
+    - key: costcenter
+      pattern: "^CC-[0-9]{4}$"
+`
+
+func writeTestTagPolicy(t *testing.T) *TagPolicy {
+	path := filepath.Join(t.TempDir(), "tag-policy.yaml")
+	if err := os.WriteFile(path, []byte(testTagPolicy), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	policy, err := loadTagPolicy(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return policy
+}
+
+func TestNewTagPolicyErrors(t *testing.T) {
+	tests := []TagPolicyConfig{
+		{},
+		{Required: []TagRequirementConfig{{Key: " "}}},
+		{Required: []TagRequirementConfig{{Key: "owner"}, {Key: "Owner"}}},
+		{Required: []TagRequirementConfig{{Key: "env", Allowed: []string{"dev"}, Pattern: "^dev$"}}},
+		{Required: []TagRequirementConfig{{Key: "env", Pattern: "("}}},
+	}
+	for _, cfg := range tests {
+		if _, err := NewTagPolicy(cfg); err == nil {
+			t.Errorf("Expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestTagPolicyEvaluate(t *testing.T) {
+	policy := writeTestTagPolicy(t)
+
+	tests := []struct {
+		tags     map[string]string
+		expected string
+	}{
+		{map[string]string{"Owner": "alice", "env": "PROD", "costcenter": "CC-1234"}, ""},
+		{map[string]string{"env": "staging", "costcenter": "1234"}, "owner:missing,env:not-allowed,costcenter:pattern-mismatch"},
+		{map[string]string{"owner": " ", "env": "dev", "CostCenter": "CC-0001"}, "owner:empty"},
+		{nil, "owner:missing,env:missing,costcenter:missing"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, violation := range policy.Evaluate(tt.tags) {
+			got = append(got, violation.Key+":"+violation.Kind)
+		}
+		if strings.Join(got, ",") != tt.expected {
+			t.Errorf("%v: expected %q, got %q", tt.tags, tt.expected, strings.Join(got, ","))
+		}
+	}
+}
+
+func TestAuditTags(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `{"value": [
+			{"name": "sandbox-bob", "location": "westus", "tags": {"owner": "bob", "env": "dev"}},
+			{"name": "prod-app", "location": "eastus", "tags": {"owner": "ops", "env": "prod", "costcenter": "CC-1000"}},
+			{"name": "NetworkWatcherRG", "location": "eastus"}
+		]}`), nil
+	})
+	client.Config.Porcelain = false
+	client.Config.OutputCSV = filepath.Join(t.TempDir(), "violations.csv")
+
+	report, err := client.AuditTags(TagAuditOptions{Policy: writeTestTagPolicy(t)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// NetworkWatcherRG is a default group and is skipped
+	if report.Groups != 2 || report.CompliantGroups != 1 || report.CompliancePercent != 50 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Results[0].Name != "prod-app" || report.Keys[2].Key != "costcenter" || report.Keys[2].Violations != 1 {
+		t.Errorf("unexpected results %+v, keys %+v", report.Results, report.Keys)
+	}
+
+	var buf, status bytes.Buffer
+	if err := client.writeTagAuditReport(&buf, &status, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `✗ required tag "costcenter" is missing`) || !strings.Contains(buf.String(), "Tag compliance: 1 of 2 resource groups (50.0%)") {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+	records := readCSVFile(t, client.Config.OutputCSV)
+	if len(records) != 2 || records[1][0] != "sandbox-bob" || records[1][3] != tagViolationMissing {
+		t.Errorf("unexpected CSV %v", records)
+	}
+
+	buf.Reset()
+	if err := client.writeTagAuditReport(&buf, &status, report, tagAuditFormatJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded TagAuditReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Results[0].Violations == nil {
+		t.Errorf("unexpected JSON report %s (%v)", buf.String(), err)
+	}
+
+	buf.Reset()
+	client.Config.Porcelain = true
+	if err := client.writeTagAuditReport(&buf, &status, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "NAME\tKEY\tVIOLATION\tVALUE\nsandbox-bob\tcostcenter\tmissing\t\n" {
+		t.Errorf("unexpected porcelain output %q", buf.String())
+	}
+	if status.String() != "Tag compliance: 1 of 2 resource groups (50.0%)\n" {
+		t.Errorf("expected the compliance line on the status writer, got %q", status.String())
+	}
+}
+
+func TestBuildTagAuditReportEmpty(t *testing.T) {
+	report := buildTagAuditReport("sub", writeTestTagPolicy(t), nil)
+	if report.CompliancePercent != 100 || report.Groups != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestTagsCommandIsReadOnly(t *testing.T) {
+	if tagsCmd.Run != nil || tagsCmd.RunE != nil {
+		t.Error("the tags command must not have an action of its own")
+	}
+	var names []string
+	for _, cmd := range tagsCmd.Commands() {
+		names = append(names, cmd.Name())
+	}
+	if strings.Join(names, ",") != "audit,inheritance,normalize,ttl" {
+		t.Errorf("unexpected tags subcommands %v", names)
+	}
+	if tagCmd.HasSubCommands() || len(tagCmd.Aliases) > 0 {
+		t.Error("the tag writer must stay separate from the read-only reports")
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TagGap is one group tag that a resource lacks or carries with a different value
+type TagGap struct {
+	Key           string `json:"key"`
+	GroupValue    string `json:"groupValue"`
+	ResourceValue string `json:"resourceValue,omitempty"`
+	Missing       bool   `json:"missing"`
+}
+
+// ResourceTagGaps lists the inheritance gaps of one resource
+type ResourceTagGaps struct {
+	Resource Resource
+	Gaps     []TagGap
+}
+
+// TagInheritance compares a group's tags with the tags of its resources
+type TagInheritance struct {
+	// Keys are the group tag keys that resources are expected to carry
+	Keys      []string
+	Resources int
+	Gaps      []ResourceTagGaps
+}
+
+// checkTagInheritance compares each resource's tags with the group's. Keys are matched
+// case-insensitively and values exactly. With keys, only those group tags are checked.
+func checkTagInheritance(groupTags map[string]string, resources []Resource, keys []string) *TagInheritance {
+	inheritance := &TagInheritance{Resources: len(resources)}
+	for key := range groupTags {
+		if len(keys) == 0 || containsFold(keys, key) {
+			inheritance.Keys = append(inheritance.Keys, key)
+		}
+	}
+	sort.Slice(inheritance.Keys, func(i, j int) bool {
+		return strings.ToLower(inheritance.Keys[i]) < strings.ToLower(inheritance.Keys[j])
+	})
+
+	for _, resource := range resources {
+		var gaps []TagGap
+		for _, key := range inheritance.Keys {
+			_, value, found := lookupTag(resource.Tags, key)
+			switch {
+			case !found:
+				gaps = append(gaps, TagGap{Key: key, GroupValue: groupTags[key], Missing: true})
+			case value != groupTags[key]:
+				gaps = append(gaps, TagGap{Key: key, GroupValue: groupTags[key], ResourceValue: value})
+			}
+		}
+		if len(gaps) > 0 {
+			inheritance.Gaps = append(inheritance.Gaps, ResourceTagGaps{Resource: resource, Gaps: gaps})
+		}
+	}
+	return inheritance
+}
+
+// TagInheritanceResource is a resource with inheritance gaps in the tag inheritance report
+type TagInheritanceResource struct {
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+	ID   string   `json:"id"`
+	Gaps []TagGap `json:"gaps"`
+}
+
+// TagInheritanceGroup is the inheritance check of one resource group
+type TagInheritanceGroup struct {
+	Name      string                   `json:"name"`
+	Location  string                   `json:"location"`
+	Keys      []string                 `json:"keys"`
+	Resources int                      `json:"resources"`
+	Gaps      []TagInheritanceResource `json:"gaps"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// TagInheritanceReport is the result of the tags inheritance command
+type TagInheritanceReport struct {
+	SubscriptionID    string                `json:"subscriptionId"`
+	GeneratedAt       time.Time             `json:"generatedAt"`
+	Groups            int                   `json:"groups"`
+	Resources         int                   `json:"resources"`
+	ResourcesWithGaps int                   `json:"resourcesWithGaps"`
+	Results           []TagInheritanceGroup `json:"results"`
+}
+
+// TagInheritanceOptions controls which groups are checked for which tag keys
+type TagInheritanceOptions struct {
+	GroupSelection
+	Keys            []string
+	IncludeDefaults bool
+}
+
+// Tags inheritance command
+var tagInheritanceCmd = &cobra.Command{
+	Use:   "inheritance",
+	Short: "Report resources missing their resource group's tags",
+	Long: `Compares every resource's tags with the tags of its resource group and reports the
+resources that lack a group tag or carry a different value. Keys are matched
+case-insensitively and values exactly; --keys restricts the check to some tag keys. This is
+the report behind the --tag-inheritance inventory flag. Default resource groups created by
+Azure are skipped unless --include-defaults is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, _ := cmd.Flags().GetStringSlice("keys")
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		includeDefaults, _ := cmd.Flags().GetBool("include-defaults")
+		format, _ := cmd.Flags().GetString("format")
+		failOnGaps, _ := cmd.Flags().GetBool("fail-on-gaps")
+
+		if format != tagAuditFormatText && format != tagAuditFormatJSON {
+			log.Fatalf("Invalid output: unsupported format %q (supported: text, json)", format)
+		}
+
+		report, err := azureClient.TagInheritanceReport(TagInheritanceOptions{
+			GroupSelection:  GroupSelection{Groups: groups, NamePattern: match},
+			Keys:            keys,
+			IncludeDefaults: includeDefaults,
+		})
+		if err != nil {
+			log.Fatalf("Error checking tag inheritance: %v", err)
+		}
+		if err := azureClient.writeTagInheritanceReport(os.Stdout, report, format); err != nil {
+			log.Fatalf("Error writing tag inheritance report: %v", err)
+		}
+
+		if failOnGaps && report.ResourcesWithGaps > 0 {
+			log.Fatalf("Tag inheritance check failed: %d resources are missing their group's tags", report.ResourcesWithGaps)
+		}
+	},
+}
+
+func init() {
+	tagInheritanceCmd.Flags().StringSlice("keys", nil, "Only check these group tag keys (repeatable or comma-separated); defaults to all")
+	tagInheritanceCmd.Flags().StringSlice("group", nil, "Resource group name to check (repeatable or comma-separated); defaults to all groups")
+	tagInheritanceCmd.Flags().String("match", "", "Regular expression selecting resource group names to check")
+	tagInheritanceCmd.Flags().Bool("include-defaults", false, "Also check default resource groups created by Azure (e.g. NetworkWatcherRG)")
+	tagInheritanceCmd.Flags().String("format", tagAuditFormatText, "Report format: text or json (--porcelain prints one gap per line)")
+	tagInheritanceCmd.Flags().Bool("fail-on-gaps", false, "Exit non-zero if any resource is missing its group's tags")
+
+	tagsCmd.AddCommand(tagInheritanceCmd)
+}
+
+// TagInheritanceReport lists the selected groups' resources with their inheritance gaps. A group
+// whose resources cannot be listed is reported with its error.
+func (ac *AzureClient) TagInheritanceReport(opts TagInheritanceOptions) (*TagInheritanceReport, error) {
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var checked []ResourceGroup
+	for _, rg := range selected {
+		if opts.IncludeDefaults || !checkIfDefaultResourceGroup(rg.Name).IsDefault {
+			checked = append(checked, rg)
+		}
+	}
+	sort.Slice(checked, func(i, j int) bool {
+		return strings.ToLower(checked[i].Name) < strings.ToLower(checked[j].Name)
+	})
+
+	var wg sync.WaitGroup
+	results := make([]TagInheritanceGroup, len(checked))
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent resource listings
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, rg := range checked {
+		wg.Add(1)
+		go func(i int, rg ResourceGroup) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			group := TagInheritanceGroup{Name: rg.Name, Location: rg.Location, Gaps: []TagInheritanceResource{}}
+			resources, err := ac.fetchResourcesInGroup(rg.Name)
+			if err != nil {
+				group.Error = err.Error()
+				results[i] = group
+				return
+			}
+
+			inheritance := checkTagInheritance(rg.Tags, resources, opts.Keys)
+			group.Keys, group.Resources = inheritance.Keys, inheritance.Resources
+			for _, resource := range inheritance.Gaps {
+				group.Gaps = append(group.Gaps, TagInheritanceResource{
+					Name: resource.Resource.Name,
+					Type: resource.Resource.Type,
+					ID:   resource.Resource.ID,
+					Gaps: resource.Gaps,
+				})
+			}
+			results[i] = group
+		}(i, rg)
+	}
+
+	wg.Wait()
+
+	report := &TagInheritanceReport{
+		SubscriptionID: ac.Config.SubscriptionID,
+		GeneratedAt:    time.Now().UTC(),
+		Groups:         len(results),
+		Results:        results,
+	}
+	for _, group := range results {
+		report.Resources += group.Resources
+		report.ResourcesWithGaps += len(group.Gaps)
+	}
+	return report, nil
+}
+
+// writeTagInheritanceReport writes the report as text, JSON or porcelain lines, and the gaps to
+// the --output-csv file when one is given
+func (ac *AzureClient) writeTagInheritanceReport(w io.Writer, report *TagInheritanceReport, format string) error {
+	if ac.Config.OutputCSV != "" {
+		if err := writeCSVRecords(ac.Config.OutputCSV, tagInheritanceCSVRecords(report)); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+	}
+
+	switch {
+	case ac.Config.Porcelain:
+		fmt.Fprintln(w, "NAME\tRESOURCE\tKEY\tSTATUS\tGROUP_VALUE\tRESOURCE_VALUE")
+		for _, record := range tagInheritanceCSVRecords(report)[1:] {
+			writePorcelainRow(w, record...)
+		}
+		return nil
+	case format == tagAuditFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode tag inheritance report: %w", err)
+		}
+		return nil
+	}
+
+	for _, group := range report.Results {
+		switch {
+		case group.Error != "":
+			fmt.Fprintf(w, "%s (%s): Error fetching resources (%s)\n", group.Name, group.Location, group.Error)
+		case len(group.Gaps) > 0:
+			fmt.Fprintf(w, "%s (%s): %d of %d resources\n", group.Name, group.Location, len(group.Gaps), group.Resources)
+			for _, resource := range group.Gaps {
+				fmt.Fprintf(w, "  - %s (%s): %s\n", resource.Name, resource.Type, formatTagGaps(resource.Gaps))
+			}
+		}
+	}
+	fmt.Fprintf(w, "Tag inheritance: %d of %d resources in %d resource groups are missing group tags or carry different values\n",
+		report.ResourcesWithGaps, report.Resources, report.Groups)
+	if ac.Config.OutputCSV != "" {
+		fmt.Fprintf(w, "CSV output written to: %s\n", ac.Config.OutputCSV)
+	}
+	return nil
+}
+
+// tagInheritanceCSVRecords returns one row per resource gap, and one per group that failed
+func tagInheritanceCSVRecords(report *TagInheritanceReport) [][]string {
+	records := [][]string{{"ResourceGroupName", "Resource", "Key", "Status", "GroupValue", "ResourceValue"}}
+	for _, group := range report.Results {
+		if group.Error != "" {
+			records = append(records, []string{group.Name, "", "", "error", "", group.Error})
+			continue
+		}
+		for _, resource := range group.Gaps {
+			for _, gap := range resource.Gaps {
+				status := "different"
+				if gap.Missing {
+					status = "missing"
+				}
+				records = append(records, []string{group.Name, resource.Name, gap.Key, status, gap.GroupValue, gap.ResourceValue})
+			}
+		}
+	}
+	return records
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTagGaps renders a resource's gaps as "owner missing, env=PROD (group: prod)"
+func formatTagGaps(gaps []TagGap) string {
+	parts := make([]string, 0, len(gaps))
+	for _, gap := range gaps {
+		if gap.Missing {
+			parts = append(parts, gap.Key+" missing")
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%s (group: %s)", gap.Key, gap.ResourceValue, gap.GroupValue))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func columnTagGapResources(r ResourceGroupResult, style columnStyle) string {
+	switch {
+	case r.Error != nil && style == columnStyleCSV:
+		return fmt.Sprintf("Error: %v", r.Error)
+	case r.Error != nil:
+		return "ERROR"
+	case r.TagInheritance != nil:
+		return strconv.Itoa(len(r.TagInheritance.Gaps))
+	case style == columnStyleCSV:
+		return ""
+	default:
+		return "N/A"
+	}
+}
+
+func columnTagGaps(r ResourceGroupResult, style columnStyle) string {
+	if r.Error != nil || r.TagInheritance == nil {
+		return columnTagGapResources(r, style)
+	}
+	if len(r.TagInheritance.Gaps) == 0 && style != columnStyleCSV {
+		return "none"
+	}
+	parts := make([]string, 0, len(r.TagInheritance.Gaps))
+	for _, resource := range r.TagInheritance.Gaps {
+		parts = append(parts, resource.Resource.Name+": "+formatTagGaps(resource.Gaps))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// tagInheritanceColumns returns the extra porcelain header columns when --tag-inheritance is set
+func (ac *AzureClient) tagInheritanceColumns() []string {
+	if !ac.Config.TagInheritance {
+		return nil
+	}
+	return []string{"TAG_GAP_RESOURCES", "TAG_GAPS"}
+}
+
+// tagInheritanceValues returns the porcelain values matching tagInheritanceColumns
+func (ac *AzureClient) tagInheritanceValues(result ResourceGroupResult) []string {
+	if !ac.Config.TagInheritance {
+		return nil
+	}
+	return []string{columnTagGapResources(result, columnStylePorcelain), columnTagGaps(result, columnStylePorcelain)}
+}
+
+// printTagInheritance prints the resources missing the group's tags in the human-readable output
+func (ac *AzureClient) printTagInheritance(result ResourceGroupResult) {
+	inheritance := result.TagInheritance
+	if !ac.Config.TagInheritance || inheritance == nil {
+		return
+	}
+	switch {
+	case len(inheritance.Keys) == 0:
+		fmt.Printf("  🏷️ Tag inheritance: the group has no tags to inherit\n")
+	case len(inheritance.Gaps) == 0:
+		fmt.Printf("  🏷️ Tag inheritance: all %d resources carry the group's tags\n", inheritance.Resources)
+	default:
+		fmt.Printf("  🏷️ Tag inheritance: %d of %d resources are missing group tags or carry different values\n",
+			len(inheritance.Gaps), inheritance.Resources)
+		for _, resource := range inheritance.Gaps {
+			fmt.Printf("    - %s (%s): %s\n", resource.Resource.Name, resource.Resource.Type, formatTagGaps(resource.Gaps))
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckTagInheritance(t *testing.T) {
+	groupTags := map[string]string{"owner": "ops", "env": "prod"}
+	resources := []Resource{
+		{Name: "web", Tags: map[string]string{"Owner": "ops", "env": "prod"}},
+		{Name: "db", Tags: map[string]string{"env": "PROD"}},
+		{Name: "disk"},
+	}
+
+	inheritance := checkTagInheritance(groupTags, resources, nil)
+	if inheritance.Resources != 3 || strings.Join(inheritance.Keys, ",") != "env,owner" || len(inheritance.Gaps) != 2 {
+		t.Fatalf("unexpected inheritance %+v", inheritance)
+	}
+	if got := formatTagGaps(inheritance.Gaps[0].Gaps); inheritance.Gaps[0].Resource.Name != "db" || got != "env=PROD (group: prod), owner missing" {
+		t.Errorf("unexpected db gaps %q", got)
+	}
+	if got := formatTagGaps(inheritance.Gaps[1].Gaps); got != "env missing, owner missing" {
+		t.Errorf("unexpected disk gaps %q", got)
+	}
+
+	inheritance = checkTagInheritance(groupTags, resources, []string{"OWNER", "costcenter"})
+	if strings.Join(inheritance.Keys, ",") != "owner" || len(inheritance.Gaps) != 2 || len(inheritance.Gaps[0].Gaps) != 1 {
+		t.Errorf("unexpected restricted inheritance %+v", inheritance)
+	}
+
+	if inheritance := checkTagInheritance(nil, resources, nil); len(inheritance.Keys) != 0 || inheritance.Gaps != nil {
+		t.Errorf("expected no gaps for an untagged group, got %+v", inheritance)
+	}
+}
+
+func TestTagInheritanceOutputs(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/resourceGroups/prod-app/resources") {
+			return jsonResponse(http.StatusOK, `{"value": [
+				{"id": "/subscriptions/test-subscription/resourceGroups/prod-app/providers/Microsoft.Web/sites/web", "name": "web", "type": "Microsoft.Web/sites", "tags": {"owner": "ops"}},
+				{"id": "/subscriptions/test-subscription/resourceGroups/prod-app/providers/Microsoft.Sql/servers/db", "name": "db", "type": "Microsoft.Sql/servers", "tags": {"owner": "dba"}}
+			]}`), nil
+		}
+		if strings.Contains(req.URL.Path, "/resourceGroups/sandbox-bob/resources") {
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": []}`), nil
+	})
+	client.Config.TagInheritance = true
+
+	groups := []ResourceGroup{
+		{ID: "/subscriptions/test-subscription/resourceGroups/prod-app", Name: "prod-app", Tags: map[string]string{"owner": "ops"}},
+		{Name: "sandbox-bob", Tags: map[string]string{"owner": "bob"}},
+	}
+	results := client.collectResourceGroupResults(groups, true, "")
+	if len(results) != 2 || results[0].TagInheritance == nil || results[1].TagInheritance != nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	if got := strings.Join(client.tagInheritanceValues(results[0]), "\t"); got != "1\tdb: owner=dba (group: ops)" {
+		t.Errorf("unexpected porcelain values %q", got)
+	}
+	if got := columnTagGapResources(results[1], columnStylePorcelain); got != "ERROR" {
+		t.Errorf("expected ERROR for a failed resource listing, got %q", got)
+	}
+	if got := columnTagGaps(ResourceGroupResult{TagInheritance: &TagInheritance{}}, columnStylePorcelain); got != "none" {
+		t.Errorf("expected none, got %q", got)
+	}
+	if got := columnTagGaps(ResourceGroupResult{}, columnStyleCSV); got != "" {
+		t.Errorf("expected an empty CSV value, got %q", got)
+	}
+
+	inventory := client.buildInventory(results, true)
+	if inventory[0].TagGapResources == nil || *inventory[0].TagGapResources != 1 {
+		t.Fatalf("unexpected inventory %+v", inventory[0])
+	}
+	if inventory[0].Resources[0].TagGaps != nil || len(inventory[0].Resources[1].TagGaps) != 1 || inventory[0].Resources[1].TagGaps[0].ResourceValue != "dba" {
+		t.Errorf("unexpected inventory resources %+v", inventory[0].Resources)
+	}
+
+	headers := columnHeaders(client.csvGroupColumns(), columnStyleCSV)
+	if strings.Join(headers[len(headers)-2:], ",") != "TagGapResources,TagGaps" {
+		t.Errorf("unexpected CSV group columns %v", headers)
+	}
+}
+
+func TestTagInheritanceListsResourcesWithoutGlobalState(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/resources") {
+			return jsonResponse(http.StatusOK, `{"value": [{"id": "/subscriptions/test-subscription/resourceGroups/prod-app/providers/Microsoft.Web/sites/web", "name": "web", "type": "Microsoft.Web/sites"}]}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": [{"id": "/subscriptions/test-subscription/resourceGroups/prod-app", "name": "prod-app", "location": "eastus", "tags": {"owner": "ops"}}]}`), nil
+	})
+	client.Config.Porcelain = false
+	client.Config.OutputFormat = outputJSON
+	client.Config.TagInheritance = true
+
+	old := os.Stdout
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		t.Fatalf("failed to create pipe: %v", pipeErr)
+	}
+	os.Stdout = w
+
+	err := client.FetchResourceGroups()
+
+	if closeErr := w.Close(); closeErr != nil {
+		t.Errorf("Failed to close pipe writer: %v", closeErr)
+	}
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Errorf("Failed to copy output: %v", copyErr)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"tagGaps"`) {
+		t.Errorf("expected the resources with their tag gaps, got:\n%s", buf.String())
+	}
+	if viper.GetBool("list-resources") {
+		t.Error("--tag-inheritance must not change the global list-resources setting")
+	}
+}
+
+func TestTagInheritanceReport(t *testing.T) {
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/sandbox-alice/resources"):
+			return jsonResponse(http.StatusOK, `{"value": [
+				{"name": "web", "type": "Microsoft.Web/sites", "tags": {"owner": "alice"}},
+				{"name": "db", "type": "Microsoft.Sql/servers", "tags": {"Owner": "bob\tsmith"}}
+			]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/sandbox-bob/resources"):
+			return jsonResponse(http.StatusForbidden, `{"error": "denied"}`), nil
+		case strings.HasSuffix(req.URL.Path, "/resources"):
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"value": [
+			{"name": "sandbox-bob", "location": "westus", "tags": {"owner": "bob"}},
+			{"name": "sandbox-alice", "location": "eastus", "tags": {"owner": "alice"}},
+			{"name": "NetworkWatcherRG", "location": "eastus", "tags": {"owner": "azure"}}
+		]}`), nil
+	})
+
+	report, err := client.TagInheritanceReport(TagInheritanceOptions{Keys: []string{"owner"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Groups != 2 || report.Resources != 2 || report.ResourcesWithGaps != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Results[0].Name != "sandbox-alice" || report.Results[1].Error == "" {
+		t.Errorf("expected groups sorted by name with the failed listing reported, got %+v", report.Results)
+	}
+
+	var buf bytes.Buffer
+	if err := client.writeTagInheritanceReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "NAME\tRESOURCE\tKEY\tSTATUS\tGROUP_VALUE\tRESOURCE_VALUE\n" +
+		"sandbox-alice\tdb\towner\tdifferent\talice\tbob smith\n" +
+		"sandbox-bob\t\t\terror\t\t" + report.Results[1].Error + "\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected porcelain output:\n%q", buf.String())
+	}
+
+	client.Config.Porcelain = false
+	buf.Reset()
+	if err := client.writeTagInheritanceReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "  - db (Microsoft.Sql/servers): owner=bob\tsmith (group: alice)\n") ||
+		!strings.Contains(buf.String(), "Tag inheritance: 1 of 2 resources in 2 resource groups") {
+		t.Errorf("unexpected text output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,696 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// Why a tag key or value variant was grouped with its canonical form
+const (
+	tagVariantCanonical  = "canonical"
+	tagVariantWhitespace = "whitespace"
+	tagVariantCase       = "case"
+	tagVariantSeparator  = "separator"
+	tagVariantSimilar    = "similar"
+)
+
+// minSimilarTokenLength is the shortest normalised token that is compared by edit distance;
+// shorter tokens ("env", "dev") are too easily one edit apart
+const minSimilarTokenLength = 5
+
+// TagVariant is one spelling of a tag key or value
+type TagVariant struct {
+	Value    string   `json:"value"`
+	Reason   string   `json:"reason"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+// TagKeyCluster is a set of tag keys that differ only by case, whitespace, separators or a
+// small edit distance
+type TagKeyCluster struct {
+	Canonical string       `json:"canonical"`
+	Variants  []TagVariant `json:"variants"`
+}
+
+// TagValueCluster is a set of inconsistent values of one (canonical) tag key
+type TagValueCluster struct {
+	Key       string       `json:"key"`
+	Canonical string       `json:"canonical"`
+	Variants  []TagVariant `json:"variants"`
+}
+
+// TagNormalizationReport is the result of the tags normalize command
+type TagNormalizationReport struct {
+	SubscriptionID string            `json:"subscriptionId"`
+	Groups         int               `json:"groups"`
+	Resources      int               `json:"resources"`
+	Keys           []TagKeyCluster   `json:"keys"`
+	Values         []TagValueCluster `json:"values"`
+	Changes        []TagRewrite      `json:"-"`
+}
+
+// TagRewrite changes one tag on one resource group or resource to its canonical key and value
+type TagRewrite struct {
+	Scope         string `json:"scope"`
+	ResourceGroup string `json:"resourceGroup"`
+	Key           string `json:"key"`
+	NewKey        string `json:"newKey"`
+	Value         string `json:"value"`
+	NewValue      string `json:"newValue"`
+	Conflict      string `json:"conflict,omitempty"`
+}
+
+// TagKeyMapping maps key variants to their canonical key
+type TagKeyMapping struct {
+	Canonical string   `json:"canonical"`
+	Variants  []string `json:"variants"`
+}
+
+// TagValueMapping maps value variants of a key to their canonical value
+type TagValueMapping struct {
+	Key       string   `json:"key"`
+	Canonical string   `json:"canonical"`
+	Variants  []string `json:"variants"`
+}
+
+// TagNormalizationPlan is the remediation plan written by --plan
+type TagNormalizationPlan struct {
+	SubscriptionID string            `json:"subscriptionId"`
+	Keys           []TagKeyMapping   `json:"keys"`
+	Values         []TagValueMapping `json:"values"`
+	Changes        []TagRewrite      `json:"changes"`
+}
+
+// TagNormalizeOptions controls which tags are analysed and how variants are clustered
+type TagNormalizeOptions struct {
+	GroupSelection
+	IncludeDefaults  bool
+	IncludeResources bool
+	MaxDistance      int
+	ValueDistance    int
+	Canonical        []string
+	Examples         int
+}
+
+// Tags normalize command
+var tagsNormalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Report inconsistent tag keys and values and plan their normalisation",
+	Long: `Clusters the tag keys used across resource groups (and, with --include-resources, their
+resources) that differ only by case, surrounding or repeated whitespace, separators (- _ .)
+or a small edit distance, and reports each cluster's variants with their counts and example
+groups. The values of each key are clustered the same way, by edit distance only when
+--value-distance is set (similar values such as two e-mail addresses are often distinct).
+The most common spelling is canonical unless --canonical names it. With --plan a JSON
+remediation plan mapping every variant to its canonical form, and listing the tags to
+rewrite, is written; nothing is changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		includeDefaults, _ := cmd.Flags().GetBool("include-defaults")
+		includeResources, _ := cmd.Flags().GetBool("include-resources")
+		maxDistance, _ := cmd.Flags().GetInt("max-distance")
+		valueDistance, _ := cmd.Flags().GetInt("value-distance")
+		canonical, _ := cmd.Flags().GetStringSlice("canonical")
+		examples, _ := cmd.Flags().GetInt("examples")
+		planFile, _ := cmd.Flags().GetString("plan")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != tagAuditFormatText && format != tagAuditFormatJSON {
+			log.Fatalf("Invalid output: unsupported format %q (supported: text, json)", format)
+		}
+		if maxDistance < 0 || valueDistance < 0 {
+			log.Fatalf("Invalid --max-distance/--value-distance: must not be negative")
+		}
+		if examples < 1 {
+			log.Fatalf("Invalid --examples: must be at least 1")
+		}
+
+		report, err := azureClient.NormalizeTags(TagNormalizeOptions{
+			GroupSelection:   GroupSelection{Groups: groups, NamePattern: match},
+			IncludeDefaults:  includeDefaults,
+			IncludeResources: includeResources,
+			MaxDistance:      maxDistance,
+			ValueDistance:    valueDistance,
+			Canonical:        canonical,
+			Examples:         examples,
+		})
+		if err != nil {
+			log.Fatalf("Error analysing tags: %v", err)
+		}
+		if err := azureClient.writeTagNormalizationReport(os.Stdout, report, format); err != nil {
+			log.Fatalf("Error writing tag report: %v", err)
+		}
+
+		if planFile != "" {
+			if err := writeTagNormalizationPlan(planFile, buildTagNormalizationPlan(report)); err != nil {
+				log.Fatalf("Error writing remediation plan: %v", err)
+			}
+			log.Printf("Remediation plan with %d tag changes written to: %s", len(report.Changes), planFile)
+		}
+	},
+}
+
+func init() {
+	tagsNormalizeCmd.Flags().StringSlice("group", nil, "Resource group name to analyse (repeatable or comma-separated); defaults to all groups")
+	tagsNormalizeCmd.Flags().String("match", "", "Regular expression selecting resource group names to analyse")
+	tagsNormalizeCmd.Flags().Bool("include-defaults", false, "Also analyse default resource groups created by Azure (e.g. NetworkWatcherRG)")
+	tagsNormalizeCmd.Flags().Bool("include-resources", false, "Also analyse the tags of every resource in the selected groups (one extra API call per group)")
+	tagsNormalizeCmd.Flags().Int("max-distance", 1, "Cluster tag keys of at least 5 characters within this edit distance (0 disables)")
+	tagsNormalizeCmd.Flags().Int("value-distance", 0, "Cluster tag values of at least 5 characters within this edit distance (0 disables)")
+	tagsNormalizeCmd.Flags().StringSlice("canonical", nil, "Preferred spelling of a tag key (repeatable or comma-separated, e.g. Owner,CostCenter)")
+	tagsNormalizeCmd.Flags().Int("examples", 3, "Example resource groups listed per variant")
+	tagsNormalizeCmd.Flags().String("plan", "", "Write a JSON remediation plan mapping variants to their canonical form")
+	tagsNormalizeCmd.Flags().String("format", tagAuditFormatText, "Report format: text or json (--porcelain prints one variant per line)")
+
+	tagsCmd.AddCommand(tagsNormalizeCmd)
+}
+
+// taggedObject is a resource group or resource and its tags
+type taggedObject struct {
+	scope         string
+	resourceGroup string
+	tags          map[string]string
+}
+
+// variantStats counts the uses of one spelling and remembers a few example groups
+type variantStats struct {
+	count    int
+	examples []string
+}
+
+func (s *variantStats) add(group string, limit int) {
+	s.count++
+	if len(s.examples) < limit && !containsString(s.examples, group) {
+		s.examples = append(s.examples, group)
+	}
+}
+
+// NormalizeTags collects the tags of the selected groups (and their resources) and clusters
+// inconsistent keys and values
+func (ac *AzureClient) NormalizeTags(opts TagNormalizeOptions) (*TagNormalizationReport, error) {
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var analysed []ResourceGroup
+	for _, rg := range selected {
+		if opts.IncludeDefaults || !checkIfDefaultResourceGroup(rg.Name).IsDefault {
+			analysed = append(analysed, rg)
+		}
+	}
+
+	objects := make([]taggedObject, 0, len(analysed))
+	for _, rg := range analysed {
+		objects = append(objects, taggedObject{
+			scope:         NewResourceGroupResourceID(ac.Config.SubscriptionID, rg.Name).String(),
+			resourceGroup: rg.Name,
+			tags:          rg.Tags,
+		})
+	}
+	resources := 0
+	if opts.IncludeResources {
+		resourceObjects := ac.fetchResourceTags(analysed)
+		resources = len(resourceObjects)
+		objects = append(objects, resourceObjects...)
+	}
+
+	report := buildTagNormalizationReport(objects, opts)
+	report.SubscriptionID = ac.Config.SubscriptionID
+	report.Groups = len(analysed)
+	report.Resources = resources
+	return report, nil
+}
+
+// fetchResourceTags lists the resources of each group with bounded concurrency. A group whose
+// resources cannot be listed is skipped with a warning.
+func (ac *AzureClient) fetchResourceTags(resourceGroups []ResourceGroup) []taggedObject {
+	var wg sync.WaitGroup
+	perGroup := make([][]taggedObject, len(resourceGroups))
+
+	// Ensure MaxConcurrency is at least 1 to prevent hanging
+	maxConcurrency := validateConcurrency(ac.Config.MaxConcurrency)
+
+	// Use a semaphore to limit concurrent resource listings
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	for i, rg := range resourceGroups {
+		wg.Add(1)
+		go func(i int, rg ResourceGroup) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			resources, err := ac.fetchResourcesInGroup(rg.Name)
+			if err != nil {
+				log.Printf("Warning: failed to list resources in %s: %v", rg.Name, err)
+				return
+			}
+			for _, resource := range resources {
+				perGroup[i] = append(perGroup[i], taggedObject{scope: resource.ID, resourceGroup: rg.Name, tags: resource.Tags})
+			}
+		}(i, rg)
+	}
+
+	wg.Wait()
+
+	var objects []taggedObject
+	for _, group := range perGroup {
+		objects = append(objects, group...)
+	}
+	return objects
+}
+
+// buildTagNormalizationReport clusters the keys, then the values of each canonical key, and
+// lists the changes that would normalise every tag
+func buildTagNormalizationReport(objects []taggedObject, opts TagNormalizeOptions) *TagNormalizationReport {
+	report := &TagNormalizationReport{Keys: []TagKeyCluster{}, Values: []TagValueCluster{}}
+
+	keyStats := make(map[string]*variantStats)
+	for _, object := range objects {
+		for key := range object.tags {
+			if keyStats[key] == nil {
+				keyStats[key] = &variantStats{}
+			}
+			keyStats[key].add(object.resourceGroup, opts.Examples)
+		}
+	}
+
+	canonicalKeys := make(map[string]string)
+	for _, cluster := range clusterTagVariants(keyStats, opts.MaxDistance) {
+		canonical := chooseCanonicalVariant(cluster, keyStats, opts.Canonical)
+		for _, variant := range cluster {
+			canonicalKeys[variant] = canonical
+		}
+		if variants := tagVariants(cluster, canonical, keyStats); variants != nil {
+			report.Keys = append(report.Keys, TagKeyCluster{Canonical: canonical, Variants: variants})
+		}
+	}
+
+	valueStats := make(map[string]map[string]*variantStats)
+	for _, object := range objects {
+		for key, value := range object.tags {
+			canonicalKey := canonicalKeys[key]
+			if valueStats[canonicalKey] == nil {
+				valueStats[canonicalKey] = make(map[string]*variantStats)
+			}
+			if valueStats[canonicalKey][value] == nil {
+				valueStats[canonicalKey][value] = &variantStats{}
+			}
+			valueStats[canonicalKey][value].add(object.resourceGroup, opts.Examples)
+		}
+	}
+
+	canonicalValues := make(map[string]map[string]string)
+	for key, stats := range valueStats {
+		canonicalValues[key] = make(map[string]string)
+		for _, cluster := range clusterTagVariants(stats, opts.ValueDistance) {
+			canonical := chooseCanonicalVariant(cluster, stats, nil)
+			for _, variant := range cluster {
+				canonicalValues[key][variant] = canonical
+			}
+			if variants := tagVariants(cluster, canonical, stats); variants != nil {
+				report.Values = append(report.Values, TagValueCluster{Key: key, Canonical: canonical, Variants: variants})
+			}
+		}
+	}
+
+	sort.Slice(report.Keys, func(i, j int) bool {
+		return lessTagCluster(report.Keys[i].Variants, report.Keys[j].Variants, report.Keys[i].Canonical, report.Keys[j].Canonical)
+	})
+	sort.Slice(report.Values, func(i, j int) bool {
+		a, b := report.Values[i], report.Values[j]
+		if !strings.EqualFold(a.Key, b.Key) {
+			return strings.ToLower(a.Key) < strings.ToLower(b.Key)
+		}
+		return lessTagCluster(a.Variants, b.Variants, a.Canonical, b.Canonical)
+	})
+
+	for _, object := range objects {
+		report.Changes = append(report.Changes, tagChanges(object, canonicalKeys, canonicalValues)...)
+	}
+	return report
+}
+
+// tagChanges returns the rewrites of one object's tags. Two keys that collapse to the same
+// canonical key with different values cannot both be kept; those changes are marked as conflicts.
+func tagChanges(object taggedObject, canonicalKeys map[string]string, canonicalValues map[string]map[string]string) []TagRewrite {
+	keys := make([]string, 0, len(object.tags))
+	for key := range object.tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sources := make(map[string][]string)
+	for _, key := range keys {
+		sources[canonicalKeys[key]] = append(sources[canonicalKeys[key]], key)
+	}
+
+	var changes []TagRewrite
+	for _, key := range keys {
+		newKey := canonicalKeys[key]
+		value := object.tags[key]
+		newValue := canonicalValues[newKey][value]
+		if key == newKey && value == newValue {
+			continue
+		}
+
+		change := TagRewrite{Scope: object.scope, ResourceGroup: object.resourceGroup, Key: key, NewKey: newKey, Value: value, NewValue: newValue}
+		for _, other := range sources[newKey] {
+			if other != key && canonicalValues[newKey][object.tags[other]] != newValue {
+				change.Conflict = fmt.Sprintf("%q also maps to %q with value %q", other, newKey, object.tags[other])
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// normalizeTagToken folds case and drops whitespace and separators, so "Cost Center",
+// "cost-center" and "COST_CENTER" compare equal
+func normalizeTagToken(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '_' || r == '.' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, value)
+}
+
+// clusterTagVariants groups spellings with the same normalised token, then merges groups whose
+// tokens are within maxDistance edits. Clusters and their members are sorted.
+func clusterTagVariants(stats map[string]*variantStats, maxDistance int) [][]string {
+	byToken := make(map[string][]string)
+	for variant := range stats {
+		token := normalizeTagToken(variant)
+		byToken[token] = append(byToken[token], variant)
+	}
+	tokens := make([]string, 0, len(byToken))
+	for token := range byToken {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	// Union-find over the tokens
+	parent := make([]int, len(tokens))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range tokens {
+		for j := i + 1; j < len(tokens); j++ {
+			if similarTagTokens(tokens[i], tokens[j], maxDistance) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]string)
+	var roots []int
+	for i, token := range tokens {
+		root := find(i)
+		if members[root] == nil {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], byToken[token]...)
+	}
+
+	clusters := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		sort.Strings(members[root])
+		clusters = append(clusters, members[root])
+	}
+	return clusters
+}
+
+func similarTagTokens(a, b string, maxDistance int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if maxDistance <= 0 || len(ra) < minSimilarTokenLength || len(rb) < minSimilarTokenLength {
+		return false
+	}
+	if diff := len(ra) - len(rb); diff > maxDistance || -diff > maxDistance {
+		return false
+	}
+	return editDistance(ra, rb) <= maxDistance
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// chooseCanonicalVariant returns the preferred spelling matching the cluster if one is given,
+// otherwise the most used spelling without stray whitespace (ties broken alphabetically)
+func chooseCanonicalVariant(cluster []string, stats map[string]*variantStats, preferred []string) string {
+	for _, candidate := range preferred {
+		candidate = strings.TrimSpace(candidate)
+		for _, variant := range cluster {
+			if candidate != "" && normalizeTagToken(candidate) == normalizeTagToken(variant) {
+				return candidate
+			}
+		}
+	}
+
+	best := ""
+	for _, variant := range cluster {
+		if best == "" || betterCanonical(variant, best, stats) {
+			best = variant
+		}
+	}
+	return best
+}
+
+func betterCanonical(a, b string, stats map[string]*variantStats) bool {
+	cleanA, cleanB := collapseWhitespace(a) == a, collapseWhitespace(b) == b
+	switch {
+	case cleanA != cleanB:
+		return cleanA
+	case stats[a].count != stats[b].count:
+		return stats[a].count > stats[b].count
+	}
+	return a < b
+}
+
+func collapseWhitespace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// tagVariantReason explains how a variant differs from the canonical spelling
+func tagVariantReason(variant, canonical string) string {
+	collapsed := collapseWhitespace(variant)
+	switch {
+	case variant == canonical:
+		return tagVariantCanonical
+	case collapsed == canonical:
+		return tagVariantWhitespace
+	case strings.EqualFold(collapsed, canonical):
+		return tagVariantCase
+	case normalizeTagToken(variant) == normalizeTagToken(canonical):
+		return tagVariantSeparator
+	default:
+		return tagVariantSimilar
+	}
+}
+
+// tagVariants returns the cluster's variants, canonical first and then by count, or nil when
+// every member already uses the canonical spelling
+func tagVariants(cluster []string, canonical string, stats map[string]*variantStats) []TagVariant {
+	if len(cluster) == 1 && cluster[0] == canonical {
+		return nil
+	}
+
+	variants := make([]TagVariant, 0, len(cluster))
+	for _, variant := range cluster {
+		variants = append(variants, TagVariant{
+			Value:    variant,
+			Reason:   tagVariantReason(variant, canonical),
+			Count:    stats[variant].count,
+			Examples: stats[variant].examples,
+		})
+	}
+	sort.SliceStable(variants, func(i, j int) bool {
+		a, b := variants[i], variants[j]
+		if (a.Reason == tagVariantCanonical) != (b.Reason == tagVariantCanonical) {
+			return a.Reason == tagVariantCanonical
+		}
+		return a.Count > b.Count
+	})
+	return variants
+}
+
+// lessTagCluster orders clusters by total uses, most used first, then by canonical spelling
+func lessTagCluster(a, b []TagVariant, canonicalA, canonicalB string) bool {
+	totalA, totalB := tagVariantTotal(a), tagVariantTotal(b)
+	if totalA != totalB {
+		return totalA > totalB
+	}
+	return canonicalA < canonicalB
+}
+
+func tagVariantTotal(variants []TagVariant) int {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Count
+	}
+	return total
+}
+
+// writeTagNormalizationReport writes the report as text, JSON or porcelain lines, and the
+// variants to the --output-csv file when one is given
+func (ac *AzureClient) writeTagNormalizationReport(w io.Writer, report *TagNormalizationReport, format string) error {
+	if ac.Config.OutputCSV != "" {
+		if err := writeCSVRecords(ac.Config.OutputCSV, tagNormalizationCSVRecords(report)); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+	}
+
+	switch {
+	case ac.Config.Porcelain:
+		records := tagNormalizationCSVRecords(report)
+		fmt.Fprintln(w, "KIND\tKEY\tCANONICAL\tVARIANT\tREASON\tCOUNT\tEXAMPLES")
+		for _, record := range records[1:] {
+			writePorcelainRow(w, record...)
+		}
+		return nil
+	case format == tagAuditFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode tag report: %w", err)
+		}
+		return nil
+	}
+
+	writeTagNormalizationText(w, report)
+	if ac.Config.OutputCSV != "" {
+		fmt.Fprintf(w, "CSV output written to: %s\n", ac.Config.OutputCSV)
+	}
+	return nil
+}
+
+func writeTagNormalizationText(w io.Writer, report *TagNormalizationReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(report.Keys) > 0 {
+		fmt.Fprintln(tw, "Inconsistent tag keys:")
+		for _, cluster := range report.Keys {
+			fmt.Fprintf(tw, "  %s\n", cluster.Canonical)
+			writeTagVariantLines(tw, cluster.Variants)
+		}
+	}
+	if len(report.Values) > 0 {
+		fmt.Fprintln(tw, "Inconsistent tag values:")
+		for _, cluster := range report.Values {
+			fmt.Fprintf(tw, "  %s = %s\n", cluster.Key, cluster.Canonical)
+			writeTagVariantLines(tw, cluster.Variants)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		log.Printf("Warning: failed to write tag report: %v", err)
+	}
+
+	scanned := fmt.Sprintf("%d resource groups", report.Groups)
+	if report.Resources > 0 {
+		scanned += fmt.Sprintf(" and %d resources", report.Resources)
+	}
+	fmt.Fprintf(w, "\nScanned %s: %d inconsistent keys, %d inconsistent values, %d tags to normalise\n",
+		scanned, len(report.Keys), len(report.Values), len(report.Changes))
+}
+
+func writeTagVariantLines(w io.Writer, variants []TagVariant) {
+	for _, variant := range variants {
+		fmt.Fprintf(w, "    %q\t%s\t%d\t%s\n", variant.Value, variant.Reason, variant.Count, strings.Join(variant.Examples, ", "))
+	}
+}
+
+// tagNormalizationCSVRecords returns one row per key or value variant
+func tagNormalizationCSVRecords(report *TagNormalizationReport) [][]string {
+	records := [][]string{{"Kind", "Key", "Canonical", "Variant", "Reason", "Count", "Examples"}}
+	for _, cluster := range report.Keys {
+		for _, variant := range cluster.Variants {
+			records = append(records, []string{"key", cluster.Canonical, cluster.Canonical, variant.Value, variant.Reason,
+				strconv.Itoa(variant.Count), strings.Join(variant.Examples, ",")})
+		}
+	}
+	for _, cluster := range report.Values {
+		for _, variant := range cluster.Variants {
+			records = append(records, []string{"value", cluster.Key, cluster.Canonical, variant.Value, variant.Reason,
+				strconv.Itoa(variant.Count), strings.Join(variant.Examples, ",")})
+		}
+	}
+	return records
+}
+
+// buildTagNormalizationPlan maps each non-canonical variant to its canonical form
+func buildTagNormalizationPlan(report *TagNormalizationReport) *TagNormalizationPlan {
+	plan := &TagNormalizationPlan{SubscriptionID: report.SubscriptionID, Keys: []TagKeyMapping{}, Values: []TagValueMapping{}, Changes: report.Changes}
+	for _, cluster := range report.Keys {
+		plan.Keys = append(plan.Keys, TagKeyMapping{Canonical: cluster.Canonical, Variants: nonCanonicalVariants(cluster.Variants)})
+	}
+	for _, cluster := range report.Values {
+		plan.Values = append(plan.Values, TagValueMapping{Key: cluster.Key, Canonical: cluster.Canonical, Variants: nonCanonicalVariants(cluster.Variants)})
+	}
+	if plan.Changes == nil {
+		plan.Changes = []TagRewrite{}
+	}
+	return plan
+}
+
+func nonCanonicalVariants(variants []TagVariant) []string {
+	var values []string
+	for _, variant := range variants {
+		if variant.Reason != tagVariantCanonical {
+			values = append(values, variant.Value)
+		}
+	}
+	return values
+}
+
+// writeTagNormalizationPlan writes the plan as indented JSON so it can be reviewed and committed
+func writeTagNormalizationPlan(path string, plan *TagNormalizationPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode remediation plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write remediation plan: %w", err)
+	}
+	return nil
+}
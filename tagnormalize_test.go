@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const normalizeTestResourceGroups = `{"value": [
+	{"name": "sandbox-alice", "location": "westus", "tags": {"Owner": "alice", "env": "Prod"}},
+	{"name": "sandbox-bob", "location": "westus", "tags": {"owner": "bob", "env": "prod"}},
+	{"name": "prod-app", "location": "eastus", "tags": {"owner ": "ops", "Env": "prod", "cost-center": "CC1"}},
+	{"name": "data-app", "location": "eastus", "tags": {"owner": "data", "owners": "dave", "CostCenter": "CC1"}},
+	{"name": "NetworkWatcherRG", "location": "eastus", "tags": {"OWNER": "azure"}}
+]}`
+
+func newNormalizeTestClient() *AzureClient {
+	return newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/resourceGroups/prod-app/resources") {
+			return jsonResponse(http.StatusOK, `{"value": [
+				{"id": "/subscriptions/test-subscription/resourceGroups/prod-app/providers/Microsoft.Web/sites/web", "name": "web", "tags": {"ENV": "PROD"}}
+			]}`), nil
+		}
+		if strings.HasSuffix(req.URL.Path, "/resources") {
+			return jsonResponse(http.StatusOK, `{"value": []}`), nil
+		}
+		return jsonResponse(http.StatusOK, normalizeTestResourceGroups), nil
+	})
+}
+
+func variantSummary(variants []TagVariant) string {
+	var parts []string
+	for _, variant := range variants {
+		parts = append(parts, variant.Value+":"+variant.Reason+":"+strings.Repeat("#", variant.Count))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestNormalizeTags(t *testing.T) {
+	client := newNormalizeTestClient()
+	client.Config.Porcelain = false
+
+	report, err := client.NormalizeTags(TagNormalizeOptions{MaxDistance: 1, Examples: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Groups != 4 || report.Resources != 0 || len(report.Keys) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if got := variantSummary(report.Keys[0].Variants); report.Keys[0].Canonical != "owner" || got != "owner:canonical:##,Owner:case:#,owner :whitespace:#,owners:similar:#" {
+		t.Errorf("unexpected owner cluster %s", got)
+	}
+	if report.Keys[1].Canonical != "env" || report.Keys[2].Canonical != "CostCenter" || report.Keys[2].Variants[1].Reason != tagVariantSeparator {
+		t.Errorf("unexpected key clusters %+v", report.Keys)
+	}
+	if strings.Join(report.Keys[0].Variants[0].Examples, ",") != "sandbox-bob,data-app" {
+		t.Errorf("unexpected examples %v", report.Keys[0].Variants[0].Examples)
+	}
+	if len(report.Values) != 1 || report.Values[0].Key != "env" || variantSummary(report.Values[0].Variants) != "prod:canonical:##,Prod:case:#" {
+		t.Errorf("unexpected value clusters %+v", report.Values)
+	}
+
+	var conflicts []string
+	for _, change := range report.Changes {
+		if change.Conflict != "" {
+			conflicts = append(conflicts, change.ResourceGroup+":"+change.Key)
+		}
+	}
+	if len(report.Changes) != 6 || strings.Join(conflicts, ",") != "data-app:owners" {
+		t.Errorf("unexpected changes %+v", report.Changes)
+	}
+
+	var buf bytes.Buffer
+	if err := client.writeTagNormalizationReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Scanned 4 resource groups: 3 inconsistent keys, 1 inconsistent values, 6 tags to normalise") {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := writeTagNormalizationPlan(path, buildTagNormalizationPlan(report)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var plan TagNormalizationPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		t.Fatalf("invalid plan %s: %v", data, err)
+	}
+	if strings.Join(plan.Keys[0].Variants, ",") != "Owner,owner ,owners" || plan.Values[0].Canonical != "prod" || len(plan.Changes) != 6 {
+		t.Errorf("unexpected plan %s", data)
+	}
+}
+
+func TestNormalizeTagsOptions(t *testing.T) {
+	client := newNormalizeTestClient()
+
+	report, err := client.NormalizeTags(TagNormalizeOptions{
+		IncludeResources: true,
+		IncludeDefaults:  true,
+		Canonical:        []string{"Owner"},
+		Examples:         1,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Groups != 5 || report.Resources != 1 {
+		t.Errorf("unexpected counts %d groups, %d resources", report.Groups, report.Resources)
+	}
+	// --max-distance 0 keeps "owners" apart; OWNER comes from the default group
+	if got := variantSummary(report.Keys[0].Variants); report.Keys[0].Canonical != "Owner" || got != "Owner:canonical:#,owner:case:##,OWNER:case:#,owner :case:#" {
+		t.Errorf("unexpected owner cluster %s %s", report.Keys[0].Canonical, got)
+	}
+	if got := variantSummary(report.Keys[1].Variants); got != "env:canonical:##,ENV:case:#,Env:case:#" {
+		t.Errorf("unexpected env cluster %s", got)
+	}
+	if len(report.Keys[0].Variants[0].Examples) != 1 {
+		t.Errorf("expected a single example, got %v", report.Keys[0].Variants[0].Examples)
+	}
+
+	client.Config.Porcelain = true
+	var buf bytes.Buffer
+	if err := client.writeTagNormalizationReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "value\tenv\tprod\tPROD\tcase\t1\tprod-app\n") {
+		t.Errorf("unexpected porcelain output %q", buf.String())
+	}
+}
+
+func TestSimilarTagTokens(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+		expected bool
+	}{
+		{"owner", "owners", 1, true},
+		{"costcenter", "costcentre", 1, false},
+		{"costcenter", "costcentre", 2, true},
+		{"env", "dev", 1, false},
+		{"owner", "owners", 0, false},
+	}
+	for _, tt := range tests {
+		if got := similarTagTokens(tt.a, tt.b, tt.distance); got != tt.expected {
+			t.Errorf("similarTagTokens(%q, %q, %d) = %v", tt.a, tt.b, tt.distance, got)
+		}
+	}
+	if normalizeTagToken(" Cost_Center.ID ") != "costcenterid" {
+		t.Errorf("unexpected token %q", normalizeTagToken(" Cost_Center.ID "))
+	}
+}
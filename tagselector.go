@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tag selector requirement operators
+const (
+	tagOpExists    = "exists"
+	tagOpNotExists = "!exists"
+	tagOpIn        = "in"
+	tagOpNotIn     = "notin"
+)
+
+var tagSetRequirementPattern = regexp.MustCompile(`(?i)^(.+?)\s+(in|notin)\s*\((.*)\)$`)
+
+// TagSelector filters resource groups by their ARM tags. A selector is a comma-separated
+// list of requirements that must all hold, in the style of Kubernetes label selectors:
+//
+//	owner               the tag is present
+//	!owner              the tag is absent
+//	env=dev             the tag has the value (env==dev is also accepted)
+//	env!=prod           the tag is absent or has another value
+//	env in (dev,test)   the tag has one of the values
+//	env notin (prod)    the tag is absent or has none of the values
+//
+// Values are compared exactly; keys are compared exactly unless ignoreKeyCase is set.
+type TagSelector struct {
+	raw           string
+	requirements  []tagRequirement
+	ignoreKeyCase bool
+}
+
+type tagRequirement struct {
+	key    string
+	op     string
+	values []string
+}
+
+// ParseTagSelector parses a tag selector expression
+func ParseTagSelector(expr string, ignoreKeyCase bool) (*TagSelector, error) {
+	selector := &TagSelector{raw: expr, ignoreKeyCase: ignoreKeyCase}
+
+	parts, err := splitSelector(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag selector %q: %w", expr, err)
+	}
+
+	for _, part := range parts {
+		requirement, err := parseTagRequirement(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag selector %q: %w", expr, err)
+		}
+		selector.requirements = append(selector.requirements, requirement)
+	}
+
+	return selector, nil
+}
+
+// splitSelector splits on commas that are not inside parentheses
+func splitSelector(expr string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	parts = append(parts, expr[start:])
+
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if parts[i] == "" {
+			return nil, fmt.Errorf("empty requirement")
+		}
+	}
+	return parts, nil
+}
+
+func parseTagRequirement(part string) (tagRequirement, error) {
+	if match := tagSetRequirementPattern.FindStringSubmatch(part); match != nil {
+		requirement := tagRequirement{key: strings.TrimSpace(match[1]), op: strings.ToLower(match[2])}
+		for _, value := range strings.Split(match[3], ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				requirement.values = append(requirement.values, value)
+			}
+		}
+		if len(requirement.values) == 0 {
+			return tagRequirement{}, fmt.Errorf("%q has an empty value list", part)
+		}
+		return requirement, nil
+	}
+
+	if key, value, found := strings.Cut(part, "!="); found {
+		return newValueRequirement(key, tagOpNotIn, value)
+	}
+	if key, value, found := strings.Cut(part, "=="); found {
+		return newValueRequirement(key, tagOpIn, value)
+	}
+	if key, value, found := strings.Cut(part, "="); found {
+		return newValueRequirement(key, tagOpIn, value)
+	}
+
+	if key, found := strings.CutPrefix(part, "!"); found {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return tagRequirement{}, fmt.Errorf("missing key after '!'")
+		}
+		return tagRequirement{key: key, op: tagOpNotExists}, nil
+	}
+
+	if strings.ContainsAny(part, "()") {
+		return tagRequirement{}, fmt.Errorf("cannot parse %q", part)
+	}
+	return tagRequirement{key: part, op: tagOpExists}, nil
+}
+
+func newValueRequirement(key, op, value string) (tagRequirement, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return tagRequirement{}, fmt.Errorf("missing key")
+	}
+	return tagRequirement{key: key, op: op, values: []string{strings.TrimSpace(value)}}, nil
+}
+
+// Matches reports whether the tags satisfy every requirement of the selector
+func (s *TagSelector) Matches(tags map[string]string) bool {
+	for _, requirement := range s.requirements {
+		value, found := s.lookup(tags, requirement.key)
+		switch requirement.op {
+		case tagOpExists:
+			if !found {
+				return false
+			}
+		case tagOpNotExists:
+			if found {
+				return false
+			}
+		case tagOpIn:
+			if !found || !containsString(requirement.values, value) {
+				return false
+			}
+		case tagOpNotIn:
+			if found && containsString(requirement.values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Keys returns the tag keys the selector refers to
+func (s *TagSelector) Keys() []string {
+	keys := make([]string, 0, len(s.requirements))
+	for _, requirement := range s.requirements {
+		keys = append(keys, requirement.key)
+	}
+	return keys
+}
+
+func (s *TagSelector) lookup(tags map[string]string, key string) (string, bool) {
+	if s.ignoreKeyCase {
+		_, value, found := lookupTag(tags, key)
+		return value, found
+	}
+	value, found := tags[key]
+	return value, found
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// TagKeyCount counts how many resource groups lack a tag key
+type TagKeyCount struct {
+	Key     string
+	Missing int
+	Present int
+}
+
+// missingTagKeys counts, for every tag key used on any of the resource groups (plus extraKeys,
+// e.g. the keys referenced by tag selectors), how many groups do not carry it. Keys missing from
+// no group are left out; the rest are sorted with the most often missing first.
+func missingTagKeys(results []ResourceGroupResult, extraKeys []string, ignoreKeyCase bool) []TagKeyCount {
+	normalize := func(key string) string {
+		if ignoreKeyCase {
+			return strings.ToLower(key)
+		}
+		return key
+	}
+
+	// Remember the first spelling of each key for display
+	displayKeys := make(map[string]string)
+	addKey := func(key string) {
+		if _, ok := displayKeys[normalize(key)]; !ok {
+			displayKeys[normalize(key)] = key
+		}
+	}
+	for _, result := range results {
+		keys := make([]string, 0, len(result.ResourceGroup.Tags))
+		for key := range result.ResourceGroup.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			addKey(key)
+		}
+	}
+	for _, key := range extraKeys {
+		addKey(key)
+	}
+
+	present := make(map[string]int, len(displayKeys))
+	for _, result := range results {
+		seen := make(map[string]bool, len(result.ResourceGroup.Tags))
+		for key := range result.ResourceGroup.Tags {
+			seen[normalize(key)] = true
+		}
+		for key := range seen {
+			present[key]++
+		}
+	}
+
+	counts := make([]TagKeyCount, 0, len(displayKeys))
+	for normalized, key := range displayKeys {
+		if missing := len(results) - present[normalized]; missing > 0 {
+			counts = append(counts, TagKeyCount{Key: key, Missing: missing, Present: present[normalized]})
+		}
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Missing != counts[j].Missing {
+			return counts[i].Missing > counts[j].Missing
+		}
+		return counts[i].Key < counts[j].Key
+	})
+	return counts
+}
+
+// printMissingTagsReport prints the missing tag keys. In porcelain and structured output modes
+// the report goes to stderr as tab-separated values so stdout stays machine-readable.
+func (ac *AzureClient) printMissingTagsReport(counts []TagKeyCount, total int) {
+	if !ac.humanOutput() {
+		fmt.Fprintln(os.Stderr, "TAG_KEY\tMISSING\tPRESENT")
+		for _, count := range counts {
+			fmt.Fprintf(os.Stderr, "%s\t%d\t%d\n", count.Key, count.Missing, count.Present)
+		}
+		return
+	}
+
+	fmt.Printf("\nMissing tag keys (across %d resource groups):\n", total)
+	if len(counts) == 0 {
+		fmt.Println("  Every tag key is present on every resource group")
+		return
+	}
+	for _, count := range counts {
+		fmt.Printf("  %-30s missing on %d (%.1f%%)\n", count.Key, count.Missing, float64(count.Missing)*100/float64(total))
+	}
+}
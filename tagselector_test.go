@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTagSelectorMatches(t *testing.T) {
+	tags := map[string]string{"Owner": "alice", "env": "dev"}
+
+	testCases := []struct {
+		expr          string
+		ignoreKeyCase bool
+		expected      bool
+	}{
+		{"Owner", false, true},
+		{"owner", false, false},
+		{"owner", true, true},
+		{"!owner", false, true},
+		{"!owner", true, false},
+		{"env=dev", false, true},
+		{"env==dev", false, true},
+		{"env=Dev", false, false},
+		{"env!=prod", false, true},
+		{"cost-center!=shared", false, true},
+		{"env in (dev,test)", false, true},
+		{"env IN (test, prod)", false, false},
+		{"env notin (prod)", false, true},
+		{"region notin (eu)", false, true},
+		{"env in (dev,test),Owner=alice", false, true},
+		{"env in (dev,test), !Owner", false, false},
+	}
+
+	for _, tc := range testCases {
+		selector, err := ParseTagSelector(tc.expr, tc.ignoreKeyCase)
+		if err != nil {
+			t.Fatalf("ParseTagSelector(%q) returned error: %v", tc.expr, err)
+		}
+		if got := selector.Matches(tags); got != tc.expected {
+			t.Errorf("%q (ignoreKeyCase=%v): expected %v, got %v", tc.expr, tc.ignoreKeyCase, tc.expected, got)
+		}
+	}
+}
+
+func TestParseTagSelectorErrors(t *testing.T) {
+	for _, expr := range []string{"", "!", "=dev", "env in ()", "env in (dev", "owner,,env", "env)"} {
+		if _, err := ParseTagSelector(expr, false); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestTagSelectorsCombineWithFilters(t *testing.T) {
+	filter, err := NewResourceGroupFilter(FilterOptions{
+		Include:      []string{"sandbox-*"},
+		TagSelectors: []string{"!owner", "env in (dev,test)"},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	groups := []ResourceGroup{
+		{Name: "sandbox-a", Tags: map[string]string{"env": "dev"}},
+		{Name: "sandbox-b", Tags: map[string]string{"env": "dev", "owner": "bob"}},
+		{Name: "sandbox-c", Tags: map[string]string{"env": "prod"}},
+		{Name: "prod-d", Tags: map[string]string{"env": "test"}},
+	}
+	filtered := filter.FilterGroups(groups)
+	if len(filtered) != 1 || filtered[0].Name != "sandbox-a" {
+		t.Errorf("Expected only sandbox-a, got %+v", filtered)
+	}
+
+	keys := filter.TagKeys()
+	if len(keys) != 2 || keys[0] != "owner" || keys[1] != "env" {
+		t.Errorf("unexpected selector keys %v", keys)
+	}
+}
+
+func TestMissingTagKeys(t *testing.T) {
+	results := []ResourceGroupResult{
+		{ResourceGroup: ResourceGroup{Name: "a", Tags: map[string]string{"env": "dev", "Owner": "alice"}}},
+		{ResourceGroup: ResourceGroup{Name: "b", Tags: map[string]string{"env": "dev", "owner": "bob"}}},
+		{ResourceGroup: ResourceGroup{Name: "c", Tags: map[string]string{"env": "prod"}}},
+		{ResourceGroup: ResourceGroup{Name: "d"}},
+	}
+
+	counts := missingTagKeys(results, []string{"cost-center"}, true)
+	expected := []TagKeyCount{
+		{Key: "cost-center", Missing: 4, Present: 0},
+		{Key: "Owner", Missing: 2, Present: 2},
+		{Key: "env", Missing: 1, Present: 3},
+	}
+	if len(counts) != len(expected) {
+		t.Fatalf("Expected %d counts, got %+v", len(expected), counts)
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Errorf("count %d: expected %+v, got %+v", i, expected[i], counts[i])
+		}
+	}
+
+	// Without case folding "Owner" and "owner" are distinct keys
+	counts = missingTagKeys(results, nil, false)
+	if len(counts) != 3 || counts[0].Missing != 3 {
+		t.Errorf("Expected case-sensitive keys to be counted separately, got %+v", counts)
+	}
+}
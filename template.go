@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template scopes for --template-scope
+const (
+	templateScopeGroup     = "group"
+	templateScopeInventory = "inventory"
+)
+
+// TemplateInventory is the data passed to an inventory-scoped template
+type TemplateInventory struct {
+	SubscriptionID string
+	GeneratedAt    time.Time
+	Groups         []InventoryGroup
+}
+
+// templateFuncs are the helper functions available to --template and --template-file
+var templateFuncs = template.FuncMap{
+	"date":        templateDate,
+	"age":         templateAge,
+	"ageDays":     templateAgeDays,
+	"join":        templateJoin,
+	"keys":        templateKeys,
+	"tag":         templateTag,
+	"default":     templateDefault,
+	"defaultInfo": checkIfDefaultResourceGroup,
+	"upper":       strings.ToUpper,
+	"lower":       strings.ToLower,
+	"now":         func() time.Time { return inventoryNow() },
+}
+
+// loadOutputTemplate parses the template given inline or read from a file (not both)
+func loadOutputTemplate(text, path, scope string) (*template.Template, error) {
+	if text != "" && path != "" {
+		return nil, fmt.Errorf("--template and --template-file cannot be used together")
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	if scope != templateScopeGroup && scope != templateScopeInventory {
+		return nil, fmt.Errorf("unsupported template scope %q (supported: group, inventory)", scope)
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate executes the output template once per resource group, or once for the whole
+// inventory when the scope is "inventory". Per-group output is terminated with a newline if the
+// template does not end with one.
+func (ac *AzureClient) renderTemplate(w io.Writer, results []ResourceGroupResult, listResources bool) error {
+	inventory := ac.buildInventory(results, listResources)
+
+	if ac.Config.TemplateScope == templateScopeInventory {
+		data := TemplateInventory{
+			SubscriptionID: ac.Config.SubscriptionID,
+			GeneratedAt:    inventoryNow().UTC(),
+			Groups:         inventory,
+		}
+		if err := ac.Template.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, group := range inventory {
+		buf.Reset()
+		if err := ac.Template.Execute(&buf, group); err != nil {
+			return fmt.Errorf("failed to render template for %s: %w", group.Name, err)
+		}
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write template output: %w", err)
+		}
+	}
+	return nil
+}
+
+// templateTime accepts the time representations found in template data
+func templateTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	}
+	return time.Time{}, false
+}
+
+// templateDate formats a time with a Go layout, e.g. {{ .CreatedTime | date "2006-01-02" }};
+// unknown times render as ""
+func templateDate(layout string, value interface{}) string {
+	t, ok := templateTime(value)
+	if !ok {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// templateAgeDays returns whole days since the time, or -1 when it is unknown
+func templateAgeDays(value interface{}) int {
+	t, ok := templateTime(value)
+	if !ok {
+		return -1
+	}
+	return ageInDays(t)
+}
+
+// templateAge describes the age of a time, e.g. "1y 47d", "12d" or "unknown"
+func templateAge(value interface{}) string {
+	days := templateAgeDays(value)
+	switch {
+	case days < 0:
+		return "unknown"
+	case days >= 365:
+		return fmt.Sprintf("%dy %dd", days/365, days%365)
+	default:
+		return fmt.Sprintf("%dd", days)
+	}
+}
+
+// templateJoin joins a list of strings (or of any values) with a separator,
+// e.g. {{ keys .Tags | join ", " }}
+func templateJoin(sep string, value interface{}) string {
+	switch list := value.(type) {
+	case []string:
+		return strings.Join(list, sep)
+	case []interface{}:
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// templateKeys returns the sorted keys of a tag map
+func templateKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templateTag looks up a tag by case-insensitive key, e.g. {{ tag .Tags "owner" }}
+func templateTag(tags map[string]string, key string) string {
+	_, value, _ := lookupTag(tags, key)
+	return value
+}
+
+// templateDefault returns value, or fallback when value is empty, e.g. {{ tag .Tags "owner" | default "unowned" }}
+func templateDefault(fallback string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	case *time.Time:
+		if v == nil {
+			return fallback
+		}
+	}
+	return value
+}
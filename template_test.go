@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withInventoryNow(t *testing.T, now time.Time) {
+	t.Helper()
+	old := inventoryNow
+	inventoryNow = func() time.Time { return now }
+	t.Cleanup(func() { inventoryNow = old })
+}
+
+func TestRenderTemplatePerGroup(t *testing.T) {
+	withInventoryNow(t, time.Date(2024, 2, 17, 12, 0, 0, 0, time.UTC))
+
+	tmpl, err := loadOutputTemplate(
+		`{{.Name}} created {{.CreatedTime | date "2006-01-02" | default "unknown"}} ({{age .CreatedTime}}) owner={{tag .Tags "OWNER" | default "none"}}{{with defaultInfo .Name}}{{if .IsDefault}} by {{.CreatedBy}}{{end}}{{end}}`,
+		"", templateScopeGroup)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := &AzureClient{Config: Config{TemplateScope: templateScopeGroup}, Template: tmpl}
+
+	var buf bytes.Buffer
+	if err := client.renderTemplate(&buf, outputTestResults(), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "sandbox-alice created 2023-01-01 (1y 47d) owner=alice\n" +
+		"NetworkWatcherRG created unknown (unknown) owner=none by Azure Network Watcher\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestRenderTemplateInventoryScope(t *testing.T) {
+	withInventoryNow(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	content := `Subscription {{.SubscriptionID}}: {{len .Groups}} groups
+{{range .Groups}}- {{.Name}} [{{keys .Tags | join ","}}] {{ageDays .CreatedTime}}
+{{range .Resources}}  * {{.Name}} ({{.Provider}}){{end}}
+{{end}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tmpl, err := loadOutputTemplate("", path, templateScopeInventory)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := &AzureClient{
+		Config:   Config{SubscriptionID: "test-subscription", TemplateScope: templateScopeInventory},
+		Template: tmpl,
+	}
+
+	var buf bytes.Buffer
+	if err := client.renderTemplate(&buf, outputTestResults(), true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"Subscription test-subscription: 2 groups",
+		"- sandbox-alice [owner] 364",
+		"  * db/main (Microsoft.Sql)",
+		"- NetworkWatcherRG [] -1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestLoadOutputTemplateErrors(t *testing.T) {
+	if _, err := loadOutputTemplate("{{.Name}}", "file.tmpl", templateScopeGroup); err == nil {
+		t.Error("Expected error when both --template and --template-file are given")
+	}
+	if _, err := loadOutputTemplate("{{.Name", "", templateScopeGroup); err == nil {
+		t.Error("Expected parse error")
+	}
+	if _, err := loadOutputTemplate("{{.Name}}", "", "everything"); err == nil {
+		t.Error("Expected error for unknown scope")
+	}
+	if _, err := loadOutputTemplate("", filepath.Join(t.TempDir(), "missing.tmpl"), templateScopeGroup); err == nil {
+		t.Error("Expected error for missing template file")
+	}
+	if tmpl, err := loadOutputTemplate("", "", templateScopeGroup); tmpl != nil || err != nil {
+		t.Errorf("Expected no template without input, got %v, %v", tmpl, err)
+	}
+}
+
+func TestTemplateJoin(t *testing.T) {
+	if got := templateJoin(", ", []interface{}{"a", 1}); got != "a, 1" {
+		t.Errorf("unexpected join %q", got)
+	}
+	if got := templateJoin(",", nil); got != "" {
+		t.Errorf("Expected empty join for nil, got %q", got)
+	}
+}
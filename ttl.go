@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Expiry states of a resource group
+const (
+	ttlStatusExpired     = "expired"
+	ttlStatusExpiring    = "expiring"
+	ttlStatusValid       = "valid"
+	ttlStatusMissing     = "missing"
+	ttlStatusUnparseable = "unparseable"
+)
+
+var ttlStatuses = []string{ttlStatusExpired, ttlStatusExpiring, ttlStatusValid, ttlStatusMissing, ttlStatusUnparseable}
+
+// expiryTagLayouts are the accepted formats of an expiry tag value: ISO dates plus a few date
+// styles commonly typed by hand. Unlike the creation time tags, the US 01/02/2006 form is not
+// accepted: next to the day-first 02.01.2006 form, a value such as 05/03/2027 could mean either
+// day, and guessing wrong would delete a group early or keep it too long.
+var expiryTagLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02",
+	"2006/01/02", "20060102", "02.01.2006", "2 Jan 2006", "Jan 2, 2006", "January 2, 2006"}
+
+// TTLGroup is the expiry state of one resource group
+type TTLGroup struct {
+	Name          string     `json:"name"`
+	Location      string     `json:"location"`
+	Status        string     `json:"status"`
+	Value         string     `json:"value,omitempty"`
+	ExpiresOn     *time.Time `json:"expiresOn,omitempty"`
+	DaysRemaining *int       `json:"daysRemaining,omitempty"`
+}
+
+// TTLReport is the result of the tags ttl command
+type TTLReport struct {
+	SubscriptionID string     `json:"subscriptionId"`
+	Tag            string     `json:"tag"`
+	Within         string     `json:"within"`
+	GeneratedAt    time.Time  `json:"generatedAt"`
+	Counts         TTLCounts  `json:"counts"`
+	Results        []TTLGroup `json:"results"`
+}
+
+// TTLCounts counts the reported groups per expiry state
+type TTLCounts struct {
+	Expired     int `json:"expired"`
+	Expiring    int `json:"expiring"`
+	Valid       int `json:"valid"`
+	Missing     int `json:"missing"`
+	Unparseable int `json:"unparseable"`
+}
+
+// TTLOptions controls which groups are checked and which states are reported
+type TTLOptions struct {
+	GroupSelection
+	Tag             string
+	Within          time.Duration
+	WithinLabel     string
+	Statuses        []string
+	IncludeDefaults bool
+}
+
+// Tags ttl command
+var tagsTTLCmd = &cobra.Command{
+	Use:   "ttl",
+	Short: "Report resource groups by their expiry tag",
+	Long: `Reads an expiry tag (default expires-on) on every resource group and classifies the
+group as expired, expiring within the --within window, valid, missing the tag, or carrying a
+value that is not a recognised date. The report is sorted by expiry date, soonest first, with
+missing and unparseable groups last. A date without a time expires at the start of that day
+(UTC). Default resource groups created by Azure are skipped unless --include-defaults is
+given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tag, _ := cmd.Flags().GetString("tag")
+		within, _ := cmd.Flags().GetString("within")
+		statuses, _ := cmd.Flags().GetStringSlice("status")
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		match, _ := cmd.Flags().GetString("match")
+		includeDefaults, _ := cmd.Flags().GetBool("include-defaults")
+		format, _ := cmd.Flags().GetString("format")
+		failOnExpired, _ := cmd.Flags().GetBool("fail-on-expired")
+
+		if format != tagAuditFormatText && format != tagAuditFormatJSON {
+			log.Fatalf("Invalid output: unsupported format %q (supported: text, json)", format)
+		}
+		if strings.TrimSpace(tag) == "" {
+			log.Fatalf("Invalid --tag: the expiry tag key is empty")
+		}
+		window, err := parseAge(within)
+		if err != nil {
+			log.Fatalf("Invalid --within: %v", err)
+		}
+		statuses, err = normalizeTTLStatuses(statuses)
+		if err != nil {
+			log.Fatalf("Invalid --status: %v", err)
+		}
+
+		report, err := azureClient.TTLReport(TTLOptions{
+			GroupSelection:  GroupSelection{Groups: groups, NamePattern: match},
+			Tag:             tag,
+			Within:          window,
+			WithinLabel:     within,
+			Statuses:        statuses,
+			IncludeDefaults: includeDefaults,
+		})
+		if err != nil {
+			log.Fatalf("Error checking expiry tags: %v", err)
+		}
+		if err := azureClient.writeTTLReport(os.Stdout, report, format); err != nil {
+			log.Fatalf("Error writing expiry report: %v", err)
+		}
+
+		if failOnExpired && report.Counts.Expired > 0 {
+			log.Fatalf("Expiry check failed: %d resource groups are past their %s date", report.Counts.Expired, report.Tag)
+		}
+	},
+}
+
+func init() {
+	tagsTTLCmd.Flags().String("tag", "expires-on", "Tag holding the resource group's expiry date (matched case-insensitively)")
+	tagsTTLCmd.Flags().String("within", "14d", "Report groups expiring within this window as expiring (e.g. 7d, 2w)")
+	tagsTTLCmd.Flags().StringSlice("status", nil, "Only report these states: expired, expiring, valid, missing, unparseable (repeatable or comma-separated)")
+	tagsTTLCmd.Flags().StringSlice("group", nil, "Resource group name to check (repeatable or comma-separated); defaults to all groups")
+	tagsTTLCmd.Flags().String("match", "", "Regular expression selecting resource group names to check")
+	tagsTTLCmd.Flags().Bool("include-defaults", false, "Also check default resource groups created by Azure (e.g. NetworkWatcherRG)")
+	tagsTTLCmd.Flags().String("format", tagAuditFormatText, "Report format: text or json (--porcelain prints one group per line)")
+	tagsTTLCmd.Flags().Bool("fail-on-expired", false, "Exit non-zero if any resource group has expired")
+
+	tagsCmd.AddCommand(tagsTTLCmd)
+}
+
+// normalizeTTLStatuses lower-cases and validates --status values
+func normalizeTTLStatuses(values []string) ([]string, error) {
+	var statuses []string
+	for _, value := range values {
+		status := strings.ToLower(strings.TrimSpace(value))
+		if status == "" {
+			continue
+		}
+		if !containsString(ttlStatuses, status) {
+			return nil, fmt.Errorf("unknown state %q (supported: %s)", value, strings.Join(ttlStatuses, ", "))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// parseExpiry parses an expiry tag value in any of expiryTagLayouts
+func parseExpiry(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range expiryTagLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// classifyExpiry returns the expiry state of a group's tags at now
+func classifyExpiry(tags map[string]string, tag string, within time.Duration, now time.Time) TTLGroup {
+	_, value, found := lookupTag(tags, tag)
+	if !found || strings.TrimSpace(value) == "" {
+		return TTLGroup{Status: ttlStatusMissing, Value: value}
+	}
+	expiresOn, ok := parseExpiry(value)
+	if !ok {
+		return TTLGroup{Status: ttlStatusUnparseable, Value: value}
+	}
+
+	remaining := expiresOn.Sub(now)
+	days := int(math.Floor(remaining.Hours() / 24))
+	group := TTLGroup{Value: value, ExpiresOn: &expiresOn, DaysRemaining: &days}
+	switch {
+	case remaining <= 0:
+		group.Status = ttlStatusExpired
+	case remaining <= within:
+		group.Status = ttlStatusExpiring
+	default:
+		group.Status = ttlStatusValid
+	}
+	return group
+}
+
+// TTLReport classifies the selected resource groups by their expiry tag. Only the resource
+// group listing is needed, so no per-group API calls are made.
+func (ac *AzureClient) TTLReport(opts TTLOptions) (*TTLReport, error) {
+	selected, err := ac.selectResourceGroups(opts.GroupSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	var checked []ResourceGroup
+	for _, rg := range selected {
+		if opts.IncludeDefaults || !checkIfDefaultResourceGroup(rg.Name).IsDefault {
+			checked = append(checked, rg)
+		}
+	}
+	report := buildTTLReport(checked, opts, inventoryNow().UTC())
+	report.SubscriptionID = ac.Config.SubscriptionID
+	return report, nil
+}
+
+// buildTTLReport classifies the groups and sorts them by expiry date; groups without a date
+// follow, missing before unparseable, then by name
+func buildTTLReport(resourceGroups []ResourceGroup, opts TTLOptions, now time.Time) *TTLReport {
+	report := &TTLReport{Tag: opts.Tag, Within: opts.WithinLabel, GeneratedAt: now, Results: []TTLGroup{}}
+
+	for _, rg := range resourceGroups {
+		group := classifyExpiry(rg.Tags, opts.Tag, opts.Within, now)
+		if len(opts.Statuses) > 0 && !containsString(opts.Statuses, group.Status) {
+			continue
+		}
+		group.Name = rg.Name
+		group.Location = rg.Location
+		report.Counts.add(group.Status)
+		report.Results = append(report.Results, group)
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		switch {
+		case a.ExpiresOn != nil && b.ExpiresOn != nil && !a.ExpiresOn.Equal(*b.ExpiresOn):
+			return a.ExpiresOn.Before(*b.ExpiresOn)
+		case (a.ExpiresOn == nil) != (b.ExpiresOn == nil):
+			return a.ExpiresOn != nil
+		case a.Status != b.Status:
+			return a.Status == ttlStatusMissing
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return report
+}
+
+func (c *TTLCounts) add(status string) {
+	switch status {
+	case ttlStatusExpired:
+		c.Expired++
+	case ttlStatusExpiring:
+		c.Expiring++
+	case ttlStatusValid:
+		c.Valid++
+	case ttlStatusMissing:
+		c.Missing++
+	case ttlStatusUnparseable:
+		c.Unparseable++
+	}
+}
+
+func formatExpiry(group TTLGroup) (string, string) {
+	if group.ExpiresOn == nil {
+		return "", ""
+	}
+	return group.ExpiresOn.Format("2006-01-02"), strconv.Itoa(*group.DaysRemaining)
+}
+
+// writeTTLReport writes the report as text, JSON or porcelain lines, and the groups to the
+// --output-csv file when one is given
+func (ac *AzureClient) writeTTLReport(w io.Writer, report *TTLReport, format string) error {
+	if ac.Config.OutputCSV != "" {
+		if err := writeCSVRecords(ac.Config.OutputCSV, ttlCSVRecords(report)); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+	}
+
+	switch {
+	case ac.Config.Porcelain:
+		fmt.Fprintln(w, "NAME\tSTATUS\tEXPIRES_ON\tDAYS_REMAINING\tVALUE")
+		for _, group := range report.Results {
+			expiresOn, days := formatExpiry(group)
+			writePorcelainRow(w, group.Name, group.Status, expiresOn, days, group.Value)
+		}
+		return nil
+	case format == tagAuditFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode expiry report: %w", err)
+		}
+		return nil
+	}
+
+	writeTTLText(w, report)
+	if ac.Config.OutputCSV != "" {
+		fmt.Fprintf(w, "CSV output written to: %s\n", ac.Config.OutputCSV)
+	}
+	return nil
+}
+
+func writeTTLText(w io.Writer, report *TTLReport) {
+	icons := map[string]string{
+		ttlStatusExpired:     "⛔",
+		ttlStatusExpiring:    "⏳",
+		ttlStatusValid:       "✅",
+		ttlStatusMissing:     "❓",
+		ttlStatusUnparseable: "⚠️",
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tRESOURCE GROUP\tSTATUS\tEXPIRES ON\tDAYS\tLOCATION")
+	for _, group := range report.Results {
+		expiresOn, days := formatExpiry(group)
+		if group.Status == ttlStatusUnparseable {
+			expiresOn = fmt.Sprintf("%q", group.Value)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", icons[group.Status], group.Name, group.Status, expiresOn, days, group.Location)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Printf("Warning: failed to write expiry report: %v", err)
+	}
+
+	fmt.Fprintf(w, "\nExpiry tag %q (expiring within %s): %d expired, %d expiring, %d valid, %d missing, %d unparseable\n",
+		report.Tag, report.Within, report.Counts.Expired, report.Counts.Expiring, report.Counts.Valid, report.Counts.Missing, report.Counts.Unparseable)
+}
+
+// ttlCSVRecords returns one row per reported group
+func ttlCSVRecords(report *TTLReport) [][]string {
+	records := [][]string{{"ResourceGroupName", "Location", "Status", "ExpiresOn", "DaysRemaining", "TagValue"}}
+	for _, group := range report.Results {
+		expiresOn, days := formatExpiry(group)
+		records = append(records, []string{group.Name, group.Location, group.Status, expiresOn, days, group.Value})
+	}
+	return records
+}
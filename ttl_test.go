@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	within := 14 * 24 * time.Hour
+
+	tests := []struct {
+		value    string
+		status   string
+		expected string
+		days     int
+	}{
+		{"2024-05-31", ttlStatusExpired, "2024-05-31", -2},
+		{"2024-06-01", ttlStatusExpired, "2024-06-01", -1},
+		{"2024/06/10", ttlStatusExpiring, "2024-06-10", 8},
+		{"15 Jun 2024", ttlStatusExpiring, "2024-06-15", 13},
+		{"2024-07-01T00:00:00Z", ttlStatusValid, "2024-07-01", 29},
+		{"01.08.2024", ttlStatusValid, "2024-08-01", 60},
+		{"05/03/2027", ttlStatusUnparseable, "", 0}, // 5 March or May 3: ambiguous, so never guessed
+		{"next friday", ttlStatusUnparseable, "", 0},
+		{" ", ttlStatusMissing, "", 0},
+	}
+	for _, tt := range tests {
+		group := classifyExpiry(map[string]string{"Expires-On": tt.value}, "expires-on", within, now)
+		if group.Status != tt.status {
+			t.Errorf("%q: expected %s, got %s", tt.value, tt.status, group.Status)
+		}
+		if expiresOn, days := formatExpiry(group); expiresOn != tt.expected || tt.expected != "" && days != strconv.Itoa(tt.days) {
+			t.Errorf("%q: unexpected expiry %s (%s days)", tt.value, expiresOn, days)
+		}
+	}
+
+	if group := classifyExpiry(nil, "expires-on", within, now); group.Status != ttlStatusMissing {
+		t.Errorf("expected missing, got %s", group.Status)
+	}
+}
+
+func TestTTLReport(t *testing.T) {
+	withInventoryNow(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	client := newCleanupTestClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `{"value": [
+			{"name": "sandbox-carol", "location": "westus", "tags": {"expires-on": "some\tday"}},
+			{"name": "sandbox-alice", "location": "westus", "tags": {"expires-on": "2024-07-01"}},
+			{"name": "sandbox-bob", "location": "westus", "tags": {"Expires-On": "2024-05-20"}},
+			{"name": "prod-app", "location": "eastus"},
+			{"name": "sandbox-dave", "location": "westus", "tags": {"expires-on": "5 Jun 2024"}},
+			{"name": "NetworkWatcherRG", "location": "eastus"}
+		]}`), nil
+	})
+	client.Config.Porcelain = false
+	client.Config.OutputCSV = filepath.Join(t.TempDir(), "ttl.csv")
+
+	report, err := client.TTLReport(TTLOptions{Tag: "expires-on", Within: 7 * 24 * time.Hour, WithinLabel: "7d"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var names []string
+	for _, group := range report.Results {
+		names = append(names, group.Name+":"+group.Status)
+	}
+	expected := "sandbox-bob:expired,sandbox-dave:expiring,sandbox-alice:valid,prod-app:missing,sandbox-carol:unparseable"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("unexpected order %v", names)
+	}
+	if report.Counts != (TTLCounts{Expired: 1, Expiring: 1, Valid: 1, Missing: 1, Unparseable: 1}) {
+		t.Errorf("unexpected counts %+v", report.Counts)
+	}
+
+	var buf bytes.Buffer
+	if err := client.writeTTLReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `Expiry tag "expires-on" (expiring within 7d): 1 expired, 1 expiring, 1 valid, 1 missing, 1 unparseable`) {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+	records := readCSVFile(t, client.Config.OutputCSV)
+	if len(records) != 6 || records[1][0] != "sandbox-bob" || records[1][3] != "2024-05-20" || records[1][4] != "-13" {
+		t.Errorf("unexpected CSV %v", records)
+	}
+
+	buf.Reset()
+	client.Config.Porcelain = true
+	report, _ = client.TTLReport(TTLOptions{Tag: "expires-on", Within: 7 * 24 * time.Hour, Statuses: []string{ttlStatusExpired, ttlStatusExpiring}})
+	if err := client.writeTTLReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "NAME\tSTATUS\tEXPIRES_ON\tDAYS_REMAINING\tVALUE\nsandbox-bob\texpired\t2024-05-20\t-13\t2024-05-20\nsandbox-dave\texpiring\t2024-06-05\t3\t5 Jun 2024\n" {
+		t.Errorf("unexpected porcelain output %q", buf.String())
+	}
+
+	// A tab in the tag value must not add a column to the porcelain row
+	buf.Reset()
+	report, _ = client.TTLReport(TTLOptions{Tag: "expires-on", Within: 7 * 24 * time.Hour, Statuses: []string{ttlStatusUnparseable}})
+	if err := client.writeTTLReport(&buf, report, tagAuditFormatText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\nsandbox-carol\tunparseable\t\t\tsome day\n") {
+		t.Errorf("unexpected porcelain output %q", buf.String())
+	}
+}
+
+func TestNormalizeTTLStatuses(t *testing.T) {
+	statuses, err := normalizeTTLStatuses([]string{" Expired", "", "missing"})
+	if err != nil || strings.Join(statuses, ",") != "expired,missing" {
+		t.Errorf("unexpected statuses %v (%v)", statuses, err)
+	}
+	if _, err := normalizeTTLStatuses([]string{"stale"}); err == nil {
+		t.Error("Expected error for an unknown state")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheet names of the --output-xlsx workbook
+const (
+	xlsxGroupsSheet    = "Groups"
+	xlsxResourcesSheet = "Resources"
+	xlsxSummarySheet   = "Summary"
+)
+
+// xlsxDateFormat is the number format of date cells
+const xlsxDateFormat = "yyyy-mm-dd hh:mm:ss"
+
+// xlsxWorkbook wraps the workbook being written with its shared styles
+type xlsxWorkbook struct {
+	file        *excelize.File
+	headerStyle int
+	dateStyle   int
+}
+
+// writeXLSX writes the results to an Excel workbook with Groups, Resources and Summary sheets.
+// Data sheets have a frozen header row and an autofilter, and times are stored as Excel dates.
+func (ac *AzureClient) writeXLSX(path string, results []ResourceGroupResult) error {
+	f := excelize.NewFile()
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Printf("Warning: failed to close workbook: %v", err)
+		}
+	}()
+
+	wb := &xlsxWorkbook{file: f}
+	var err error
+	if wb.headerStyle, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return fmt.Errorf("failed to create header style: %w", err)
+	}
+	dateFormat := xlsxDateFormat
+	if wb.dateStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return fmt.Errorf("failed to create date style: %w", err)
+	}
+
+	// The default sheet becomes the Groups sheet so it opens first
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxGroupsSheet); err != nil {
+		return fmt.Errorf("failed to rename sheet: %w", err)
+	}
+	for _, sheet := range []string{xlsxResourcesSheet, xlsxSummarySheet} {
+		if _, err := f.NewSheet(sheet); err != nil {
+			return fmt.Errorf("failed to create %s sheet: %w", sheet, err)
+		}
+	}
+
+	if err := wb.writeTable(xlsxGroupsSheet, ac.xlsxGroupHeader(), ac.xlsxGroupRows(results)); err != nil {
+		return err
+	}
+	if err := wb.writeTable(xlsxResourcesSheet, xlsxResourceHeader, xlsxResourceRows(results)); err != nil {
+		return err
+	}
+	if err := wb.writeSummary(results); err != nil {
+		return err
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("failed to save workbook: %w", err)
+	}
+	return nil
+}
+
+func (ac *AzureClient) xlsxGroupHeader() []string {
+	header := []string{"ResourceGroupName", "ID", "Location", "ProvisioningState", "CreatedTime", "AgeDays",
+		"IsDefault", "CreatedBy", "Description", "ResourceCount", "Tags"}
+	if ac.Protection != nil {
+		header = append(header, "Protected", "ProtectionReason")
+	}
+	if ac.Config.CheckLocks {
+		header = append(header, "Locks")
+	}
+	if ac.Config.LastModified {
+		header = append(header, "LastModified", "DaysSinceChange")
+	}
+	if ac.Config.RoleAssignments {
+		header = append(header, "InferredOwners", "RoleAssignments")
+	}
+	if ac.Config.PolicyCompliance {
+		header = append(header, "NonCompliantResources", "NonCompliantPolicies")
+	}
+	if ac.Config.Cost {
+		header = append(header, "Cost", "CostCurrency")
+	}
+	if ac.Config.TagInheritance {
+		header = append(header, "TagGapResources", "TagGaps")
+	}
+	return append(header, "Error")
+}
+
+// xlsxGroupRows returns typed cell values: times stay time.Time and counts stay numbers so Excel
+// can sort and filter them; unknown values are left empty
+func (ac *AzureClient) xlsxGroupRows(results []ResourceGroupResult) [][]interface{} {
+	rows := make([][]interface{}, 0, len(results))
+	for _, result := range results {
+		rg := result.ResourceGroup
+		defaultInfo := checkIfDefaultResourceGroup(rg.Name)
+
+		var created, age, count interface{}
+		if result.CreatedTime != nil {
+			created = result.CreatedTime.UTC()
+			age = ageInDays(*result.CreatedTime)
+		}
+		if result.Error == nil {
+			count = len(result.Resources)
+		}
+
+		row := []interface{}{rg.Name, rg.ID, rg.Location, rg.Properties.ProvisioningState, created, age,
+			defaultInfo.IsDefault, defaultInfo.CreatedBy, defaultInfo.Description, count, formatTagsCell(rg.Tags)}
+		if ac.Protection != nil {
+			row = append(row, result.Protected, result.ProtectionReason)
+		}
+		if ac.Config.CheckLocks {
+			row = append(row, columnLocks(result, columnStyleCSV))
+		}
+		if ac.Config.LastModified {
+			var days interface{}
+			if d := daysSinceChange(result); d != nil {
+				days = *d
+			}
+			row = append(row, xlsxTime(result.LastModified), days)
+		}
+		if ac.Config.RoleAssignments {
+			row = append(row, columnInferredOwners(result, columnStyleCSV), columnRoleAssignments(result, columnStyleCSV))
+		}
+		if ac.Config.PolicyCompliance {
+			var resources, policies interface{}
+			if result.Policy != nil {
+				resources, policies = result.Policy.NonCompliantResources, result.Policy.NonCompliantPolicies
+			}
+			row = append(row, resources, policies)
+		}
+		if ac.Config.Cost {
+			var cost interface{}
+			if result.Cost != nil {
+				cost = roundCost(*result.Cost)
+			}
+			row = append(row, cost, result.CostCurrency)
+		}
+		if ac.Config.TagInheritance {
+			var gapResources interface{}
+			if result.TagInheritance != nil {
+				gapResources = len(result.TagInheritance.Gaps)
+			}
+			row = append(row, gapResources, columnTagGaps(result, columnStyleCSV))
+		}
+		errorText := ""
+		if result.Error != nil {
+			errorText = result.Error.Error()
+		}
+		rows = append(rows, append(row, errorText))
+	}
+	return rows
+}
+
+var xlsxResourceHeader = []string{"ResourceGroupName", "ResourceGroupID", "ResourceID", "ResourceName",
+	"ResourceType", "ResourceLocation", "ResourceCreatedTime", "ResourceChangedTime"}
+
+func xlsxResourceRows(results []ResourceGroupResult) [][]interface{} {
+	var rows [][]interface{}
+	for _, result := range results {
+		for _, resource := range result.Resources {
+			rows = append(rows, []interface{}{result.ResourceGroup.Name, result.ResourceGroup.ID, resource.ID,
+				resource.Name, resource.Type, resource.Location, xlsxTime(resource.CreatedTime), xlsxTime(resource.ChangedTime)})
+		}
+	}
+	return rows
+}
+
+// xlsxTime returns a cell value for an optional time; nil leaves the cell empty
+func xlsxTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// writeTable writes a header row and data rows, styles header and date cells, freezes the
+// header row and adds an autofilter over the table
+func (wb *xlsxWorkbook) writeTable(sheet string, header []string, rows [][]interface{}) error {
+	headerRow := make([]interface{}, len(header))
+	for i, h := range header {
+		headerRow[i] = h
+	}
+	if err := wb.setRow(sheet, 1, headerRow); err != nil {
+		return err
+	}
+	lastColumn, _ := excelize.ColumnNumberToName(len(header))
+	if err := wb.file.SetCellStyle(sheet, "A1", lastColumn+"1", wb.headerStyle); err != nil {
+		return fmt.Errorf("failed to style %s header: %w", sheet, err)
+	}
+
+	for i, row := range rows {
+		if err := wb.setRow(sheet, i+2, row); err != nil {
+			return err
+		}
+		for j, value := range row {
+			if _, ok := value.(time.Time); !ok {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+2)
+			if err := wb.file.SetCellStyle(sheet, cell, cell, wb.dateStyle); err != nil {
+				return fmt.Errorf("failed to style %s!%s: %w", sheet, cell, err)
+			}
+		}
+	}
+
+	if err := wb.file.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return fmt.Errorf("failed to freeze %s header: %w", sheet, err)
+	}
+
+	lastRow := len(rows) + 1
+	if err := wb.file.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastColumn, lastRow), nil); err != nil {
+		return fmt.Errorf("failed to add %s autofilter: %w", sheet, err)
+	}
+	if err := wb.file.SetColWidth(sheet, "A", lastColumn, 20); err != nil {
+		return fmt.Errorf("failed to size %s columns: %w", sheet, err)
+	}
+	return nil
+}
+
+func (wb *xlsxWorkbook) setRow(sheet string, row int, values []interface{}) error {
+	cell, _ := excelize.CoordinatesToCellName(1, row)
+	if err := wb.file.SetSheetRow(sheet, cell, &values); err != nil {
+		return fmt.Errorf("failed to write %s row %d: %w", sheet, row, err)
+	}
+	return nil
+}
+
+// writeSummary writes the group total and the breakdowns by location, category and age as
+// consecutive two-column tables
+func (wb *xlsxWorkbook) writeSummary(results []ResourceGroupResult) error {
+	sections := []struct {
+		title     string
+		breakdown []LabelCount
+	}{
+		{"Location", countBy(results, func(r ResourceGroupResult) string { return r.ResourceGroup.Location })},
+		{"Category", countBy(results, func(r ResourceGroupResult) string { return groupCategory(r.ResourceGroup.Name) })},
+		{"Age", countByAge(results)},
+	}
+
+	if err := wb.setRow(xlsxSummarySheet, 1, []interface{}{"Resource groups", len(results)}); err != nil {
+		return err
+	}
+	if err := wb.file.SetCellStyle(xlsxSummarySheet, "A1", "A1", wb.headerStyle); err != nil {
+		return fmt.Errorf("failed to style summary: %w", err)
+	}
+
+	row := 3
+	for _, section := range sections {
+		if err := wb.setRow(xlsxSummarySheet, row, []interface{}{section.title, "Groups"}); err != nil {
+			return err
+		}
+		if err := wb.file.SetCellStyle(xlsxSummarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), wb.headerStyle); err != nil {
+			return fmt.Errorf("failed to style summary: %w", err)
+		}
+		row++
+		for _, entry := range section.breakdown {
+			if err := wb.setRow(xlsxSummarySheet, row, []interface{}{entry.Label, entry.Count}); err != nil {
+				return err
+			}
+			row++
+		}
+		row++
+	}
+
+	if err := wb.file.SetColWidth(xlsxSummarySheet, "A", "A", 30); err != nil {
+		return fmt.Errorf("failed to size summary columns: %w", err)
+	}
+	return nil
+}
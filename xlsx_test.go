@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	withInventoryNow(t, time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	path := filepath.Join(t.TempDir(), "inventory.xlsx")
+	client := &AzureClient{}
+	if err := client.writeXLSX(path, outputTestResults()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) != 3 || sheets[0] != xlsxGroupsSheet || sheets[1] != xlsxResourcesSheet || sheets[2] != xlsxSummarySheet {
+		t.Fatalf("unexpected sheets %v", sheets)
+	}
+
+	rows, err := f.GetRows(xlsxGroupsSheet)
+	if err != nil {
+		t.Fatalf("Failed to read groups: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "ResourceGroupName" || rows[1][0] != "sandbox-alice" {
+		t.Fatalf("unexpected group rows %v", rows)
+	}
+
+	// Creation times are numeric Excel dates with a date format, not strings
+	if cellType, _ := f.GetCellType(xlsxGroupsSheet, "E2"); cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Errorf("Expected a date cell, got type %v", cellType)
+	}
+	if raw, _ := f.GetCellValue(xlsxGroupsSheet, "E2", excelize.Options{RawCellValue: true}); raw != "44927.5" {
+		t.Errorf("Expected Excel serial date 44927.5, got %q", raw)
+	}
+	if formatted, _ := f.GetCellValue(xlsxGroupsSheet, "E2"); formatted != "2023-01-01 12:00:00" {
+		t.Errorf("unexpected formatted date %q", formatted)
+	}
+	if age, _ := f.GetCellValue(xlsxGroupsSheet, "F2"); age != "59" {
+		t.Errorf("Expected age 59, got %q", age)
+	}
+	if created, _ := f.GetCellValue(xlsxGroupsSheet, "E3"); created != "" {
+		t.Errorf("Expected empty cell for unknown creation time, got %q", created)
+	}
+
+	for _, sheet := range []string{xlsxGroupsSheet, xlsxResourcesSheet} {
+		panes, err := f.GetPanes(sheet)
+		if err != nil {
+			t.Fatalf("Failed to read panes: %v", err)
+		}
+		if !panes.Freeze || panes.YSplit != 1 {
+			t.Errorf("Expected frozen header row on %s, got %+v", sheet, panes)
+		}
+	}
+
+	resources, err := f.GetRows(xlsxResourcesSheet)
+	if err != nil {
+		t.Fatalf("Failed to read resources: %v", err)
+	}
+	if len(resources) != 2 || resources[1][3] != "db/main" {
+		t.Errorf("unexpected resource rows %v", resources)
+	}
+
+	summary, err := f.GetRows(xlsxSummarySheet)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	found := map[string]string{}
+	for _, row := range summary {
+		if len(row) == 2 {
+			found[row[0]] = row[1]
+		}
+	}
+	for label, count := range map[string]string{
+		"Resource groups":       "2",
+		"eastus":                "1",
+		"Azure Network Watcher": "1",
+		userCategory:            "1",
+		ageBucket30To90Days:     "1",
+		ageBucketUnknown:        "1",
+		ageBucketOverYear:       "0",
+	} {
+		if found[label] != count {
+			t.Errorf("Expected %s = %s in summary, got %q", label, count, found[label])
+		}
+	}
+}